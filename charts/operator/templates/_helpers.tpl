@@ -191,3 +191,25 @@ Create the name of the operator service account to use
     {{- default "default" .Values.operator.serviceAccount.name }}
   {{- end }}
 {{- end }}
+
+{{/*
+Create the name of the rule-evaluator service account to use
+*/}}
+{{- define "prometheus-engine.rule-evaluator.serviceAccountName" -}}
+  {{- if .Values.ruleEvaluator.serviceAccount.create }}
+    {{- default "rule-evaluator" .Values.ruleEvaluator.serviceAccount.name }}
+  {{- else }}
+    {{- default "default" .Values.ruleEvaluator.serviceAccount.name }}
+  {{- end }}
+{{- end }}
+
+{{/*
+Names of the objects of all rule shards, given the name of the object of the first shard.
+The OperatorConfig allows up to 64 shards.
+*/}}
+{{- define "prometheus-engine.ruleShardNames" -}}
+- {{ . }}
+{{- range $i := untilStep 1 64 1 }}
+- {{ $ }}-shard-{{ $i }}
+{{- end }}
+{{- end }}
@@ -0,0 +1,252 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/GoogleCloudPlatform/prometheus-engine/pkg/configshard"
+)
+
+const (
+	// dataDirName is the symlink pointing to the directory holding the current files, like
+	// in ConfigMap volumes.
+	dataDirName = "..data"
+)
+
+// assembler writes the files of a mounted ConfigMap into an output directory. If the ConfigMap
+// holds a shard manifest, the files are reassembled from the shard ConfigMaps it references.
+//
+// The output directory is updated atomically: all files are written into a new directory,
+// which is then swapped in by replacing a symlink.
+type assembler struct {
+	logger       log.Logger
+	src, dst     string
+	getConfigMap configshard.ConfigMapGetter
+	failures     prometheus.Counter
+
+	// Content of the files last written to the output directory.
+	last map[string][]byte
+}
+
+func newAssembler(logger log.Logger, reg prometheus.Registerer, src, dst string, get configshard.ConfigMapGetter) *assembler {
+	failures := prometheus.NewCounter(prometheus.CounterOpts{
+		Name:        "config_reloader_assemble_failures_total",
+		Help:        "Number of times assembling the configuration files failed.",
+		ConstLabels: prometheus.Labels{"dir": src},
+	})
+	reg.MustRegister(failures)
+	return &assembler{
+		logger:       log.With(logger, "dir", src),
+		src:          src,
+		dst:          dst,
+		getConfigMap: get,
+		failures:     failures,
+	}
+}
+
+// apply assembles the files and updates the output directory if they changed.
+func (a *assembler) apply(ctx context.Context) error {
+	files, err := a.read(ctx)
+	if err != nil {
+		a.failures.Inc()
+		return err
+	}
+	if a.last != nil && maps.EqualFunc(files, a.last, bytes.Equal) {
+		return nil
+	}
+	if err := writeDirAtomic(a.dst, files); err != nil {
+		a.failures.Inc()
+		return fmt.Errorf("write files to %s: %w", a.dst, err)
+	}
+	//nolint:errcheck
+	level.Info(a.logger).Log("msg", "assembled configuration files", "files", len(files))
+	a.last = files
+	return nil
+}
+
+func (a *assembler) read(ctx context.Context) (map[string][]byte, error) {
+	files, err := readDir(a.src)
+	if err != nil {
+		return nil, err
+	}
+	manifest, ok := files[configshard.ManifestKey]
+	if !ok {
+		return files, nil
+	}
+	m, err := configshard.ParseManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	return configshard.Assemble(ctx, m, a.getConfigMap)
+}
+
+// readDir reads all regular files of a directory, following symlinks. Hidden entries, which
+// include the internal bookkeeping of ConfigMap volumes, are skipped.
+func readDir(dir string) (map[string][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		p := filepath.Join(dir, e.Name())
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		files[e.Name()] = b
+	}
+	return files, nil
+}
+
+// writeDirAtomic replaces the files in dir with the given ones. Readers see either the previous
+// or the new set of files, never a mix of both.
+func writeDirAtomic(dir string, files map[string][]byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tsDir, err := os.MkdirTemp(dir, "..gen-")
+	if err != nil {
+		return err
+	}
+	for name, data := range files {
+		if name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+			return fmt.Errorf("invalid file name %q", name)
+		}
+		if err := os.WriteFile(filepath.Join(tsDir, name), data, 0644); err != nil {
+			return err
+		}
+	}
+
+	// Renaming a symlink over another one is atomic.
+	tmpLink := filepath.Join(dir, dataDirName+"_tmp")
+	if err := os.Remove(tmpLink); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Symlink(filepath.Base(tsDir), tmpLink); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpLink, filepath.Join(dir, dataDirName)); err != nil {
+		return err
+	}
+
+	for name := range files {
+		p := filepath.Join(dir, name)
+		if _, err := os.Lstat(p); err == nil {
+			continue
+		}
+		if err := os.Symlink(filepath.Join(dataDirName, name), p); err != nil {
+			return err
+		}
+	}
+
+	// Clean up files that are gone and previous data directories.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		switch {
+		case name == dataDirName || name == filepath.Base(tsDir):
+		case strings.HasPrefix(name, "..gen-"):
+			if err := os.RemoveAll(filepath.Join(dir, name)); err != nil {
+				return err
+			}
+		case !strings.HasPrefix(name, "."):
+			if _, ok := files[name]; !ok {
+				if err := os.Remove(filepath.Join(dir, name)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// inClusterConfigMapGetter returns a getter that lazily connects to the Kubernetes API server
+// the first time a shard must be fetched.
+func inClusterConfigMapGetter() configshard.ConfigMapGetter {
+	var (
+		once   sync.Once
+		client corev1client.CoreV1Interface
+		err    error
+	)
+	return func(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+		once.Do(func() {
+			var cfg *rest.Config
+			cfg, err = rest.InClusterConfig()
+			if err != nil {
+				return
+			}
+			client, err = corev1client.NewForConfig(cfg)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create Kubernetes client: %w", err)
+		}
+		return client.ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+}
+
+// assembleDir is a source and destination directory pair.
+type assembleDir struct {
+	src, dst string
+}
+
+type assembleDirs []assembleDir
+
+func (d *assembleDirs) String() string {
+	var s []string
+	for _, p := range *d {
+		s = append(s, p.src+":"+p.dst)
+	}
+	return strings.Join(s, ", ")
+}
+
+func (d *assembleDirs) Set(value string) error {
+	src, dst, ok := strings.Cut(value, ":")
+	if !ok || src == "" || dst == "" {
+		return fmt.Errorf("expected <source>:<destination>, got %q", value)
+	}
+	*d = append(*d, assembleDir{src: src, dst: dst})
+	return nil
+}
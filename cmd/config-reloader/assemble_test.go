@@ -0,0 +1,99 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleCloudPlatform/prometheus-engine/pkg/configshard"
+)
+
+func TestAssembler(t *testing.T) {
+	src, dst := t.TempDir(), filepath.Join(t.TempDir(), "out")
+
+	shards := map[string]map[string][]byte{}
+	get := func(_ context.Context, _, name string) (*corev1.ConfigMap, error) {
+		data, ok := shards[name]
+		if !ok {
+			return nil, fmt.Errorf("not found: %s", name)
+		}
+		return &corev1.ConfigMap{BinaryData: data}, nil
+	}
+	a := newAssembler(log.NewNopLogger(), prometheus.NewRegistry(), src, dst, get)
+
+	// Plain files are copied.
+	require.NoError(t, os.WriteFile(filepath.Join(src, "a.yaml"), []byte("a"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "b.yaml"), []byte("b"), 0644))
+	require.NoError(t, a.apply(t.Context()))
+	got, err := readDir(dst)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{"a.yaml": []byte("a"), "b.yaml": []byte("b")}, got)
+
+	// Files referenced by a manifest are reassembled and files that are gone are removed.
+	files := map[string][]byte{
+		"a.yaml": []byte(strings.Repeat("x", 250)),
+		"c.yaml": []byte(strings.Repeat("y", 250)),
+	}
+	m, split, err := configshard.Split("ns", "rules", files, 100)
+	require.NoError(t, err)
+	manifest, err := json.Marshal(m)
+	require.NoError(t, err)
+
+	require.NoError(t, os.Remove(filepath.Join(src, "a.yaml")))
+	require.NoError(t, os.Remove(filepath.Join(src, "b.yaml")))
+	require.NoError(t, os.WriteFile(filepath.Join(src, configshard.ManifestKey), manifest, 0644))
+
+	// Missing shards must not affect the previous output.
+	require.Error(t, a.apply(t.Context()))
+	got, err = readDir(dst)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{"a.yaml": []byte("a"), "b.yaml": []byte("b")}, got)
+
+	shards = split
+	require.NoError(t, a.apply(t.Context()))
+	got, err = readDir(dst)
+	require.NoError(t, err)
+	require.Equal(t, files, got)
+
+	// Only a single data directory remains.
+	entries, err := os.ReadDir(dst)
+	require.NoError(t, err)
+	var dataDirs int
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), "..gen-") {
+			dataDirs++
+		}
+	}
+	require.Equal(t, 1, dataDirs)
+}
+
+func TestAssembleDirsFlag(t *testing.T) {
+	var d assembleDirs
+	require.NoError(t, d.Set("/etc/rules:/prometheus/rules_in"))
+	require.Equal(t, assembleDirs{{src: "/etc/rules", dst: "/prometheus/rules_in"}}, d)
+	require.Error(t, d.Set("/etc/rules"))
+	require.Error(t, d.Set(":/prometheus/rules_in"))
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// configHashCollector exposes the SHA256 hash of the uncompressed config file, which the
+// operator compares against the hash of the configuration it generated. Together with
+// reloader_last_reload_successful this tells whether the current configuration was loaded.
+type configHashCollector struct {
+	path string
+	desc *prometheus.Desc
+}
+
+func newConfigHashCollector(path string) *configHashCollector {
+	return &configHashCollector{
+		path: path,
+		desc: prometheus.NewDesc(
+			"config_reloader_config_hash_info",
+			"SHA256 hash of the uncompressed watched config file.",
+			[]string{"hash"}, nil,
+		),
+	}
+}
+
+func (c *configHashCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *configHashCollector) Collect(ch chan<- prometheus.Metric) {
+	hash, err := configHash(c.path)
+	if err != nil {
+		// The file may not have been written yet, the metric is absent until it exists.
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, 1, hash)
+}
+
+// configHash returns the hex-encoded SHA256 hash of the file, decompressing it first
+// if it is gzipped.
+func configHash(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if bytes.HasPrefix(b, []byte{0x1f, 0x8b}) {
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return "", err
+		}
+		if b, err = io.ReadAll(r); err != nil {
+			return "", err
+		}
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigHashCollector(t *testing.T) {
+	const config = "global:\n  scrape_interval: 30s\n"
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	c := newConfigHashCollector(path)
+
+	// No metric before the file exists.
+	require.Equal(t, 0, testutil.CollectAndCount(c))
+
+	sum := sha256.Sum256([]byte(config))
+	want := hex.EncodeToString(sum[:])
+
+	require.NoError(t, os.WriteFile(path, []byte(config), 0o644))
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expectedHashMetric(want))))
+
+	// Compressed files hash to the same value.
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(config))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expectedHashMetric(want))))
+}
+
+func expectedHashMetric(hash string) string {
+	return `
+# HELP config_reloader_config_hash_info SHA256 hash of the uncompressed watched config file.
+# TYPE config_reloader_config_hash_info gauge
+config_reloader_config_hash_info{hash="` + hash + `"} 1
+`
+}
@@ -38,6 +38,7 @@ import (
 func main() {
 	var (
 		watchedDirs      stringSlice
+		assembleDirs     assembleDirs
 		configFile       = flag.String("config-file", "", "config file to watch for changes")
 		configFileOutput = flag.String("config-file-output", "", "config file to write with interpolated environment variables")
 		configDir        = flag.String("config-dir", "", "config directory to watch for changes")
@@ -54,6 +55,7 @@ func main() {
 		listenAddress = flag.String("listen-address", ":19091", "address on which to expose metrics")
 	)
 	flag.Var(&watchedDirs, "watched-dir", "directory to watch for file changes (for rule and secret files, may be repeated)")
+	flag.Var(&assembleDirs, "assemble-dir", "<source>:<destination> directory pair; files of the ConfigMap mounted at source, reassembled from its shards if it holds a shard manifest, are written to destination (may be repeated)")
 
 	flag.Parse()
 
@@ -73,6 +75,9 @@ func main() {
 		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
 		versioninfo.NewCollector("config_reloader"), // Add build_info metric.
 	)
+	if *configFile != "" {
+		metrics.MustRegister(newConfigHashCollector(*configFile))
+	}
 
 	reloadURL, err := url.Parse(*reloadURLStr)
 	if err != nil {
@@ -135,6 +140,30 @@ func main() {
 	}()
 	<-done
 
+	// Assemble the configuration files once before the reloader starts, as it fails if
+	// they don't exist.
+	getConfigMap := inClusterConfigMapGetter()
+	var assemblers []*assembler
+	for _, d := range assembleDirs {
+		a := newAssembler(logger, metrics, d.src, d.dst, getConfigMap)
+		for {
+			err := a.apply(context.Background())
+			if err == nil {
+				break
+			}
+			//nolint:errcheck
+			level.Error(logger).Log("msg", "assembling configuration failed, retrying", "dir", d.src, "err", err)
+			select {
+			case <-term:
+				//nolint:errcheck
+				level.Info(logger).Log("msg", "received SIGTERM, exiting gracefully...")
+				os.Exit(0)
+			case <-time.After(5 * time.Second):
+			}
+		}
+		assemblers = append(assemblers, a)
+	}
+
 	var cfgDirs []reloader.CfgDirOption
 	if *configDir != "" {
 		cfgDirs = append(cfgDirs, reloader.CfgDirOption{
@@ -171,6 +200,28 @@ func main() {
 			cancel()
 		})
 	}
+	if len(assemblers) > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		g.Add(func() error {
+			ticker := time.NewTicker(10 * time.Second)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+				for _, a := range assemblers {
+					if err := a.apply(ctx); err != nil {
+						//nolint:errcheck
+						level.Error(logger).Log("msg", "assembling configuration failed", "dir", a.src, "err", err)
+					}
+				}
+			}
+		}, func(error) {
+			cancel()
+		})
+	}
 	{
 		cancel := make(chan struct{})
 		g.Add(
@@ -15,21 +15,36 @@
 package rule
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"net"
 	"net/http"
 	"net/url"
+	"slices"
+	"strings"
 	"sync"
 
 	"github.com/GoogleCloudPlatform/prometheus-engine/internal/promapi"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/prometheus/prometheus/model/labels"
 	promapiv1 "github.com/prometheus/prometheus/web/api/v1"
 )
 
+// dnsSchemePrefix marks endpoints whose host is resolved to all its addresses on each request,
+// e.g. dns+http://rule-evaluator-headless.gmp-system.svc.cluster.local:19092.
+const dnsSchemePrefix = "dns+"
+
 var errAllEndpointsFailed = errors.New("all endpoint failed")
 
+// resolver is an interface for resolving hosts to their addresses.
+type resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
 // Retriever is an interface for fetching rules and alerts.
 type retriever interface {
 	RuleGroups(ctx context.Context, baseURL url.URL, queryString string) ([]*promapiv1.RuleGroup, error)
@@ -37,12 +52,16 @@ type retriever interface {
 }
 
 // Proxy fan-outs requests to multiple endpoints serving rules and alerts.
-// Results are un-sorted and concatenated as-is. In case of errors from any endpoint,
-// warning log and partial results are returned.
+// Results are un-sorted and concatenated as-is. Endpoints with a "dns+" scheme prefix are
+// resolved to all their addresses instead, e.g. to reach every shard and replica of the
+// rule-evaluator. Their results are deduplicated and sorted, as replicas of a shard serve
+// the same rule groups and alerts. In case of errors from any endpoint, warning log and
+// partial results are returned.
 type Proxy struct {
 	logger    log.Logger
 	endpoints []url.URL
 	client    retriever
+	resolver  resolver
 }
 
 // NewProxy creates a new proxy.
@@ -51,11 +70,13 @@ func NewProxy(logger log.Logger, c httpClient, ruleEndpoints []url.URL) *Proxy {
 		logger:    logger,
 		endpoints: ruleEndpoints,
 		client:    newClient(c),
+		resolver:  net.DefaultResolver,
 	}
 }
 
 func (p *Proxy) RuleGroups(w http.ResponseWriter, req *http.Request) {
-	rules, err := fanoutForward[*promapiv1.RuleGroup](req.Context(), p.logger, p.endpoints, req.URL.RawQuery, p.client.RuleGroups)
+	retrieveFn := resolveForward(p.logger, p.resolver, p.client.RuleGroups, dedupRuleGroups)
+	rules, err := fanoutForward[*promapiv1.RuleGroup](req.Context(), p.logger, p.endpoints, req.URL.RawQuery, retrieveFn)
 	if err != nil {
 		p.handleError(w, req, err)
 		return
@@ -65,7 +86,8 @@ func (p *Proxy) RuleGroups(w http.ResponseWriter, req *http.Request) {
 }
 
 func (p *Proxy) Alerts(w http.ResponseWriter, req *http.Request) {
-	alerts, err := fanoutForward[*promapiv1.Alert](req.Context(), p.logger, p.endpoints, req.URL.RawQuery, p.client.Alerts)
+	retrieveFn := resolveForward(p.logger, p.resolver, p.client.Alerts, dedupAlerts)
+	alerts, err := fanoutForward[*promapiv1.Alert](req.Context(), p.logger, p.endpoints, req.URL.RawQuery, retrieveFn)
 	if err != nil {
 		p.handleError(w, req, err)
 		return
@@ -142,6 +164,94 @@ func fanoutForward[T *promapiv1.Alert | *promapiv1.RuleGroup](
 	return results, nil
 }
 
+// resolveForward wraps retrieveFn so that endpoints with a "dns+" scheme prefix are resolved
+// and all their addresses are called in parallel. The combined results are deduplicated with
+// dedupFn. Other endpoints are passed to retrieveFn as-is.
+func resolveForward[T *promapiv1.Alert | *promapiv1.RuleGroup](
+	logger log.Logger,
+	r resolver,
+	retrieveFn func(context.Context, url.URL, string) ([]T, error),
+	dedupFn func([]T) []T,
+) func(context.Context, url.URL, string) ([]T, error) {
+	return func(ctx context.Context, baseURL url.URL, rawQuery string) ([]T, error) {
+		if !strings.HasPrefix(baseURL.Scheme, dnsSchemePrefix) {
+			return retrieveFn(ctx, baseURL, rawQuery)
+		}
+		endpoints, err := resolveEndpoints(ctx, r, baseURL)
+		if err != nil {
+			return nil, err
+		}
+		results, err := fanoutForward(ctx, logger, endpoints, rawQuery, retrieveFn)
+		if err != nil {
+			return nil, err
+		}
+		return dedupFn(results), nil
+	}
+}
+
+// resolveEndpoints returns an endpoint for each address the host of baseURL resolves to.
+func resolveEndpoints(ctx context.Context, r resolver, baseURL url.URL) ([]url.URL, error) {
+	addrs, err := r.LookupHost(ctx, baseURL.Hostname())
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s failed: %w", baseURL.Hostname(), err)
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("resolving %s failed: no addresses", baseURL.Hostname())
+	}
+	endpoints := make([]url.URL, 0, len(addrs))
+	for _, addr := range addrs {
+		endpoint := baseURL
+		endpoint.Scheme = strings.TrimPrefix(baseURL.Scheme, dnsSchemePrefix)
+		switch port := baseURL.Port(); {
+		case port != "":
+			endpoint.Host = net.JoinHostPort(addr, port)
+		case strings.Contains(addr, ":"):
+			endpoint.Host = "[" + addr + "]"
+		default:
+			endpoint.Host = addr
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, nil
+}
+
+// dedupRuleGroups removes rule groups with the same file and name, keeping the most recently
+// evaluated one, and sorts the result by file and name.
+func dedupRuleGroups(groups []*promapiv1.RuleGroup) []*promapiv1.RuleGroup {
+	type key struct{ file, name string }
+	byKey := make(map[key]*promapiv1.RuleGroup, len(groups))
+	for _, g := range groups {
+		k := key{file: g.File, name: g.Name}
+		if prev, ok := byKey[k]; ok && !g.LastEvaluation.After(prev.LastEvaluation) {
+			continue
+		}
+		byKey[k] = g
+	}
+	res := slices.Collect(maps.Values(byKey))
+	slices.SortFunc(res, func(a, b *promapiv1.RuleGroup) int {
+		return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.Name, b.Name))
+	})
+	return res
+}
+
+// dedupAlerts removes alerts with the same labels, keeping the first one, and sorts the result
+// by labels.
+func dedupAlerts(alerts []*promapiv1.Alert) []*promapiv1.Alert {
+	byLabels := make(map[string]*promapiv1.Alert, len(alerts))
+	for _, a := range alerts {
+		k := a.Labels.String()
+		if _, ok := byLabels[k]; ok {
+			continue
+		}
+		byLabels[k] = a
+	}
+	res := slices.Collect(maps.Values(byLabels))
+	slices.SortFunc(res, func(a, b *promapiv1.Alert) int {
+		return labels.Compare(a.Labels, b.Labels)
+	})
+	return res
+}
+
 // handleError writes an error response to the client based on the error.
 func (p *Proxy) handleError(w http.ResponseWriter, req *http.Request, err error) {
 	if errors.Is(err, context.Canceled) {
@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -46,6 +47,39 @@ func (m *mockRetriever) RuleGroups(ctx context.Context, baseURL url.URL, querySt
 	return m.RuleGroupsFunc(ctx, baseURL, queryString)
 }
 
+type mockResolver map[string][]string
+
+func (m mockResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	addrs, ok := m[host]
+	if !ok {
+		return nil, fmt.Errorf("no such host %q", host)
+	}
+	return addrs, nil
+}
+
+func TestResolveEndpoints(t *testing.T) {
+	t.Parallel()
+
+	r := mockResolver{"rule-evaluator": {"10.0.0.1", "fd00::1"}}
+
+	endpoints, err := resolveEndpoints(t.Context(), r, url.URL{Scheme: "dns+http", Host: "rule-evaluator:19092", Path: "/prefix"})
+	require.NoError(t, err)
+	require.Equal(t, []url.URL{
+		{Scheme: "http", Host: "10.0.0.1:19092", Path: "/prefix"},
+		{Scheme: "http", Host: "[fd00::1]:19092", Path: "/prefix"},
+	}, endpoints)
+
+	endpoints, err = resolveEndpoints(t.Context(), r, url.URL{Scheme: "dns+https", Host: "rule-evaluator"})
+	require.NoError(t, err)
+	require.Equal(t, []url.URL{
+		{Scheme: "https", Host: "10.0.0.1"},
+		{Scheme: "https", Host: "[fd00::1]"},
+	}, endpoints)
+
+	_, err = resolveEndpoints(t.Context(), r, url.URL{Scheme: "dns+http", Host: "unknown:19092"})
+	require.Error(t, err)
+}
+
 func TestProxy_handleError(t *testing.T) {
 	t.Parallel()
 
@@ -290,12 +324,44 @@ func TestProxy_Alerts(t *testing.T) {
 			wantStatus: http.StatusOK,
 			wantBody:   `{"status":"success","data":{"alerts":[{"labels":{"labelKey1":"labelVal1"},"annotations":{"annoKey1":"AnnoVal1"},"state":"firing","activeAt":"2011-11-11T11:11:11.111122223Z","value":"1e+00"},{"labels":{"labelKey2":"labelVal2"},"annotations":{"annoKey2":"AnnoVal2"},"state":"firing","activeAt":"2022-02-22T22:22:22.999977773Z","value":"2e+00"}]}}`,
 		},
+		{
+			name: "dns endpoint merges alerts of all addresses without duplicates",
+			ruleEvaluatorBaseURLs: []url.URL{
+				{Scheme: "dns+http", Host: "rule-evaluator:19092"},
+			},
+			ruleRetriever: &mockRetriever{
+				RuleGroupsFunc: func(context.Context, url.URL, string) ([]*promapiv1.RuleGroup, error) {
+					t.Fatal("Should not call the RULES endpoint when fetching alerts")
+					return nil, nil
+				},
+				AlertsFunc: func(_ context.Context, baseURL url.URL, _ string) ([]*promapiv1.Alert, error) {
+					alerts := []*promapiv1.Alert{{
+						Labels:   []labels.Label{{Name: "labelKey2", Value: "labelVal2"}},
+						State:    "firing",
+						ActiveAt: &activeAt2,
+						Value:    "2e+00",
+					}}
+					if baseURL.Host == "10.0.0.1:19092" {
+						alerts = append(alerts, &promapiv1.Alert{
+							Labels:   []labels.Label{{Name: "labelKey1", Value: "labelVal1"}},
+							State:    "firing",
+							ActiveAt: &activeAt1,
+							Value:    "1e+00",
+						})
+					}
+					return alerts, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"success","data":{"alerts":[{"labels":{"labelKey1":"labelVal1"},"annotations":{},"state":"firing","activeAt":"2011-11-11T11:11:11.111122223Z","value":"1e+00"},{"labels":{"labelKey2":"labelVal2"},"annotations":{},"state":"firing","activeAt":"2022-02-22T22:22:22.999977773Z","value":"2e+00"}]}}`,
+		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			r := &Proxy{
 				logger:    log.NewNopLogger(),
 				endpoints: tt.ruleEvaluatorBaseURLs,
 				client:    tt.ruleRetriever,
+				resolver:  mockResolver{"rule-evaluator": {"10.0.0.1", "10.0.0.2"}},
 			}
 
 			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
@@ -358,6 +424,35 @@ func TestProxy_RuleGroups(t *testing.T) {
 			wantStatus: http.StatusOK,
 			wantBody:   `{"status":"success","data":{"groups":[{"name":"group1","file":"file1","rules":[],"interval":0,"limit":0,"evaluationTime":0,"lastEvaluation":"0001-01-01T00:00:00Z"}]}}`,
 		},
+		{
+			name: "dns endpoint merges rule groups of all addresses without duplicates",
+			ruleEvaluatorBaseURLs: []url.URL{
+				{Scheme: "dns+http", Host: "rule-evaluator:19092"},
+			},
+			ruleRetriever: &mockRetriever{
+				AlertsFunc: func(context.Context, url.URL, string) ([]*promapiv1.Alert, error) {
+					t.Fatal("Should not call the ALERTS endpoint when fetching rules")
+					return nil, nil
+				},
+				RuleGroupsFunc: func(_ context.Context, baseURL url.URL, _ string) ([]*promapiv1.RuleGroup, error) {
+					// Both addresses serve group2, the second one evaluated it more recently.
+					switch baseURL.Host {
+					case "10.0.0.1:19092":
+						return []*promapiv1.RuleGroup{
+							{Name: "group2", File: "file1", Rules: []promapiv1.Rule{}, LastEvaluation: time.Unix(10, 0).UTC()},
+							{Name: "group1", File: "file1", Rules: []promapiv1.Rule{}, LastEvaluation: time.Unix(10, 0).UTC()},
+						}, nil
+					case "10.0.0.2:19092":
+						return []*promapiv1.RuleGroup{
+							{Name: "group2", File: "file1", Rules: []promapiv1.Rule{}, LastEvaluation: time.Unix(20, 0).UTC()},
+						}, nil
+					}
+					return nil, fmt.Errorf("unexpected endpoint %s", baseURL.String())
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"success","data":{"groups":[{"name":"group1","file":"file1","rules":[],"interval":0,"limit":0,"evaluationTime":0,"lastEvaluation":"1970-01-01T00:00:10Z"},{"name":"group2","file":"file1","rules":[],"interval":0,"limit":0,"evaluationTime":0,"lastEvaluation":"1970-01-01T00:00:20Z"}]}}`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -365,6 +460,7 @@ func TestProxy_RuleGroups(t *testing.T) {
 				logger:    log.NewNopLogger(),
 				endpoints: tt.ruleEvaluatorBaseURLs,
 				client:    tt.ruleRetriever,
+				resolver:  mockResolver{"rule-evaluator": {"10.0.0.1", "10.0.0.2"}},
 			}
 
 			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
@@ -72,7 +72,7 @@ var (
 		fmt.Sprintf("The URL to forward authenticated requests to. (%s is replaced with the --query.project-id flag.)", projectIDVar))
 
 	//nolint:revive // Allow insecure http connection
-	ruleEndpointURLStrings = flag.String("rules.target-urls", "http://rule-evaluator.gmp-system.svc.cluster.local:19092", "Comma separated lists of URLs that support HTTP Prometheus Alert and Rules APIs (/api/v1/alerts, /api/v1/rules), e.g. GMP rule-evaluator. NOTE: Results are merged as-is, no sorting and deduplication is done.")
+	ruleEndpointURLStrings = flag.String("rules.target-urls", "dns+http://rule-evaluator-headless.gmp-system.svc.cluster.local:19092", "Comma separated lists of URLs that support HTTP Prometheus Alert and Rules APIs (/api/v1/alerts, /api/v1/rules), e.g. GMP rule-evaluator. URLs with a dns+ scheme prefix are resolved to all their addresses on each request, e.g. to reach all shards and replicas of the GMP rule-evaluator, and their results are deduplicated. NOTE: Results of different URLs are merged as-is, no sorting and deduplication is done.")
 
 	logLevel = flag.String("log.level", "info",
 		"The level of logging. Can be one of 'debug', 'info', 'warn', 'error'")
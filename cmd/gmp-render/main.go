@@ -0,0 +1,159 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/GoogleCloudPlatform/prometheus-engine/internal/resources"
+	"github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator"
+)
+
+// commaStringSlice implements the flag.Value interface to support
+// repeated and/or comma-separated string flags.
+type commaStringSlice []string
+
+func (s *commaStringSlice) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *commaStringSlice) Set(value string) error {
+	for p := range strings.SplitSeq(value, ",") {
+		trimmed := strings.TrimSpace(p)
+		if trimmed != "" {
+			*s = append(*s, trimmed)
+		}
+	}
+	return nil
+}
+
+func main() {
+	var inputFiles commaStringSlice
+	flag.Var(&inputFiles, "file", "Input source (YAML file, directory, or '-' for stdin) (Required)")
+	flag.Var(&inputFiles, "f", "Input source (YAML file, directory, or '-' for stdin) (Required)")
+
+	var (
+		logVerbosity      = flag.Int("v", 0, "Logging verbosity")
+		projectID         = flag.String("project-id", "", "Project ID of the cluster. (Required)")
+		location          = flag.String("location", "", "Google Cloud region or zone of the cluster.")
+		cluster           = flag.String("cluster", "", "Name of the cluster. (Required)")
+		operatorNamespace = flag.String("operator-namespace", operator.DefaultOperatorNamespace,
+			"Namespace in which the operator manages its resources.")
+		publicNamespace = flag.String("public-namespace", operator.DefaultPublicNamespace,
+			"Namespace in which the operator reads user-provided resources.")
+		defaultNamespace = flag.String("namespace", "default", "Namespace of input resources that don't specify one.")
+		outputDir        = flag.String("output-dir", "", "Directory to write the generated files to. If empty, all files are written to stdout.")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
+		fmt.Fprint(os.Stderr, "Render the Prometheus configuration and rule files the GMP operator generates for the given resources.\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	logger := zap.New(zap.Level(zapcore.Level(-*logVerbosity)))
+
+	if flag.NArg() > 0 {
+		logger.Error(nil, "unexpected positional arguments, pass inputs with -f", "arguments", flag.Args())
+		flag.Usage()
+		os.Exit(1)
+	}
+	if len(inputFiles) == 0 {
+		logger.Error(nil, "flag -f / --file is required")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	var objs []client.Object
+	for _, input := range inputFiles {
+		res, err := resources.Read(input, *defaultNamespace)
+		if err != nil {
+			logger.Error(err, "reading input failed", "input", input)
+			os.Exit(1)
+		}
+		objs = append(objs, res...)
+	}
+
+	result, err := operator.Render(context.Background(), logger, operator.Options{
+		ProjectID:         *projectID,
+		Location:          *location,
+		Cluster:           *cluster,
+		OperatorNamespace: *operatorNamespace,
+		PublicNamespace:   *publicNamespace,
+	}, objs...)
+	if err != nil {
+		logger.Error(err, "rendering configuration failed")
+		os.Exit(1)
+	}
+
+	if *outputDir != "" {
+		err = writeFiles(*outputDir, result.Files)
+	} else {
+		err = printFiles(os.Stdout, result.Files)
+	}
+	if err != nil {
+		logger.Error(err, "writing output failed")
+		os.Exit(1)
+	}
+
+	// Resources that failed generation are silently dropped from the configuration by the
+	// operator. Fail explicitly so that this gets noticed before changes reach a cluster.
+	if len(result.Failures) > 0 {
+		for _, f := range result.Failures {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", f.Key, f.Message)
+		}
+		os.Exit(1)
+	}
+}
+
+// printFiles writes all files as a single YAML stream, in a stable order.
+func printFiles(w io.Writer, files map[string]string) error {
+	for i, name := range slices.Sorted(maps.Keys(files)) {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w, "---"); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "# Source: %s\n%s", name, files[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFiles(dir string, files map[string]string) error {
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
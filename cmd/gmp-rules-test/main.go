@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/prometheus/prometheus/google/export"
+	"github.com/prometheus/prometheus/google/export/setup"
+	"go.uber.org/zap/zapcore"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator"
+)
+
+func main() {
+	var (
+		logVerbosity      = flag.Int("v", 0, "Logging verbosity")
+		projectID         = flag.String("project-id", "", "Project ID of the cluster. (Required)")
+		location          = flag.String("location", "", "Google Cloud region or zone of the cluster.")
+		cluster           = flag.String("cluster", "", "Name of the cluster. (Required)")
+		operatorNamespace = flag.String("operator-namespace", operator.DefaultOperatorNamespace,
+			"Namespace in which the operator manages its resources.")
+		publicNamespace = flag.String("public-namespace", operator.DefaultPublicNamespace,
+			"Namespace in which the operator reads user-provided resources.")
+		defaultNamespace = flag.String("namespace", "default", "Namespace of resources that don't specify one.")
+		run              = flag.String("run", "", "If set, only run test groups whose name matches the regular expression.")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s: [flags] <test-file>...\n", os.Args[0])
+		fmt.Fprint(os.Stderr, "Unit test the rules of Rules, ClusterRules and GlobalRules resources as they are evaluated by the GMP rule-evaluator.\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	logger := zap.New(zap.Level(zapcore.Level(-*logVerbosity)))
+
+	if flag.NArg() == 0 {
+		logger.Error(nil, "at least one test file is required")
+		flag.Usage()
+		os.Exit(1)
+	}
+	rt := &ruleTester{
+		logger: logger,
+		opts: operator.Options{
+			ProjectID:         *projectID,
+			Location:          *location,
+			Cluster:           *cluster,
+			OperatorNamespace: *operatorNamespace,
+			PublicNamespace:   *publicNamespace,
+		},
+		defaultNamespace: *defaultNamespace,
+	}
+	if *run != "" {
+		re, err := regexp.Compile(*run)
+		if err != nil {
+			logger.Error(err, "invalid -run expression")
+			os.Exit(1)
+		}
+		rt.run = re
+	}
+
+	// The test storage exports samples through the global exporter. Nothing must be sent
+	// to Google Cloud Monitoring.
+	if err := setup.SetGlobal(export.NopExporter()); err != nil {
+		logger.Error(err, "setting up exporter failed")
+		os.Exit(1)
+	}
+
+	failed := false
+	for _, filename := range flag.Args() {
+		fmt.Println("Unit testing:", filename)
+		if errs := rt.testFile(context.Background(), filename); len(errs) > 0 {
+			fmt.Println("  FAILED:")
+			for _, err := range errs {
+				fmt.Printf("    %s\n", err)
+			}
+			failed = true
+		} else {
+			fmt.Println("  SUCCESS")
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,485 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/google/export"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/promql/promqltest"
+	"github.com/prometheus/prometheus/rules"
+	"gopkg.in/yaml.v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/GoogleCloudPlatform/prometheus-engine/internal/resources"
+	"github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator"
+)
+
+// unitTestFile holds the contents of a single unit test file. It follows the format of
+// promtool's rule unit tests, except that rule_files refers to manifests of Rules, ClusterRules
+// and GlobalRules resources rather than Prometheus rule files.
+type unitTestFile struct {
+	RuleFiles          []string       `yaml:"rule_files"`
+	EvaluationInterval model.Duration `yaml:"evaluation_interval,omitempty"`
+	GroupEvalOrder     []string       `yaml:"group_eval_order"`
+	Tests              []testGroup    `yaml:"tests"`
+}
+
+// testGroup is a group of input series and the tests run against them.
+type testGroup struct {
+	Name            string           `yaml:"name,omitempty"`
+	Interval        model.Duration   `yaml:"interval"`
+	InputSeries     []series         `yaml:"input_series"`
+	AlertRuleTests  []alertTestCase  `yaml:"alert_rule_test,omitempty"`
+	PromqlExprTests []promqlTestCase `yaml:"promql_expr_test,omitempty"`
+	ExternalLabels  labels.Labels    `yaml:"external_labels,omitempty"`
+	ExternalURL     string           `yaml:"external_url,omitempty"`
+}
+
+type series struct {
+	Series string `yaml:"series"`
+	Values string `yaml:"values"`
+}
+
+type alertTestCase struct {
+	EvalTime  model.Duration `yaml:"eval_time"`
+	Alertname string         `yaml:"alertname"`
+	ExpAlerts []alert        `yaml:"exp_alerts"`
+}
+
+type alert struct {
+	ExpLabels      map[string]string `yaml:"exp_labels"`
+	ExpAnnotations map[string]string `yaml:"exp_annotations"`
+}
+
+type promqlTestCase struct {
+	Expr       string         `yaml:"expr"`
+	EvalTime   model.Duration `yaml:"eval_time"`
+	ExpSamples []sample       `yaml:"exp_samples"`
+}
+
+type sample struct {
+	Labels string  `yaml:"labels"`
+	Value  float64 `yaml:"value"`
+	// Histogram is the expected native histogram in the series notation. If set, Value is ignored.
+	Histogram string `yaml:"histogram"`
+}
+
+// ruleTester runs rule unit tests against the rule files the operator generates for the
+// tested resources.
+type ruleTester struct {
+	logger logr.Logger
+	opts   operator.Options
+	// Namespace of namespaced resources that don't specify one.
+	defaultNamespace string
+	// If set, only test groups with a matching name are run.
+	run *regexp.Regexp
+}
+
+// testFile runs all tests of the given unit test file and returns the failures.
+func (rt *ruleTester) testFile(ctx context.Context, filename string) []error {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return []error{err}
+	}
+	var utf unitTestFile
+	if err := yaml.UnmarshalStrict(b, &utf); err != nil {
+		return []error{err}
+	}
+	if utf.EvaluationInterval == 0 {
+		utf.EvaluationInterval = model.Duration(time.Minute)
+	}
+
+	dir, err := os.MkdirTemp("", "gmp-rules-test")
+	if err != nil {
+		return []error{err}
+	}
+	defer os.RemoveAll(dir)
+
+	ruleFiles, err := rt.renderRuleFiles(ctx, filepath.Dir(filename), utf.RuleFiles, dir)
+	if err != nil {
+		return []error{err}
+	}
+
+	// Groups are evaluated in the order they are listed in. Unlisted groups go first.
+	groupOrder := map[string]int{}
+	for i, name := range utf.GroupEvalOrder {
+		if _, ok := groupOrder[name]; ok {
+			return []error{fmt.Errorf("group name repeated in evaluation order: %s", name)}
+		}
+		groupOrder[name] = i
+	}
+
+	var errs []error
+	for _, tg := range utf.Tests {
+		if rt.run != nil && !rt.run.MatchString(tg.Name) {
+			continue
+		}
+		if tg.Interval == 0 {
+			tg.Interval = utf.EvaluationInterval
+		}
+		errs = append(errs, rt.test(&tg, time.Duration(utf.EvaluationInterval), groupOrder, ruleFiles)...)
+	}
+	return errs
+}
+
+// renderRuleFiles generates the rule files for the resources in the given manifests, relative
+// to baseDir, and writes them into dir. It fails if any resource is rejected, as the operator
+// would omit its rules.
+func (rt *ruleTester) renderRuleFiles(ctx context.Context, baseDir string, patterns []string, dir string) ([]string, error) {
+	var objs []client.Object
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(baseDir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no file matches pattern %q", pattern)
+		}
+		for _, m := range matches {
+			res, err := resources.Read(m, rt.defaultNamespace)
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, res...)
+		}
+	}
+
+	result, err := operator.Render(ctx, rt.logger, rt.opts, objs...)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Failures) > 0 {
+		var errs []error
+		for _, f := range result.Failures {
+			errs = append(errs, fmt.Errorf("%s: %s", f.Key, f.Message))
+		}
+		return nil, errors.Join(errs...)
+	}
+
+	var files []string
+	for _, name := range slices.Sorted(maps.Keys(result.Files)) {
+		// The rules directory also holds files other than rule files, such as the query
+		// projects of the rule files.
+		if !strings.HasPrefix(name, "rules/") || filepath.Ext(name) != ".yaml" {
+			continue
+		}
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(p, []byte(result.Files[name]), 0644); err != nil {
+			return nil, err
+		}
+		files = append(files, p)
+	}
+	return files, nil
+}
+
+// test runs the tests of a test group.
+func (rt *ruleTester) test(tg *testGroup, evalInterval time.Duration, groupOrder map[string]int, ruleFiles []string) (errs []error) {
+	input, err := rt.seriesLoadingString(tg)
+	if err != nil {
+		return []error{err}
+	}
+	suite, err := promqltest.NewLazyLoader(input, promqltest.LazyLoaderOpts{
+		EnableAtModifier:     true,
+		EnableNegativeOffset: true,
+	})
+	if err != nil {
+		return []error{err}
+	}
+	defer func() {
+		if err := suite.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}()
+	suite.SubqueryInterval = evalInterval
+
+	manager := rules.NewManager(&rules.ManagerOptions{
+		QueryFunc:  rules.EngineQueryFunc(suite.QueryEngine(), suite.Storage()),
+		Appendable: suite.Storage(),
+		Context:    context.Background(),
+		NotifyFunc: func(context.Context, string, ...*rules.Alert) {},
+		Logger:     log.NewNopLogger(),
+	})
+	groupsByKey, loadErrs := manager.LoadGroups(time.Duration(tg.Interval), tg.ExternalLabels, tg.ExternalURL, nil, ruleFiles...)
+	if loadErrs != nil {
+		return loadErrs
+	}
+	groups := make([]*rules.Group, 0, len(groupsByKey))
+	for _, g := range groupsByKey {
+		groups = append(groups, g)
+		for _, r := range g.Rules() {
+			// Mark alerting rules as restored so that they produce the ALERTS series.
+			if ar, ok := r.(*rules.AlertingRule); ok {
+				ar.SetRestored(true)
+			}
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groupOrder[groups[i].Name()] != groupOrder[groups[j].Name()] {
+			return groupOrder[groups[i].Name()] < groupOrder[groups[j].Name()]
+		}
+		return groups[i].File() < groups[j].File()
+	})
+
+	alertTests := map[model.Duration][]alertTestCase{}
+	var maxEvalTime model.Duration
+	for _, tc := range tg.AlertRuleTests {
+		if tc.Alertname == "" {
+			return []error{fmt.Errorf("%salert test at eval_time %s misses required attribute alertname", testGroupPrefix(tg), tc.EvalTime)}
+		}
+		alertTests[tc.EvalTime] = append(alertTests[tc.EvalTime], tc)
+		maxEvalTime = max(maxEvalTime, tc.EvalTime)
+	}
+	for _, tc := range tg.PromqlExprTests {
+		maxEvalTime = max(maxEvalTime, tc.EvalTime)
+	}
+	alertEvalTimes := slices.Sorted(maps.Keys(alertTests))
+
+	mint := time.Unix(0, 0).UTC()
+	maxt := mint.Add(time.Duration(maxEvalTime))
+	for ts := mint; !ts.After(maxt); ts = ts.Add(evalInterval) {
+		var evalErrs []error
+		suite.WithSamplesTill(ts, func(err error) {
+			if err != nil {
+				evalErrs = append(evalErrs, err)
+				return
+			}
+			for _, g := range groups {
+				g.Eval(suite.Context(), ts)
+				for _, r := range g.Rules() {
+					if r.LastError() != nil {
+						evalErrs = append(evalErrs, fmt.Errorf("%srule: %s, time: %s, err: %w", testGroupPrefix(tg), r.Name(), ts.Sub(mint), r.LastError()))
+					}
+				}
+			}
+		})
+		if len(evalErrs) > 0 {
+			return append(errs, evalErrs...)
+		}
+		// Alerts are checked against the last evaluation at or before their eval_time.
+		for len(alertEvalTimes) > 0 && time.Duration(alertEvalTimes[0]) < ts.Add(evalInterval).Sub(mint) {
+			for _, tc := range alertTests[alertEvalTimes[0]] {
+				if err := checkAlerts(tc, groups); err != nil {
+					errs = append(errs, fmt.Errorf("%s%w", testGroupPrefix(tg), err))
+				}
+			}
+			alertEvalTimes = alertEvalTimes[1:]
+		}
+	}
+
+	for _, tc := range tg.PromqlExprTests {
+		if err := checkExpr(suite, tc, mint.Add(time.Duration(tc.EvalTime))); err != nil {
+			errs = append(errs, fmt.Errorf("%s%w", testGroupPrefix(tg), err))
+		}
+	}
+	return errs
+}
+
+// seriesLoadingString returns the input series in the promqltest notation. Like all data
+// written to Google Cloud Managed Service for Prometheus, series carry the project_id,
+// location and cluster labels. They default to the values the rules are scoped to.
+func (rt *ruleTester) seriesLoadingString(tg *testGroup) (string, error) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "load %s\n", tg.Interval)
+	for _, s := range tg.InputSeries {
+		lset, err := parser.ParseMetric(s.Series)
+		if err != nil {
+			return "", fmt.Errorf("%sparse series %q: %w", testGroupPrefix(tg), s.Series, err)
+		}
+		b := labels.NewBuilder(lset)
+		for name, value := range map[string]string{
+			export.KeyProjectID: rt.opts.ProjectID,
+			export.KeyLocation:  rt.opts.Location,
+			export.KeyCluster:   rt.opts.Cluster,
+		} {
+			if !lset.Has(name) && value != "" {
+				b.Set(name, value)
+			}
+		}
+		fmt.Fprintf(&sb, "  %s %s\n", b.Labels(), s.Values)
+	}
+	return sb.String(), nil
+}
+
+// checkAlerts compares the firing alerts of all alerting rules with the name of the test case
+// against the expected alerts.
+func checkAlerts(tc alertTestCase, groups []*rules.Group) error {
+	var got []labelsAndAnnotations
+	for _, g := range groups {
+		for _, r := range g.Rules() {
+			ar, ok := r.(*rules.AlertingRule)
+			if !ok || ar.Name() != tc.Alertname {
+				continue
+			}
+			for _, a := range ar.ActiveAlerts() {
+				if a.State == rules.StateFiring {
+					got = append(got, labelsAndAnnotations{Labels: a.Labels.Copy(), Annotations: a.Annotations.Copy()})
+				}
+			}
+		}
+	}
+	var want []labelsAndAnnotations
+	for _, a := range tc.ExpAlerts {
+		lset := labels.NewBuilder(labels.FromMap(a.ExpLabels))
+		// The alertname label is added by the alerting rule and not listed in the test.
+		lset.Set(labels.AlertName, tc.Alertname)
+		want = append(want, labelsAndAnnotations{Labels: lset.Labels(), Annotations: labels.FromMap(a.ExpAnnotations)})
+	}
+	slices.SortFunc(got, compareLabelsAndAnnotations)
+	slices.SortFunc(want, compareLabelsAndAnnotations)
+
+	if !cmp.Equal(want, got, cmp.Comparer(labels.Equal)) {
+		return fmt.Errorf("alertname: %s, time: %s,\n      exp: %s\n      got: %s", tc.Alertname, tc.EvalTime, formatList(want), formatList(got))
+	}
+	return nil
+}
+
+// checkExpr compares the result of the test case's expression against the expected samples.
+func checkExpr(suite *promqltest.LazyLoader, tc promqlTestCase, ts time.Time) error {
+	got, err := query(suite, tc.Expr, ts)
+	if err != nil {
+		return fmt.Errorf("expr: %q, time: %s, err: %w", tc.Expr, tc.EvalTime, err)
+	}
+	want := make([]parsedSample, 0, len(tc.ExpSamples))
+	for _, s := range tc.ExpSamples {
+		lset, err := parser.ParseMetric(s.Labels)
+		if err != nil {
+			return fmt.Errorf("expr: %q, time: %s, err: labels %q: %w", tc.Expr, tc.EvalTime, s.Labels, err)
+		}
+		ps := parsedSample{Labels: lset, Value: s.Value}
+		if s.Histogram != "" {
+			_, values, err := parser.ParseSeriesDesc("{} " + s.Histogram)
+			if err != nil {
+				return fmt.Errorf("expr: %q, time: %s, err: histogram %q: %w", tc.Expr, tc.EvalTime, s.Histogram, err)
+			}
+			if len(values) != 1 || values[0].Histogram == nil {
+				return fmt.Errorf("expr: %q, time: %s, err: expected a single histogram, got %q", tc.Expr, tc.EvalTime, s.Histogram)
+			}
+			ps.Value = 0
+			ps.Histogram = promqltest.HistogramTestExpression(values[0].Histogram)
+		}
+		want = append(want, ps)
+	}
+	slices.SortFunc(got, compareSamples)
+	slices.SortFunc(want, compareSamples)
+
+	if !cmp.Equal(want, got, cmp.Comparer(labels.Equal)) {
+		return fmt.Errorf("expr: %q, time: %s,\n      exp: %s\n      got: %s", tc.Expr, tc.EvalTime, formatList(want), formatList(got))
+	}
+	return nil
+}
+
+func query(suite *promqltest.LazyLoader, expr string, ts time.Time) ([]parsedSample, error) {
+	q, err := suite.QueryEngine().NewInstantQuery(suite.Context(), suite.Queryable(), nil, expr, ts)
+	if err != nil {
+		return nil, err
+	}
+	defer q.Close()
+
+	res := q.Exec(suite.Context())
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	switch v := res.Value.(type) {
+	case promql.Vector:
+		result := make([]parsedSample, 0, len(v))
+		for _, s := range v {
+			ps := parsedSample{Labels: s.Metric, Value: s.F}
+			if s.H != nil {
+				ps.Value = 0
+				ps.Histogram = promqltest.HistogramTestExpression(s.H)
+			}
+			result = append(result, ps)
+		}
+		return result, nil
+	case promql.Scalar:
+		return []parsedSample{{Labels: labels.EmptyLabels(), Value: v.V}}, nil
+	default:
+		return nil, fmt.Errorf("result of type %s is not a vector or scalar", res.Value.Type())
+	}
+}
+
+func testGroupPrefix(tg *testGroup) string {
+	if tg.Name == "" {
+		return ""
+	}
+	return fmt.Sprintf("name: %s, ", tg.Name)
+}
+
+type labelsAndAnnotations struct {
+	Labels      labels.Labels
+	Annotations labels.Labels
+}
+
+func (la labelsAndAnnotations) String() string {
+	return fmt.Sprintf("{labels: %s, annotations: %s}", la.Labels, la.Annotations)
+}
+
+func compareLabelsAndAnnotations(a, b labelsAndAnnotations) int {
+	if c := labels.Compare(a.Labels, b.Labels); c != 0 {
+		return c
+	}
+	return labels.Compare(a.Annotations, b.Annotations)
+}
+
+type parsedSample struct {
+	Labels labels.Labels
+	Value  float64
+	// Histogram is the histogram in the series notation. If set, Value is zero.
+	Histogram string
+}
+
+func (ps parsedSample) String() string {
+	if ps.Histogram != "" {
+		return ps.Labels.String() + " " + ps.Histogram
+	}
+	return ps.Labels.String() + " " + strconv.FormatFloat(ps.Value, 'g', -1, 64)
+}
+
+func compareSamples(a, b parsedSample) int {
+	return labels.Compare(a.Labels, b.Labels)
+}
+
+func formatList[T fmt.Stringer](items []T) string {
+	s := make([]string, 0, len(items))
+	for _, item := range items {
+		s = append(s, item.String())
+	}
+	return "[" + strings.Join(s, ", ") + "]"
+}
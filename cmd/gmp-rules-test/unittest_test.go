@@ -0,0 +1,142 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr/testr"
+
+	"github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator"
+)
+
+func newTestRuleTester(t *testing.T) *ruleTester {
+	return &ruleTester{
+		logger: testr.New(t),
+		opts: operator.Options{
+			ProjectID: "test-project",
+			Location:  "us-central1",
+			Cluster:   "test-cluster",
+		},
+		defaultNamespace: "default",
+	}
+}
+
+func TestRuleTester(t *testing.T) {
+	rt := newTestRuleTester(t)
+	if errs := rt.testFile(t.Context(), "testdata/test.yaml"); len(errs) > 0 {
+		t.Fatalf("unexpected test failures: %v", errs)
+	}
+}
+
+func TestRuleTesterFailures(t *testing.T) {
+	rules, err := filepath.Abs("testdata/rules.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, tc := range map[string]struct {
+		test string
+		want string
+	}{
+		// Passes with promtool, but the scoped rules don't see the series of another namespace.
+		"unscoped input": {
+			test: `
+tests:
+- input_series:
+  - series: 'up{namespace="team-b", job="app", instance="b"}'
+    values: '0x10'
+  alert_rule_test:
+  - eval_time: 10m
+    alertname: InstanceDown
+    exp_alerts:
+    - exp_labels:
+        namespace: team-b
+        job: app
+        instance: b
+        severity: page
+        team: a
+      exp_annotations:
+        summary: b is down
+`,
+			want: "alertname: InstanceDown, time: 10m",
+		},
+		"unexpected sample": {
+			test: `
+tests:
+- name: samples
+  input_series:
+  - series: 'up{namespace="team-a", job="app", instance="a"}'
+    values: '1'
+  promql_expr_test:
+  - expr: job:up:sum
+    eval_time: 0m
+`,
+			want: `name: samples, expr: "job:up:sum", time: 0s`,
+		},
+		"invalid rules": {
+			test: `
+rule_files:
+- invalid.yaml
+tests: []
+`,
+			want: "Rules/team-a/invalid",
+		},
+		"missing rule file": {
+			test: `
+rule_files:
+- missing.yaml
+tests: []
+`,
+			want: "no file matches pattern",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if !strings.Contains(tc.test, "rule_files") {
+				tc.test = "rule_files: [" + rules + "]\n" + tc.test
+			}
+			if err := os.WriteFile(filepath.Join(dir, "invalid.yaml"), []byte(`
+apiVersion: monitoring.googleapis.com/v1
+kind: Rules
+metadata:
+  name: invalid
+  namespace: team-a
+spec:
+  groups:
+  - name: invalid
+    rules:
+    - record: invalid
+      expr: sum(
+`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			testFile := filepath.Join(dir, "test.yaml")
+			if err := os.WriteFile(testFile, []byte(tc.test), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			errs := newTestRuleTester(t).testFile(t.Context(), testFile)
+			if len(errs) != 1 {
+				t.Fatalf("expected a single failure, got %v", errs)
+			}
+			if !strings.Contains(errs[0].Error(), tc.want) {
+				t.Errorf("expected failure to contain %q, got %q", tc.want, errs[0])
+			}
+		})
+	}
+}
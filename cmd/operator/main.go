@@ -85,6 +85,12 @@ func main() {
 		// feature.
 		cleanupAnnotKey = flag.String("cleanup-unless-annotation-key", "",
 			"Clean up operator-managed workloads without the provided annotation key.")
+
+		leaderElection = flag.Bool("leader-elect", false,
+			"Elect a leader among operator replicas to run the controllers. Required when running more than one replica.")
+
+		ruleEvaluatorReplicas = flag.Int("rule-evaluator-replicas", 1,
+			"Number of rule-evaluator replicas to run while there are rules to evaluate. Replicas elect a leader to write rule results.")
 	)
 	flag.Parse()
 
@@ -120,6 +126,9 @@ func main() {
 		CertDir:           *certDir,
 		ListenAddr:        *webhookAddr,
 		CleanupAnnotKey:   *cleanupAnnotKey,
+		LeaderElection:    *leaderElection,
+
+		RuleEvaluatorReplicas: int32(*ruleEvaluatorReplicas),
 	})
 	if err != nil {
 		logger.Error(err, "instantiating operator failed")
@@ -27,6 +27,7 @@ import (
 	"reflect"
 	"runtime"
 	"runtime/debug"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -60,6 +61,7 @@ import (
 	"github.com/prometheus/common/model"
 	promforkconfig "github.com/prometheus/prometheus/config"
 	"github.com/prometheus/prometheus/discovery"
+	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/notifier"
 	"github.com/prometheus/prometheus/promql"
@@ -69,7 +71,8 @@ import (
 	"github.com/prometheus/prometheus/util/annotations"
 	"github.com/prometheus/prometheus/util/strutil"
 
-	// Import to enable 'kubernetes_sd_configs' to SD config register.
+	// Import to enable 'dns_sd_configs' and 'kubernetes_sd_configs' to SD config register.
+	_ "github.com/prometheus/prometheus/discovery/dns"
 	_ "github.com/prometheus/prometheus/discovery/kubernetes"
 )
 
@@ -87,7 +90,7 @@ var (
 			Name: "rule_evaluator_query_requests_total",
 			Help: "A counter for query requests sent to GCM.",
 		},
-		[]string{"code", "method"},
+		[]string{"project", "code", "method"},
 	)
 	queryHistogram = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -95,7 +98,7 @@ var (
 			Help:    "Histogram of response latency of query requests sent to GCM.",
 			Buckets: prometheus.DefBuckets,
 		},
-		[]string{"code", "method"},
+		[]string{"project", "code", "method"},
 	)
 )
 
@@ -152,6 +155,10 @@ func main() {
 		ListenAddress: ":9091",
 		ConfigFile:    "prometheus.yml",
 		QueueCapacity: 10000,
+		// Same defaults as Prometheus.
+		OutageTolerance: time.Hour,
+		ForGracePeriod:  10 * time.Minute,
+		ResendDelay:     time.Minute,
 	}
 	defaultEvaluatorOpts.setupFlags(a)
 
@@ -181,11 +188,30 @@ func main() {
 		_ = level.Error(logger).Log("msg", "invalid command line argument", "err", err)
 		os.Exit(1)
 	}
+	if defaultEvaluatorOpts.ReplicaLabel != "" && defaultEvaluatorOpts.Replica == "" {
+		defaultEvaluatorOpts.Replica, err = os.Hostname()
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "Unable to detect hostname for the replica label", "err", err)
+			os.Exit(1)
+		}
+	}
 
 	startTime := time.Now()
 
+	// Rule results are gated by the lease before they reach the exporter, which shares the
+	// lease to reset its series state on leader changes. The exporter runs the lease.
+	opts.ExporterOpts.DefaultUnsetFields()
+	opts.MetadataOpts.DefaultUnsetFields()
+	opts.HAOptions.DefaultUnsetFields()
+	opts.MetadataOpts.ExtractMetadata(logger, &opts.ExporterOpts)
+	lease, err := opts.HAOptions.NewLease(logger, reg)
+	if err != nil {
+		_ = level.Error(logger).Log("msg", "Creating the HA lease failed", "err", err)
+		os.Exit(1)
+	}
+
 	ctxExporter, cancelExporter := context.WithCancel(ctx)
-	exporter, err := opts.NewExporter(ctxExporter, logger, reg)
+	exporter, err := export.New(ctxExporter, logger, reg, opts.ExporterOpts, lease)
 	if err != nil {
 		_ = level.Error(logger).Log("msg", "Creating a Cloud Monitoring Exporter failed", "err", err)
 		os.Exit(1)
@@ -200,7 +226,7 @@ func main() {
 	}
 	notificationManager := notifier.NewManager(&notifierOptions, log.With(logger, "component", "notifier"))
 	rulesMetrics := rules.NewGroupMetrics(reg)
-	ruleEvaluator, err := newRuleEvaluator(ctx, logger, &defaultEvaluatorOpts, version.Version, destination, notificationManager, rulesMetrics)
+	ruleEvaluator, err := newRuleEvaluator(ctx, logger, &defaultEvaluatorOpts, version.Version, &leaderAppendable{Appendable: destination, lease: lease}, notificationManager, rulesMetrics)
 	if err != nil {
 		_ = level.Error(logger).Log("msg", "Create rule-evaluator", "err", err)
 		os.Exit(1)
@@ -240,7 +266,7 @@ func main() {
 				if cfg.GoogleCloud.Query.ProjectID != "" {
 					evaluatorOpts.ProjectID = cfg.GoogleCloud.Query.ProjectID
 				}
-				return ruleEvaluator.ApplyConfig(cfg, &evaluatorOpts)
+				return ruleEvaluator.ApplyConfig(withReplicaLabel(cfg, evaluatorOpts.ReplicaLabel, evaluatorOpts.Replica), &evaluatorOpts)
 			},
 		},
 	}
@@ -454,12 +480,18 @@ func Must[T any](value T, err error) T {
 type evaluatorOptions struct {
 	TargetURL       *url.URL
 	ProjectID       string
+	ProjectsFile    string
 	GeneratorURL    *url.URL
 	CredentialsFile string
 	DisableAuth     bool
 	ListenAddress   string
 	ConfigFile      string
 	QueueCapacity   int
+	OutageTolerance time.Duration
+	ForGracePeriod  time.Duration
+	ResendDelay     time.Duration
+	ReplicaLabel    string
+	Replica         string
 }
 
 func (opts *evaluatorOptions) setupFlags(a *kingpin.Application) {
@@ -467,6 +499,10 @@ func (opts *evaluatorOptions) setupFlags(a *kingpin.Application) {
 		Default(opts.ProjectID).
 		StringVar(&opts.ProjectID)
 
+	a.Flag("query.projects-file", "JSON file that maps the names of rule files to the project ID of the scoping project to evaluate their rules against. Rules of unlisted files are evaluated against --query.project-id.").
+		PlaceHolder("<FILE>").
+		StringVar(&opts.ProjectsFile)
+
 	a.Flag("query.target-url", fmt.Sprintf("The address of the Prometheus server query endpoint. (%s is replaced with the --query.project-id flag.)", projectIDVar)).
 		Default(opts.TargetURL.String()).
 		URLVar(&opts.TargetURL)
@@ -494,6 +530,26 @@ func (opts *evaluatorOptions) setupFlags(a *kingpin.Application) {
 	a.Flag("alertmanager.notification-queue-capacity", "The capacity of the queue for pending Alertmanager notifications.").
 		Default(strconv.Itoa(opts.QueueCapacity)).
 		IntVar(&opts.QueueCapacity)
+
+	a.Flag("rules.alert.for-outage-tolerance", "Max time to tolerate the rule-evaluator outage for restoring \"for\" state of alert from the query API.").
+		Default(model.Duration(opts.OutageTolerance).String()).
+		DurationVar(&opts.OutageTolerance)
+
+	a.Flag("rules.alert.for-grace-period", "Minimum duration between alert and restored \"for\" state. This is maintained only for alerts with configured \"for\" time greater than grace period.").
+		Default(model.Duration(opts.ForGracePeriod).String()).
+		DurationVar(&opts.ForGracePeriod)
+
+	a.Flag("rules.alert.resend-delay", "Minimum amount of time to wait before resending an alert to Alertmanager.").
+		Default(model.Duration(opts.ResendDelay).String()).
+		DurationVar(&opts.ResendDelay)
+
+	a.Flag("rules.ha.replica-label", "Name of an external label that identifies the replica when running multiple rule-evaluators. It is available to rule templates but stripped from exported rule results and sent alerts, so that Alertmanager deduplicates the alerts of all replicas.").
+		Default(opts.ReplicaLabel).
+		StringVar(&opts.ReplicaLabel)
+
+	a.Flag("rules.ha.replica", "Value of the replica label. Defaults to the hostname.").
+		Default(opts.Replica).
+		StringVar(&opts.Replica)
 }
 
 func (opts *evaluatorOptions) validate() error {
@@ -511,6 +567,10 @@ func (opts *evaluatorOptions) validate() error {
 		opts.ProjectID = cfg.GoogleCloud.Query.ProjectID
 	}
 
+	if opts.ReplicaLabel != "" && !model.LabelName(opts.ReplicaLabel).IsValid() {
+		return fmt.Errorf("invalid --rules.ha.replica-label value %q", opts.ReplicaLabel)
+	}
+
 	// Pass a placeholder project ID value "x" to ensure the URL replacement is valid.
 	if _, err := url.Parse(strings.ReplaceAll(opts.TargetURL.String(), projectIDVar, "x")); err != nil {
 		return fmt.Errorf("unable to parse --query.target-url value %q: %w", opts.TargetURL.String(), err)
@@ -537,8 +597,9 @@ func newAPI(ctx context.Context, opts *evaluatorOptions, version string) (v1.API
 	if err != nil {
 		return nil, err
 	}
-	roundTripper := promhttp.InstrumentRoundTripperCounter(queryCounter,
-		promhttp.InstrumentRoundTripperDuration(queryHistogram, transport))
+	projectLabel := prometheus.Labels{"project": opts.ProjectID}
+	roundTripper := promhttp.InstrumentRoundTripperCounter(queryCounter.MustCurryWith(projectLabel),
+		promhttp.InstrumentRoundTripperDuration(queryHistogram.MustCurryWith(projectLabel), transport))
 	client, err := api.NewClient(api.Config{
 		Address:      strings.ReplaceAll(opts.TargetURL.String(), projectIDVar, opts.ProjectID),
 		RoundTripper: roundTripper,
@@ -565,6 +626,58 @@ func QueryFunc(ctx context.Context, q string, t time.Time, v1api v1.API) (parser
 	return v, warnings, err
 }
 
+// withReplicaLabel returns a copy of the configuration with the replica label added to the
+// external labels. It's only passed to the rules manager, the exporter and notifier keep
+// the original external labels, so neither exported rule results nor alerts carry it.
+func withReplicaLabel(cfg *promforkconfig.Config, name, value string) *promforkconfig.Config {
+	if name == "" {
+		return cfg
+	}
+	res := *cfg
+	b := labels.NewBuilder(cfg.GlobalConfig.ExternalLabels)
+	b.Set(name, value)
+	res.GlobalConfig.ExternalLabels = b.Labels()
+	return &res
+}
+
+// leaderAppendable drops samples outside of the time range for which the lease is held,
+// so that only the leader among several replicas writes rule results. Samples are gated
+// by their evaluation timestamp rather than the time they are appended at, so replicas
+// that evaluate the same groups at the same timestamps don't write overlapping results
+// around a leader handover.
+type leaderAppendable struct {
+	storage.Appendable
+	lease export.Lease
+}
+
+func (a *leaderAppendable) Appender(ctx context.Context) storage.Appender {
+	return &leaderAppender{Appender: a.Appendable.Appender(ctx), lease: a.lease}
+}
+
+type leaderAppender struct {
+	storage.Appender
+	lease export.Lease
+}
+
+func (a *leaderAppender) inRange(t int64) bool {
+	start, end, ok := a.lease.Range()
+	return ok && t >= start.UnixMilli() && t <= end.UnixMilli()
+}
+
+func (a *leaderAppender) Append(ref storage.SeriesRef, l labels.Labels, t int64, v float64) (storage.SeriesRef, error) {
+	if !a.inRange(t) {
+		return ref, nil
+	}
+	return a.Appender.Append(ref, l, t, v)
+}
+
+func (a *leaderAppender) AppendHistogram(ref storage.SeriesRef, l labels.Labels, t int64, h *histogram.Histogram, fh *histogram.FloatHistogram) (storage.SeriesRef, error) {
+	if !a.inRange(t) {
+		return ref, nil
+	}
+	return a.Appender.AppendHistogram(ref, l, t, h, fh)
+}
+
 // sendAlerts returns the rules.NotifyFunc for a Notifier.
 func sendAlerts(s *notifier.Manager, projectID string, generatorURL *url.URL) rules.NotifyFunc {
 	return func(_ context.Context, expr string, alerts ...*rules.Alert) {
@@ -740,6 +853,10 @@ func convertMetricToLabel(metric model.Metric) labels.Labels {
 		}
 		ls = append(ls, l)
 	}
+	// Labels must be sorted, which the metric map doesn't guarantee.
+	slices.SortFunc(ls, func(a, b labels.Label) int {
+		return strings.Compare(a.Name, b.Name)
+	})
 	return ls
 }
 
@@ -833,9 +950,23 @@ func convertMatchersToPromQL(matchers []*labels.Matcher, d int64) (string, []str
 	return queryExpression, filteredMatchers
 }
 
+// convertMatchersToSelectors converts the matchers to the series selectors of the label APIs.
+func convertMatchersToSelectors(matchers []*labels.Matcher) []string {
+	if len(matchers) == 0 {
+		return nil
+	}
+	selector := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		selector = append(selector, m.String())
+	}
+	return []string{"{" + strings.Join(selector, ", ") + "}"}
+}
+
 // queryStorage implements storage.Queryable.
 type queryStorage struct {
 	api v1.API
+	// externalLabels returns the external labels that series are currently exported with.
+	externalLabels func() labels.Labels
 }
 
 // Querier provides querying access over time series data of a fixed time range.
@@ -846,34 +977,39 @@ func (s *queryStorage) Querier(mint, maxt int64) (storage.Querier, error) {
 		maxt:  maxt / 1000,
 		query: QueryFunc,
 	}
+	if s.externalLabels != nil {
+		db.externalLabels = s.externalLabels()
+	}
 	return db, nil
 }
 
 // queryAccess implements storage.Querier.
 type queryAccess struct {
-	// storage.LabelQuerier satisfies the interface. Calling related methods will result in panic.
-	storage.LabelQuerier
-
 	api   v1.API
 	mint  int64
 	maxt  int64
 	query func(context.Context, string, time.Time, v1.API) (parser.Value, v1.Warnings, error)
+	// externalLabels are added to series when they are exported. They are removed again from
+	// selected series, so that they are equal to the series written by the rules manager.
+	// This is required to restore the "for" state of alerts from ALERTS_FOR_STATE series.
+	externalLabels labels.Labels
 }
 
 // Select returns a set of series that matches the given label matchers and time range.
-func (db *queryAccess) Select(ctx context.Context, sort bool, hints *storage.SelectHints, matchers ...*labels.Matcher) storage.SeriesSet {
-	if sort || hints != nil {
-		return newListSeriesSet(nil, errors.New("sorting series and select hints are not supported"), nil)
+func (db *queryAccess) Select(ctx context.Context, sortSeries bool, hints *storage.SelectHints, matchers ...*labels.Matcher) storage.SeriesSet {
+	mint, maxt := db.mint, db.maxt
+	// Hints are in milliseconds and may only narrow down the time range of the querier.
+	if hints != nil && hints.End > hints.Start {
+		mint = max(mint, hints.Start/1000)
+		maxt = min(maxt, hints.End/1000)
 	}
-
-	duration := db.maxt - db.mint
+	duration := maxt - mint
 	if duration <= 0 { // not a valid time duration.
 		return newListSeriesSet(nil, nil, nil)
 	}
 
-	queryExpression, filteredMatchers := convertMatchersToPromQL(matchers, duration)
-	maxt := time.Unix(db.maxt, 0)
-	v, warnings, err := db.query(ctx, queryExpression, maxt, db.api)
+	queryExpression, selectedLabels := convertMatchersToPromQL(matchers, duration)
+	v, warnings, err := db.query(ctx, queryExpression, time.Unix(maxt, 0), db.api)
 	if err != nil {
 		return newListSeriesSet(nil, err, warnings)
 	}
@@ -882,16 +1018,58 @@ func (db *queryAccess) Select(ctx context.Context, sort bool, hints *storage.Sel
 	if !ok {
 		return newListSeriesSet(nil, fmt.Errorf("error querying Prometheus, expected type matrix response. actual type %v", v.Type()), nil)
 	}
-	// TODO(maxamin) GCM returns label names and values that are not in matchers.
-	// Ensure results from query are equivalent to the requested matchers because
-	// manager.go checks if returned labels have the same length as matchers.
-	// Upstream change to prometheus code may be necessary.
 	for i, sample := range m {
-		m[i].Metric = sample.Metric.MatchLabels(true, filteredMatchers...)
+		m[i].Metric = db.removeExportLabels(sample.Metric, selectedLabels)
+	}
+	if sortSeries {
+		slices.SortFunc(m, func(a, b promql.Series) int {
+			return labels.Compare(a.Metric, b.Metric)
+		})
 	}
 	return newListSeriesSet(m, err, warnings)
 }
 
+// removeExportLabels removes the labels that were added to the series on export, which are
+// empty resource labels and external labels. Labels that matchers select on are kept.
+// Series that had the value of an external label before the export cannot be told apart
+// and lose the label.
+func (db *queryAccess) removeExportLabels(lset labels.Labels, selectedLabels []string) labels.Labels {
+	b := labels.NewBuilder(lset)
+	lset.Range(func(l labels.Label) {
+		if slices.Contains(selectedLabels, l.Name) {
+			return
+		}
+		if l.Value == "" || db.externalLabels.Get(l.Name) == l.Value {
+			b.Del(l.Name)
+		}
+	})
+	return b.Labels()
+}
+
+// LabelValues returns the values of the label in the time range of the querier.
+func (db *queryAccess) LabelValues(ctx context.Context, name string, matchers ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	values, warnings, err := db.api.LabelValues(ctx, name, convertMatchersToSelectors(matchers), time.Unix(db.mint, 0), time.Unix(db.maxt, 0))
+	if err != nil {
+		return nil, nil, fmt.Errorf("query label values: %w", err)
+	}
+	res := make([]string, 0, len(values))
+	for _, v := range values {
+		res = append(res, string(v))
+	}
+	slices.Sort(res)
+	return res, convertV1WarningsToAnnotations(warnings), nil
+}
+
+// LabelNames returns the label names in the time range of the querier in sorted order.
+func (db *queryAccess) LabelNames(ctx context.Context, matchers ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	names, warnings, err := db.api.LabelNames(ctx, convertMatchersToSelectors(matchers), time.Unix(db.mint, 0), time.Unix(db.maxt, 0))
+	if err != nil {
+		return nil, nil, fmt.Errorf("query label names: %w", err)
+	}
+	slices.Sort(names)
+	return names, convertV1WarningsToAnnotations(warnings), nil
+}
+
 func (db *queryAccess) Close() error {
 	return nil
 }
@@ -905,9 +1083,16 @@ type ruleEvaluator struct {
 	rulesMetrics    *rules.Metrics
 
 	queryFunc         rules.QueryFunc
+	queryRouter       *queryRouter
 	rulesManager      *rules.Manager
 	lastEvaluatorOpts *evaluatorOptions
 	mtx               sync.Mutex
+
+	// The global configuration has its own lock as it's read by rule groups, which are
+	// stopped while mtx is held.
+	externalLabels  labels.Labels
+	ruleQueryOffset time.Duration
+	globalConfigMtx sync.Mutex
 }
 
 // Returns the URL that points to the rule-evaluator instance (set by the user). By default, or if
@@ -939,19 +1124,7 @@ func newRuleEvaluator(
 		return nil, fmt.Errorf("query client: %w", err)
 	}
 	queryFunc := newQueryFunc(logger, v1api)
-
-	rulesManager := rules.NewManager(&rules.ManagerOptions{
-		ExternalURL: getExternalURL(evaluatorOpts.GeneratorURL, evaluatorOpts.ProjectID),
-		QueryFunc:   queryFunc,
-		Context:     ctx,
-		Appendable:  appendable,
-		Queryable: &queryStorage{
-			api: v1api,
-		},
-		Logger:     logger,
-		NotifyFunc: sendAlerts(notifierManager, evaluatorOpts.ProjectID, evaluatorOpts.GeneratorURL),
-		Metrics:    rulesMetrics,
-	})
+	router := newQueryRouter(ctx, logger, evaluatorOpts, version, queryFunc)
 
 	evaluator := ruleEvaluator{
 		ctx:             ctx,
@@ -961,50 +1134,55 @@ func newRuleEvaluator(
 		notifierManager: notifierManager,
 		rulesMetrics:    rulesMetrics,
 
-		rulesManager:      rulesManager,
 		queryFunc:         queryFunc,
+		queryRouter:       router,
 		lastEvaluatorOpts: evaluatorOpts,
 	}
+	evaluator.rulesManager = evaluator.newRulesManager(evaluatorOpts, v1api, router.query)
 
 	return &evaluator, nil
 }
 
-func (e *ruleEvaluator) ApplyConfig(cfg *promforkconfig.Config, evaluatorOpts *evaluatorOptions) error {
-	if evaluatorOpts != nil && !reflect.DeepEqual(evaluatorOpts, e.lastEvaluatorOpts) {
-		e.lastEvaluatorOpts = evaluatorOpts
+// newRulesManager returns a rules manager that evaluates rules through the query API. The
+// "for" state of alerts is always restored from the query API of the default project.
+func (e *ruleEvaluator) newRulesManager(evaluatorOpts *evaluatorOptions, v1api v1.API, queryFunc rules.QueryFunc) *rules.Manager {
+	return rules.NewManager(&rules.ManagerOptions{
+		ExternalURL: getExternalURL(evaluatorOpts.GeneratorURL, evaluatorOpts.ProjectID),
+		QueryFunc:   queryFunc,
+		Context:     e.ctx,
+		Appendable:  e.appendable,
+		Queryable: &queryStorage{
+			api:            v1api,
+			externalLabels: e.getExternalLabels,
+		},
+		Logger:          e.logger,
+		NotifyFunc:      sendAlerts(e.notifierManager, evaluatorOpts.ProjectID, evaluatorOpts.GeneratorURL),
+		Metrics:         e.rulesMetrics,
+		OutageTolerance: evaluatorOpts.OutageTolerance,
+		ForGracePeriod:  evaluatorOpts.ForGracePeriod,
+		ResendDelay:     evaluatorOpts.ResendDelay,
+		// Rule groups without a query offset of their own use the global one.
+		DefaultRuleQueryOffset: e.getRuleQueryOffset,
+	})
+}
 
-		v1api, err := newAPI(e.ctx, evaluatorOpts, e.version)
-		if err != nil {
-			return fmt.Errorf("query client: %w", err)
-		}
-		queryFunc := newQueryFunc(e.logger, v1api)
-
-		rulesManager := rules.NewManager(&rules.ManagerOptions{
-			ExternalURL: getExternalURL(evaluatorOpts.GeneratorURL, evaluatorOpts.ProjectID),
-			QueryFunc:   queryFunc,
-			Context:     e.ctx,
-			Appendable:  e.appendable,
-			Queryable: &queryStorage{
-				api: v1api,
-			},
-			Logger:     e.logger,
-			NotifyFunc: sendAlerts(e.notifierManager, evaluatorOpts.ProjectID, evaluatorOpts.GeneratorURL),
-			Metrics:    e.rulesMetrics,
-		})
+func (e *ruleEvaluator) getExternalLabels() labels.Labels {
+	e.globalConfigMtx.Lock()
+	defer e.globalConfigMtx.Unlock()
+	return e.externalLabels
+}
 
-		// Set new rule-manager and flag before stopping, so we can rerun with the new one.
-		e.mtx.Lock()
-		oldRuleManager := e.rulesManager
-		e.rulesManager = rulesManager
-		oldRuleManager.Stop()
-		e.queryFunc = queryFunc
-		e.mtx.Unlock()
+func (e *ruleEvaluator) getRuleQueryOffset() time.Duration {
+	e.globalConfigMtx.Lock()
+	defer e.globalConfigMtx.Unlock()
+	return e.ruleQueryOffset
+}
 
-		_, err = queryFunc(e.ctx, "vector(1)", time.Now())
-		if err != nil {
-			_ = level.Error(e.logger).Log("msg", "Error querying Prometheus instance", "err", err)
-		}
-	}
+func (e *ruleEvaluator) ApplyConfig(cfg *promforkconfig.Config, evaluatorOpts *evaluatorOptions) error {
+	e.globalConfigMtx.Lock()
+	e.externalLabels = cfg.GlobalConfig.ExternalLabels
+	e.ruleQueryOffset = time.Duration(cfg.GlobalConfig.RuleQueryOffset)
+	e.globalConfigMtx.Unlock()
 
 	// Get all rule files matching the configuration paths.
 	var files []string
@@ -1015,13 +1193,77 @@ func (e *ruleEvaluator) ApplyConfig(cfg *promforkconfig.Config, evaluatorOpts *e
 		}
 		files = append(files, fs...)
 	}
-	return e.rulesManager.Update(
-		time.Duration(cfg.GlobalConfig.EvaluationInterval),
-		files,
-		cfg.GlobalConfig.ExternalLabels,
-		"",
-		nil,
-	)
+	interval := time.Duration(cfg.GlobalConfig.EvaluationInterval)
+
+	// The query projects are updated before the rule groups, so that new groups query the
+	// right project from their first evaluation.
+	opts := e.lastEvaluatorOpts
+	if evaluatorOpts != nil {
+		opts = evaluatorOpts
+	}
+	projects, err := readQueryProjects(opts.ProjectsFile)
+	if err != nil {
+		return err
+	}
+
+	if evaluatorOpts == nil || reflect.DeepEqual(evaluatorOpts, e.lastEvaluatorOpts) {
+		if err := e.queryRouter.update(projects); err != nil {
+			return err
+		}
+		return e.rulesManager.Update(interval, files, cfg.GlobalConfig.ExternalLabels, "", nil)
+	}
+	v1api, err := newAPI(e.ctx, evaluatorOpts, e.version)
+	if err != nil {
+		return fmt.Errorf("query client: %w", err)
+	}
+	queryFunc := newQueryFunc(e.logger, v1api)
+	router := newQueryRouter(e.ctx, e.logger, evaluatorOpts, e.version, queryFunc)
+	if err := router.update(projects); err != nil {
+		return err
+	}
+	rulesManager := e.newRulesManager(evaluatorOpts, v1api, router.query)
+
+	// The state of the current rule groups is carried over below, so it must not be restored
+	// from the query API again. Updating without any rule files marks the new manager as
+	// restored. The groups of the new manager are loaded but don't evaluate until it runs.
+	if err := rulesManager.Update(interval, nil, cfg.GlobalConfig.ExternalLabels, "", nil); err != nil {
+		return err
+	}
+	if err := rulesManager.Update(interval, files, cfg.GlobalConfig.ExternalLabels, "", nil); err != nil {
+		return err
+	}
+
+	// Set new rule-manager before stopping, so we can rerun with the new one.
+	e.mtx.Lock()
+	oldRuleManager := e.rulesManager
+	e.rulesManager = rulesManager
+	oldRuleManager.Stop()
+	copyRulesState(oldRuleManager, rulesManager)
+	e.queryFunc = queryFunc
+	e.queryRouter = router
+	e.lastEvaluatorOpts = evaluatorOpts
+	e.mtx.Unlock()
+
+	_, err = queryFunc(e.ctx, "vector(1)", time.Now())
+	if err != nil {
+		_ = level.Error(e.logger).Log("msg", "Error querying Prometheus instance", "err", err)
+	}
+	return nil
+}
+
+// copyRulesState copies the evaluation state, including active alerts, of each rule group
+// to the group with the same file and name in the other manager. Neither manager may be
+// evaluating its groups while the state is copied.
+func copyRulesState(from, to *rules.Manager) {
+	groups := map[string]*rules.Group{}
+	for _, g := range from.RuleGroups() {
+		groups[rules.GroupKey(g.File(), g.Name())] = g
+	}
+	for _, g := range to.RuleGroups() {
+		if old, ok := groups[rules.GroupKey(g.File(), g.Name())]; ok {
+			g.CopyState(old)
+		}
+	}
 }
 
 func (e *ruleEvaluator) Query(ctx context.Context, q string, t time.Time) (promql.Vector, error) {
@@ -1070,3 +1312,97 @@ func newQueryFunc(logger log.Logger, v1api v1.API) rules.QueryFunc {
 		return vec, nil
 	}
 }
+
+// queryRouter routes the queries of rule groups to the query API of the project that the
+// rules of their file are evaluated against.
+type queryRouter struct {
+	ctx          context.Context
+	logger       log.Logger
+	opts         *evaluatorOptions
+	version      string
+	defaultQuery rules.QueryFunc
+
+	mtx sync.Mutex
+	// projects maps the base names of rule files to their query project.
+	projects map[string]string
+	queries  map[string]rules.QueryFunc
+}
+
+func newQueryRouter(ctx context.Context, logger log.Logger, opts *evaluatorOptions, version string, defaultQuery rules.QueryFunc) *queryRouter {
+	return &queryRouter{
+		ctx:          ctx,
+		logger:       logger,
+		opts:         opts,
+		version:      version,
+		defaultQuery: defaultQuery,
+		queries:      map[string]rules.QueryFunc{},
+	}
+}
+
+// update sets the query projects of the rule files. Query clients are created for new projects
+// and dropped for projects that are no longer used.
+func (r *queryRouter) update(projects map[string]string) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	queries := make(map[string]rules.QueryFunc, len(r.queries))
+	for _, projectID := range projects {
+		if _, ok := queries[projectID]; ok {
+			continue
+		}
+		if q, ok := r.queries[projectID]; ok {
+			queries[projectID] = q
+			continue
+		}
+		opts := *r.opts
+		opts.ProjectID = projectID
+		v1api, err := newAPI(r.ctx, &opts, r.version)
+		if err != nil {
+			return fmt.Errorf("query client for project %q: %w", projectID, err)
+		}
+		queries[projectID] = newQueryFunc(log.With(r.logger, "project", projectID), v1api)
+	}
+	r.projects = projects
+	r.queries = queries
+	return nil
+}
+
+// query runs the query against the project of the rule group it originates from, if any.
+func (r *queryRouter) query(ctx context.Context, q string, t time.Time) (promql.Vector, error) {
+	queryFunc := r.defaultQuery
+	if file := ruleGroupFile(ctx); file != "" {
+		r.mtx.Lock()
+		if projectID, ok := r.projects[filepath.Base(file)]; ok {
+			queryFunc = r.queries[projectID]
+		}
+		r.mtx.Unlock()
+	}
+	return queryFunc(ctx, q, t)
+}
+
+// ruleGroupFile returns the file of the rule group that the query of the context originates
+// from, if any.
+func ruleGroupFile(ctx context.Context) string {
+	origin, _ := ctx.Value(promql.QueryOrigin{}).(map[string]any)
+	group, _ := origin["ruleGroup"].(map[string]string)
+	return group["file"]
+}
+
+// readQueryProjects reads the query projects of rule files from the given file. A missing
+// file means that all rules are evaluated against the default project.
+func readQueryProjects(filename string) (map[string]string, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read query projects: %w", err)
+	}
+	var projects map[string]string
+	if err := json.Unmarshal(content, &projects); err != nil {
+		return nil, fmt.Errorf("unmarshal query projects %q: %w", filename, err)
+	}
+	return projects, nil
+}
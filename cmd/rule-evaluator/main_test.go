@@ -18,7 +18,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -29,8 +34,10 @@ import (
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/common/version"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/notifier"
 	"github.com/prometheus/prometheus/promql"
 	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/rules"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/tsdb/chunkenc"
 	"github.com/prometheus/prometheus/util/annotations"
@@ -186,6 +193,512 @@ func TestSelect(t *testing.T) {
 	}
 }
 
+func TestSelectExportLabels(t *testing.T) {
+	db := &queryAccess{
+		mint:           1000,
+		maxt:           2000,
+		externalLabels: labels.FromStrings("cluster", "c1", "location", "l1", "project_id", "p1"),
+		query: func(_ context.Context, q string, _ time.Time, _ v1.API) (parser.Value, v1.Warnings, error) {
+			// Hints narrow down the range of the querier.
+			if want := `{__name__="ALERTS_FOR_STATE", alertname="Foo"}[500s]`; q != want {
+				return nil, nil, fmt.Errorf("expected query %s, got %s", want, q)
+			}
+			return promql.Matrix{
+				{
+					Metric: labels.FromStrings(model.MetricNameLabel, "ALERTS_FOR_STATE", "alertname", "Foo", "pod", "b", "cluster", "c1", "location", "l1", "project_id", "p1", "job", ""),
+					Floats: []promql.FPoint{{T: 1000, F: 1}},
+				},
+				{
+					// Series of other clusters keep their labels.
+					Metric: labels.FromStrings(model.MetricNameLabel, "ALERTS_FOR_STATE", "alertname", "Foo", "pod", "a", "cluster", "c2", "location", "l1", "project_id", "p1"),
+					Floats: []promql.FPoint{{T: 1000, F: 2}},
+				},
+			}, nil, nil
+		},
+	}
+	matchers := []*labels.Matcher{
+		labels.MustNewMatcher(labels.MatchEqual, model.MetricNameLabel, "ALERTS_FOR_STATE"),
+		labels.MustNewMatcher(labels.MatchEqual, "alertname", "Foo"),
+	}
+	got := db.Select(t.Context(), true, &storage.SelectHints{Start: 1500000, End: 2000000}, matchers...)
+	if got.Err() != nil {
+		t.Fatal(got.Err())
+	}
+	want := promql.Matrix{
+		{
+			Metric: labels.FromStrings(model.MetricNameLabel, "ALERTS_FOR_STATE", "alertname", "Foo", "pod", "a", "cluster", "c2"),
+			Floats: []promql.FPoint{{T: 1000, F: 2}},
+		},
+		{
+			Metric: labels.FromStrings(model.MetricNameLabel, "ALERTS_FOR_STATE", "alertname", "Foo", "pod", "b"),
+			Floats: []promql.FPoint{{T: 1000, F: 1}},
+		},
+	}
+	if diff := cmp.Diff(want, expandSeriesSet(got)); diff != "" {
+		t.Errorf("unexpected result (-want, +got): %s", diff)
+	}
+}
+
+// fakeAPI is a query API that only supports instant queries.
+type fakeAPI struct {
+	v1.API
+	query func(ctx context.Context, q string, ts time.Time) (model.Value, error)
+}
+
+func (api *fakeAPI) Query(ctx context.Context, q string, ts time.Time, _ ...v1.Option) (model.Value, v1.Warnings, error) {
+	v, err := api.query(ctx, q, ts)
+	return v, nil, err
+}
+
+type nopAppendable struct{}
+
+func (nopAppendable) Appender(context.Context) storage.Appender {
+	return nopAppender{}
+}
+
+type nopAppender struct {
+	storage.Appender
+}
+
+func (nopAppender) Append(storage.SeriesRef, labels.Labels, int64, float64) (storage.SeriesRef, error) {
+	return 0, nil
+}
+
+func (nopAppender) Commit() error {
+	return nil
+}
+
+func (nopAppender) Rollback() error {
+	return nil
+}
+
+// recordingAppendable calls a function for each appended sample.
+type recordingAppendable func(l labels.Labels, t int64)
+
+func (a recordingAppendable) Appender(context.Context) storage.Appender {
+	return recordingAppender{record: a}
+}
+
+type recordingAppender struct {
+	nopAppender
+	record recordingAppendable
+}
+
+func (a recordingAppender) Append(_ storage.SeriesRef, l labels.Labels, t int64, _ float64) (storage.SeriesRef, error) {
+	a.record(l, t)
+	return 0, nil
+}
+
+// fakeLease is a lease that is held for a fixed time range.
+type fakeLease struct {
+	start, end time.Time
+}
+
+func (l *fakeLease) Range() (time.Time, time.Time, bool) {
+	return l.start, l.end, true
+}
+
+func (*fakeLease) Run(ctx context.Context) {
+	<-ctx.Done()
+}
+
+func (*fakeLease) OnLeaderChange(func()) {}
+
+func TestLeaderHandover(t *testing.T) {
+	start := time.Now().Truncate(time.Hour)
+	handover := start.Add(20 * time.Minute)
+	// The second replica acquires the lease once it expired without being renewed.
+	leases := map[string]*fakeLease{
+		"a": {start: start.Add(-time.Hour), end: handover},
+		"b": {start: handover.Add(15 * time.Second), end: start.Add(2 * time.Hour)},
+	}
+
+	var (
+		mtx     sync.Mutex
+		written = map[int64][]string{}
+	)
+	var groups []*rules.Group
+	for _, replica := range []string{"a", "b"} {
+		rule := rules.NewRecordingRule("job:up:sum", Must(parser.ParseExpr(`sum(up)`)), labels.EmptyLabels())
+		groups = append(groups, rules.NewGroup(rules.GroupOptions{
+			Name:     "group",
+			File:     "rules.yaml",
+			Interval: time.Minute,
+			Rules:    []rules.Rule{rule},
+			Opts: &rules.ManagerOptions{
+				Context: t.Context(),
+				QueryFunc: func(_ context.Context, _ string, ts time.Time) (promql.Vector, error) {
+					return promql.Vector{{T: ts.UnixMilli(), F: 1}}, nil
+				},
+				Appendable: &leaderAppendable{
+					Appendable: recordingAppendable(func(_ labels.Labels, t int64) {
+						mtx.Lock()
+						defer mtx.Unlock()
+						written[t] = append(written[t], replica)
+					}),
+					lease: leases[replica],
+				},
+				Logger:  log.NewNopLogger(),
+				Metrics: rules.NewGroupMetrics(nil),
+			},
+		}))
+	}
+
+	var evaluated []time.Time
+	for now := start; now.Before(start.Add(time.Hour)); now = now.Add(time.Minute) {
+		// Replicas align the evaluation timestamps of a group, regardless of when they started.
+		ts := groups[0].EvalTimestamp(now.UnixNano())
+		if other := groups[1].EvalTimestamp(now.UnixNano()); !other.Equal(ts) {
+			t.Fatalf("expected replicas to evaluate at %s, got %s", ts, other)
+		}
+		for _, g := range groups {
+			g.Eval(t.Context(), ts)
+		}
+		evaluated = append(evaluated, ts)
+	}
+
+	var missing int
+	for _, ts := range evaluated {
+		switch replicas := written[ts.UnixMilli()]; {
+		case len(replicas) > 1:
+			t.Errorf("duplicate results at %s from replicas %v", ts, replicas)
+		case len(replicas) == 0:
+			// Only results between the end of the old and start of the new lease are lost.
+			if !ts.After(leases["a"].end) || !ts.Before(leases["b"].start) {
+				t.Errorf("missing results at %s outside of the handover", ts)
+			}
+			missing++
+		}
+	}
+	if missing > 1 {
+		t.Errorf("expected at most one evaluation to be lost during the handover, got %d", missing)
+	}
+	if len(written[evaluated[0].UnixMilli()]) != 1 || written[evaluated[0].UnixMilli()][0] != "a" {
+		t.Errorf("expected first results from replica a, got %v", written[evaluated[0].UnixMilli()])
+	}
+	if last := evaluated[len(evaluated)-1]; len(written[last.UnixMilli()]) != 1 || written[last.UnixMilli()][0] != "b" {
+		t.Errorf("expected last results from replica b, got %v", written[last.UnixMilli()])
+	}
+}
+
+func TestWithReplicaLabel(t *testing.T) {
+	cfg, err := loadConfig([]byte(`
+global:
+  external_labels:
+    cluster: c1
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := withReplicaLabel(cfg, "replica", "rule-evaluator-0").GlobalConfig.ExternalLabels
+	if want := labels.FromStrings("cluster", "c1", "replica", "rule-evaluator-0"); !labels.Equal(got, want) {
+		t.Errorf("expected external labels %s, got %s", want, got)
+	}
+	// The original configuration, as used by the exporter and notifier, is unchanged.
+	if want := labels.FromStrings("cluster", "c1"); !labels.Equal(cfg.GlobalConfig.ExternalLabels, want) {
+		t.Errorf("expected original external labels %s, got %s", want, cfg.GlobalConfig.ExternalLabels)
+	}
+	if withReplicaLabel(cfg, "", "rule-evaluator-0") != cfg {
+		t.Error("expected configuration to be unchanged without replica label")
+	}
+}
+
+func TestRestoreForState(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	activeAt := now.Add(-20 * time.Minute)
+	downAt := now.Add(-time.Minute)
+
+	api := &fakeAPI{
+		query: func(_ context.Context, q string, _ time.Time) (model.Value, error) {
+			if !strings.Contains(q, "ALERTS_FOR_STATE") {
+				return nil, fmt.Errorf("unexpected query %s", q)
+			}
+			// The series as exported with the external labels.
+			return model.Matrix{{
+				Metric: model.Metric{
+					model.MetricNameLabel: "ALERTS_FOR_STATE",
+					"alertname":           "PodDown",
+					"severity":            "page",
+					"pod":                 "a",
+					"project_id":          "p1",
+					"location":            "l1",
+					"cluster":             "c1",
+				},
+				Values: []model.SamplePair{{
+					Timestamp: model.TimeFromUnixNano(downAt.UnixNano()),
+					Value:     model.SampleValue(activeAt.Unix()),
+				}},
+			}}, nil
+		},
+	}
+	rule := rules.NewAlertingRule(
+		"PodDown", Must(parser.ParseExpr(`up == 0`)), 30*time.Minute, 0,
+		labels.FromStrings("severity", "page"), labels.EmptyLabels(), labels.EmptyLabels(), "", false, log.NewNopLogger(),
+	)
+	group := rules.NewGroup(rules.GroupOptions{
+		Name:     "group",
+		Interval: time.Minute,
+		Rules:    []rules.Rule{rule},
+		Opts: &rules.ManagerOptions{
+			Context: t.Context(),
+			QueryFunc: func(context.Context, string, time.Time) (promql.Vector, error) {
+				return promql.Vector{{Metric: labels.FromStrings("pod", "a"), F: 0}}, nil
+			},
+			Queryable: &queryStorage{
+				api: api,
+				externalLabels: func() labels.Labels {
+					return labels.FromStrings("cluster", "c1", "location", "l1", "project_id", "p1")
+				},
+			},
+			Appendable:      nopAppendable{},
+			NotifyFunc:      func(context.Context, string, ...*rules.Alert) {},
+			Logger:          log.NewNopLogger(),
+			Metrics:         rules.NewGroupMetrics(nil),
+			OutageTolerance: time.Hour,
+			ForGracePeriod:  10 * time.Minute,
+		},
+	})
+
+	group.Eval(t.Context(), now)
+	group.RestoreForState(now)
+
+	alerts := rule.ActiveAlerts()
+	if len(alerts) != 1 {
+		t.Fatalf("expected 1 active alert, got %d", len(alerts))
+	}
+	// The alert was pending for 19 minutes before the restart, which the restored state
+	// shifts by the one minute downtime.
+	if want := activeAt.Add(now.Sub(downAt)); !alerts[0].ActiveAt.Equal(want) {
+		t.Errorf("expected alert to be active at %s, got %s", want, alerts[0].ActiveAt)
+	}
+}
+
+func TestApplyConfigKeepsAlerts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"pod":"a"},"value":[%d,"0"]}]}}`, time.Now().Unix())
+	}))
+	defer srv.Close()
+	targetURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	ruleFile := filepath.Join(dir, "rules.yaml")
+	// The long interval ensures the groups only evaluate when the test does so.
+	if err := os.WriteFile(ruleFile, []byte(`
+groups:
+- name: group
+  interval: 24h
+  rules:
+  - alert: PodDown
+    expr: up == 0
+    for: 1h
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfig(fmt.Appendf(nil, "rule_files: [%q]", ruleFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := log.NewNopLogger()
+	opts := &evaluatorOptions{
+		DisableAuth: true,
+		TargetURL:   targetURL,
+	}
+	re, err := newRuleEvaluator(t.Context(), logger, opts, version.Version, nopAppendable{}, notifier.NewManager(&notifier.Options{}, logger), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := re.ApplyConfig(cfg, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		re.Run()
+	})
+	defer wg.Wait()
+	defer re.Stop()
+
+	activeAlert := func() *rules.Alert {
+		re.mtx.Lock()
+		defer re.mtx.Unlock()
+		groups := re.rulesManager.RuleGroups()
+		if len(groups) != 1 {
+			t.Fatalf("expected 1 rule group, got %d", len(groups))
+		}
+		alerts := groups[0].AlertingRules()[0].ActiveAlerts()
+		if len(alerts) != 1 {
+			t.Fatalf("expected 1 active alert, got %d", len(alerts))
+		}
+		return alerts[0]
+	}
+
+	now := time.Now().Truncate(time.Second)
+	re.mtx.Lock()
+	group := re.rulesManager.RuleGroups()[0]
+	re.mtx.Unlock()
+	group.Eval(t.Context(), now.Add(-2*time.Hour))
+	group.Eval(t.Context(), now)
+	if alert := activeAlert(); alert.State != rules.StateFiring {
+		t.Fatalf("expected firing alert, got %s", alert.State)
+	}
+
+	// Any change of the options, such as rotated credentials, replaces the rules manager.
+	changed := *opts
+	changed.ResendDelay = time.Minute
+	if err := re.ApplyConfig(cfg, &changed); err != nil {
+		t.Fatal(err)
+	}
+	re.mtx.Lock()
+	newGroup := re.rulesManager.RuleGroups()[0]
+	re.mtx.Unlock()
+	if newGroup == group {
+		t.Fatal("expected a new rules manager after changing the options")
+	}
+	alert := activeAlert()
+	if alert.State != rules.StateFiring {
+		t.Errorf("expected alert to keep firing, got %s", alert.State)
+	}
+	if want := now.Add(-2 * time.Hour); !alert.ActiveAt.Equal(want) {
+		t.Errorf("expected alert to be active at %s, got %s", want, alert.ActiveAt)
+	}
+}
+
+func TestApplyConfigQueryOffset(t *testing.T) {
+	ruleFile := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(ruleFile, []byte(`
+groups:
+- name: default
+  rules:
+  - record: job:up:sum
+    expr: sum by (job) (up)
+- name: offset
+  query_offset: 5m
+  rules:
+  - record: job:up:sum
+    expr: sum by (job) (up)
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := log.NewNopLogger()
+	opts := &evaluatorOptions{
+		DisableAuth: true,
+		TargetURL:   Must(url.Parse("http://localhost:9090")),
+	}
+	re, err := newRuleEvaluator(t.Context(), logger, opts, version.Version, nopAppendable{}, notifier.NewManager(&notifier.Options{}, logger), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, offset := range []string{"1m", "2m"} {
+		cfg, err := loadConfig(fmt.Appendf(nil, "global: {rule_query_offset: %s}\nrule_files: [%q]", offset, ruleFile))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := re.ApplyConfig(cfg, opts); err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]time.Duration{}
+		for _, g := range re.rulesManager.RuleGroups() {
+			got[g.Name()] = g.QueryOffset()
+		}
+		want := map[string]time.Duration{
+			"default": time.Duration(Must(model.ParseDuration(offset))),
+			"offset":  5 * time.Minute,
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("unexpected query offsets (-want, +got): %s", diff)
+		}
+	}
+}
+
+func TestQueryRouter(t *testing.T) {
+	// The server returns a sample for each project that holds the length of the project ID.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		projectID := strings.Split(strings.TrimPrefix(r.URL.Path, "/projects/"), "/")[0]
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[%d,"%d"]}]}}`, time.Now().Unix(), len(projectID))
+	}))
+	defer srv.Close()
+
+	logger := log.NewNopLogger()
+	opts := &evaluatorOptions{
+		DisableAuth: true,
+		ProjectID:   "default",
+		TargetURL:   Must(url.Parse(srv.URL + "/projects/" + projectIDVar)),
+	}
+	v1api, err := newAPI(t.Context(), opts, version.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := newQueryRouter(t.Context(), logger, opts, version.Version, newQueryFunc(logger, v1api))
+	if err := router.update(map[string]string{"team.yaml": "team-project"}); err != nil {
+		t.Fatal(err)
+	}
+
+	query := func(ctx context.Context) float64 {
+		vec, err := router.query(ctx, "vector(1)", time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(vec) != 1 {
+			t.Fatalf("expected 1 sample, got %v", vec)
+		}
+		return vec[0].F
+	}
+	originContext := func(file string) context.Context {
+		return promql.NewOriginContext(t.Context(), map[string]any{
+			"ruleGroup": map[string]string{"file": file, "name": "group"},
+		})
+	}
+	if got, want := query(t.Context()), float64(len("default")); got != want {
+		t.Errorf("expected query without rule group to query %v, got %v", want, got)
+	}
+	if got, want := query(originContext("/etc/rules/other.yaml")), float64(len("default")); got != want {
+		t.Errorf("expected rule file without query project to query %v, got %v", want, got)
+	}
+	if got, want := query(originContext("/etc/rules/team.yaml")), float64(len("team-project")); got != want {
+		t.Errorf("expected rule file with query project to query %v, got %v", want, got)
+	}
+
+	// Removing the query project routes the queries of the file to the default project again.
+	if err := router.update(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := query(originContext("/etc/rules/team.yaml")), float64(len("default")); got != want {
+		t.Errorf("expected rule file without query project to query %v, got %v", want, got)
+	}
+}
+
+func TestReadQueryProjects(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "query-projects.json")
+	if err := os.WriteFile(filename, []byte(`{"rules__ns__name.yaml":"team-project"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readQueryProjects(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]string{"rules__ns__name.yaml": "team-project"}, got); diff != "" {
+		t.Errorf("unexpected query projects (-want, +got): %s", diff)
+	}
+
+	// Without rules that query other projects the operator doesn't generate the file.
+	got, err = readQueryProjects(filepath.Join(dir, "missing.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != nil {
+		t.Errorf("expected no query projects, got %v", got)
+	}
+}
+
 // Regression test against b/470033222.
 func TestGracefulShutdown(t *testing.T) {
 	re, err := newRuleEvaluator(
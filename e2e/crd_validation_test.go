@@ -2049,6 +2049,74 @@ func TestCRDValidation(t *testing.T) {
 				},
 				wantErr: false,
 			},
+			"invalid-keep-firing-for": {
+				obj: &monitoringv1.Rules{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "invalid-keep-firing-for",
+						Namespace: "default",
+					},
+					Spec: monitoringv1.RulesSpec{
+						Groups: []monitoringv1.RuleGroup{
+							{
+								Rules: []monitoringv1.Rule{
+									{
+										Record:        "test",
+										KeepFiringFor: "5m",
+									},
+								},
+							},
+						},
+					},
+				},
+				wantErr: true,
+			},
+			"valid-group-options": {
+				obj: &monitoringv1.Rules{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "valid-group-options",
+						Namespace: "default",
+					},
+					Spec: monitoringv1.RulesSpec{
+						Groups: []monitoringv1.RuleGroup{
+							{
+								QueryOffset: "1m",
+								Limit:       10,
+								Labels: map[string]string{
+									"team": "a",
+								},
+								Rules: []monitoringv1.Rule{
+									{
+										Alert:         "test",
+										KeepFiringFor: "5m",
+									},
+								},
+							},
+						},
+					},
+				},
+				wantErr: false,
+			},
+			"negative-limit": {
+				obj: &monitoringv1.Rules{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "negative-limit",
+						Namespace: "default",
+					},
+					Spec: monitoringv1.RulesSpec{
+						Groups: []monitoringv1.RuleGroup{
+							{
+								Limit: -1,
+								Rules: []monitoringv1.Rule{
+									{
+										Record: "test",
+									},
+								},
+							},
+						},
+					},
+				},
+				wantErr: true,
+			},
 			"alert-and-record-both-set": {
 				obj: &monitoringv1.Rules{
 					ObjectMeta: metav1.ObjectMeta{
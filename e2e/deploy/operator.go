@@ -16,11 +16,13 @@ package deploy
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"strings"
 
 	"github.com/GoogleCloudPlatform/prometheus-engine/e2e/kube"
 	"github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator"
-	corev1 "k8s.io/api/core/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -30,23 +32,43 @@ func WaitForOperatorReady(ctx context.Context, kubeClient client.Client) error {
 	return kube.WaitForDeploymentReady(ctx, kubeClient, operator.DefaultOperatorNamespace, operator.NameOperator)
 }
 
-// OperatorLogs returns the operator pods logs.
+// OperatorLogs returns the logs of all ready operator pods.
 func OperatorLogs(ctx context.Context, restConfig *rest.Config, kubeClient client.Client, operatorNamespace string) (string, error) {
-	pod, err := operatorPod(ctx, kubeClient, operatorNamespace)
+	podList, err := kube.DeploymentPods(ctx, kubeClient, operatorNamespace, operator.NameOperator)
 	if err != nil {
 		return "", err
 	}
-	return kube.PodLogs(ctx, restConfig, pod.Namespace, pod.Name, "operator")
+	podList = kube.PodsReady(podList)
+	if len(podList) == 0 {
+		return "", errors.New("no ready operator pods found")
+	}
+	var sb strings.Builder
+	for _, pod := range podList {
+		logs, err := kube.PodLogs(ctx, restConfig, pod.Namespace, pod.Name, "operator")
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(logs)
+	}
+	return sb.String(), nil
 }
 
-func operatorPod(ctx context.Context, kubeClient client.Client, operatorNamespace string) (*corev1.Pod, error) {
-	podList, err := kube.DeploymentPods(ctx, kubeClient, operatorNamespace, operator.NameOperator)
-	if err != nil {
-		return nil, err
+// OperatorLeader returns the name of the operator pod currently holding the leader election
+// lease.
+func OperatorLeader(ctx context.Context, kubeClient client.Client, operatorNamespace string) (string, error) {
+	lease := coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: operatorNamespace,
+			Name:      operator.NameOperatorLease,
+		},
 	}
-	podList = kube.PodsReady(podList)
-	if len(podList) != 1 {
-		return nil, fmt.Errorf("expected 1 pod, found %d", len(podList))
+	if err := kubeClient.Get(ctx, client.ObjectKeyFromObject(&lease), &lease); err != nil {
+		return "", err
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == "" {
+		return "", errors.New("lease is not held")
 	}
-	return &podList[0], nil
+	// The identity is made up of the pod's hostname, i.e. its name, and a unique suffix.
+	name, _, _ := strings.Cut(*lease.Spec.HolderIdentity, "_")
+	return name, nil
 }
@@ -0,0 +1,110 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/prometheus-engine/e2e/deploy"
+	"github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator"
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TestOperatorFailover validates that configuration keeps converging after the operator
+// replica holding the leader election lease goes away.
+func TestOperatorFailover(t *testing.T) {
+	ctx := contextWithDeadline(t)
+	kubeClient, restConfig, err := setupCluster(ctx, t)
+	if err != nil {
+		t.Fatalf("error instantiating clients. err: %s", err)
+	}
+
+	t.Run("collector-deployed", testCollectorDeployed(ctx, restConfig, kubeClient))
+	t.Run("collector-operatorconfig", testCollectorOperatorConfig(ctx, kubeClient))
+	t.Run("enable-target-status", testEnableTargetStatus(ctx, kubeClient))
+	t.Run("leader-failover", testOperatorLeaderFailover(ctx, kubeClient))
+	// Both the scrape config and the target status are only updated by the new leader.
+	pm := &monitoringv1.PodMonitoring{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "collector-podmon-failover",
+			Namespace: operator.DefaultOperatorNamespace,
+		},
+		Spec: monitoringv1.PodMonitoringSpec{
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					operator.LabelAppName: operator.NameCollector,
+				},
+			},
+			Endpoints: []monitoringv1.ScrapeEndpoint{
+				{
+					Port:     intstr.FromString(operator.CollectorPrometheusContainerPortName),
+					Interval: "5s",
+				},
+			},
+		},
+	}
+	t.Run("podmonitoring-ready", testEnsurePodMonitoringReady(ctx, kubeClient, pm))
+}
+
+// testOperatorLeaderFailover deletes the operator leader pod and waits for another
+// replica to take over.
+func testOperatorLeaderFailover(ctx context.Context, kubeClient client.Client) func(*testing.T) {
+	return func(t *testing.T) {
+		var leader string
+		err := wait.PollUntilContextCancel(ctx, pollDuration, true, func(ctx context.Context) (bool, error) {
+			var err error
+			if leader, err = deploy.OperatorLeader(ctx, kubeClient, operator.DefaultOperatorNamespace); err != nil {
+				t.Logf("no operator leader yet: %s", err)
+				return false, nil
+			}
+			return true, nil
+		})
+		if err != nil {
+			t.Fatalf("waiting for operator leader: %s", err)
+		}
+
+		t.Logf("deleting operator leader %q", leader)
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: operator.DefaultOperatorNamespace,
+				Name:      leader,
+			},
+		}
+		if err := kubeClient.Delete(ctx, pod); err != nil {
+			t.Fatalf("delete operator leader pod: %s", err)
+		}
+
+		err = wait.PollUntilContextCancel(ctx, pollDuration, true, func(ctx context.Context) (bool, error) {
+			newLeader, err := deploy.OperatorLeader(ctx, kubeClient, operator.DefaultOperatorNamespace)
+			if err != nil {
+				t.Logf("no operator leader yet: %s", err)
+				return false, nil
+			}
+			return newLeader != leader, nil
+		})
+		if err != nil {
+			t.Fatalf("waiting for new operator leader: %s", err)
+		}
+		if err := deploy.WaitForOperatorReady(ctx, kubeClient); err != nil {
+			t.Fatalf("waiting for operator deployment to be ready: %s", err)
+		}
+	}
+}
@@ -82,6 +82,10 @@ update_crdgen() {
 		${SED} -i '0,/---/{/---/d}' $i
 		# removed the crd status hack , see https://github.com/kubernetes-sigs/controller-tools/pull/630
 		echo "$(cat $i)" >$i
+		# CRDs still serving v1alpha1 are converted by the operator's conversion webhook.
+		if grep -q '^    name: v1alpha1$' $i; then
+			${SED} -i '/^spec:$/a\  conversion:\n    strategy: Webhook\n    webhook:\n      clientConfig:\n        service:\n          name: gmp-operator\n          namespace: gmp-system\n          path: /convert\n          port: 443\n      conversionReviewVersions:\n      - v1' $i
+		fi
 		echo -e "$(cat ${REPO_ROOT}/hack/boilerplate.txt)\n$(cat $i)" >$i
 	done
 
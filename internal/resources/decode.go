@@ -0,0 +1,202 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resources decodes GMP resources and the Kubernetes objects they reference
+// from manifests, as an API server would store them.
+package resources
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/defaulting"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/GoogleCloudPlatform/prometheus-engine/manifests"
+	"github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator"
+)
+
+// clusterScopedKinds are the kinds accepted by Decode that are not namespaced.
+var clusterScopedKinds = map[string]bool{
+	"ClusterPodMonitoring":  true,
+	"ClusterNodeMonitoring": true,
+	"ClusterRules":          true,
+	"GlobalRules":           true,
+	"Namespace":             true,
+}
+
+// Decode decodes a stream of YAML or JSON Kubernetes manifests into typed objects
+// that can be passed to operator.Render. Schema defaults of the GMP CRDs are applied, just like
+// the API server would when the resources are created. Namespaced objects without a
+// namespace are placed in the given default namespace.
+func Decode(r io.Reader, defaultNamespace string) ([]client.Object, error) {
+	sc, err := operator.NewScheme()
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize Kubernetes scheme: %w", err)
+	}
+	schemas, err := loadCRDSchemas()
+	if err != nil {
+		return nil, err
+	}
+
+	var objs []client.Object
+	decoder := k8syaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		var u unstructured.Unstructured
+		if err := decoder.Decode(&u.Object); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("decode manifest: %w", err)
+		}
+		// Skip empty documents.
+		if len(u.Object) == 0 {
+			continue
+		}
+		gvk := u.GroupVersionKind()
+		if u.IsList() {
+			return nil, fmt.Errorf("lists are not supported, found %s", gvk)
+		}
+		if s, ok := schemas[gvk]; ok {
+			defaulting.Default(u.Object, s)
+		}
+		if u.GetNamespace() == "" && !clusterScopedKinds[gvk.Kind] {
+			u.SetNamespace(defaultNamespace)
+		}
+		// Objects taken from a live cluster carry a resource version, which must not be set
+		// when they are added to a client.
+		u.SetResourceVersion("")
+
+		typed, err := sc.New(gvk)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported kind %s: %w", gvk, err)
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, typed); err != nil {
+			return nil, fmt.Errorf("convert %s %s/%s: %w", gvk.Kind, u.GetNamespace(), u.GetName(), err)
+		}
+		obj, ok := typed.(client.Object)
+		if !ok {
+			return nil, fmt.Errorf("unsupported kind %s", gvk)
+		}
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// Read decodes the objects from a file, a directory of YAML files or stdin if input is "-".
+// Directories are walked recursively for files with a ".yaml" or ".yml" extension.
+func Read(input, defaultNamespace string) ([]client.Object, error) {
+	if input == "-" {
+		return Decode(os.Stdin, defaultNamespace)
+	}
+	info, err := os.Stat(input)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return readFile(input, defaultNamespace)
+	}
+
+	var objs []client.Object
+	err = filepath.WalkDir(input, func(fp string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// Skip hidden files and directories.
+		if fp != input && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if ext := strings.ToLower(filepath.Ext(fp)); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		res, err := readFile(fp, defaultNamespace)
+		if err != nil {
+			return err
+		}
+		objs = append(objs, res...)
+		return nil
+	})
+	return objs, err
+}
+
+func readFile(path, defaultNamespace string) ([]client.Object, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	objs, err := Decode(f, defaultNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return objs, nil
+}
+
+// loadCRDSchemas returns the structural schemas of all GMP CRD versions by kind.
+func loadCRDSchemas() (map[schema.GroupVersionKind]*structuralschema.Structural, error) {
+	schemas := map[schema.GroupVersionKind]*structuralschema.Structural{}
+
+	decoder := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifests.CRDManifest), 4096)
+	for {
+		var crd apiextensionsv1.CustomResourceDefinition
+		if err := decoder.Decode(&crd); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("decode CRD manifest: %w", err)
+		}
+		if crd.Kind != "CustomResourceDefinition" {
+			continue
+		}
+		for _, v := range crd.Spec.Versions {
+			if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			var internal apiextensions.JSONSchemaProps
+			if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(v.Schema.OpenAPIV3Schema, &internal, nil); err != nil {
+				return nil, fmt.Errorf("convert schema of CRD %s/%s: %w", crd.Name, v.Name, err)
+			}
+			s, err := structuralschema.NewStructural(&internal)
+			if err != nil {
+				return nil, fmt.Errorf("structural schema of CRD %s/%s: %w", crd.Name, v.Name, err)
+			}
+			gvk := schema.GroupVersionKind{
+				Group:   crd.Spec.Group,
+				Version: v.Name,
+				Kind:    crd.Spec.Names.Kind,
+			}
+			schemas[gvk] = s
+		}
+	}
+	return schemas, nil
+}
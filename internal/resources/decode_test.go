@@ -0,0 +1,94 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"strings"
+	"testing"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+func TestDecode(t *testing.T) {
+	input := `
+apiVersion: monitoring.googleapis.com/v1
+kind: PodMonitoring
+metadata:
+  name: app
+  resourceVersion: "123"
+spec:
+  selector:
+    matchLabels:
+      app: foo
+  endpoints:
+  - port: metrics
+    interval: 30s
+---
+---
+apiVersion: monitoring.googleapis.com/v1
+kind: ClusterRules
+metadata:
+  name: rules
+spec:
+  groups:
+  - name: group
+    interval: 30s
+    rules:
+    - record: foo
+      expr: up
+`
+	objs, err := Decode(strings.NewReader(input), "ns1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(objs))
+	}
+
+	pm, ok := objs[0].(*monitoringv1.PodMonitoring)
+	if !ok {
+		t.Fatalf("expected PodMonitoring, got %T", objs[0])
+	}
+	if pm.Namespace != "ns1" {
+		t.Errorf("expected default namespace %q, got %q", "ns1", pm.Namespace)
+	}
+	if pm.ResourceVersion != "" {
+		t.Errorf("expected resource version to be cleared, got %q", pm.ResourceVersion)
+	}
+	// Defaults from the CRD schema must be applied.
+	if pm.Spec.TargetLabels.Metadata == nil {
+		t.Error("expected defaulted target label metadata")
+	}
+
+	cr, ok := objs[1].(*monitoringv1.ClusterRules)
+	if !ok {
+		t.Fatalf("expected ClusterRules, got %T", objs[1])
+	}
+	if cr.Namespace != "" {
+		t.Errorf("expected cluster-scoped object without namespace, got %q", cr.Namespace)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, input := range []string{
+		"apiVersion: v1\nkind: List\nitems: []\n",
+		"apiVersion: example.com/v1\nkind: Unknown\nmetadata:\n  name: foo\n",
+		"not: [valid\n",
+	} {
+		if _, err := Decode(strings.NewReader(input), "default"); err == nil {
+			t.Errorf("expected error for input %q", input)
+		}
+	}
+}
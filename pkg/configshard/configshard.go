@@ -0,0 +1,183 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configshard splits configuration files that exceed the size limit of a single
+// Kubernetes ConfigMap across multiple ConfigMaps and reassembles them.
+//
+// Shard ConfigMaps are immutable and named after a hash of their content. A manifest, stored in
+// a ConfigMap with a fixed name, references the shards that make up each file. Since shards are
+// never modified, reading the manifest and then the shards it references always yields a
+// consistent set of files, even while the manifest is being replaced.
+package configshard
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ManifestKey is the ConfigMap key under which a manifest is stored.
+const ManifestKey = "shards-manifest.json"
+
+// Manifest describes how files are split across shard ConfigMaps.
+type Manifest struct {
+	// Namespace in which the shard ConfigMaps reside.
+	Namespace string `json:"namespace"`
+	// Files are the files that are reassembled from shards.
+	Files []File `json:"files"`
+}
+
+// File is a file whose content is the concatenation of its chunks.
+type File struct {
+	Name string `json:"name"`
+	// SHA256 is the hex-encoded checksum of the full file content.
+	SHA256 string  `json:"sha256"`
+	Chunks []Chunk `json:"chunks"`
+}
+
+// Chunk references a part of a file in a shard ConfigMap.
+type Chunk struct {
+	ConfigMap string `json:"configMap"`
+	Key       string `json:"key"`
+}
+
+// ConfigMaps returns the names of all shard ConfigMaps referenced by the manifest.
+func (m *Manifest) ConfigMaps() []string {
+	var names []string
+	for _, f := range m.Files {
+		for _, c := range f.Chunks {
+			names = append(names, c.ConfigMap)
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// ParseManifest decodes a manifest.
+func ParseManifest(b []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("decode shard manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// Split distributes files across shards whose data size does not exceed maxSize. Files are
+// packed in name order and files larger than maxSize are split into multiple chunks.
+// Shards are named after the given prefix and a hash of their content.
+//
+// It returns the manifest and the data of each shard by shard name.
+func Split(namespace, prefix string, files map[string][]byte, maxSize int) (*Manifest, map[string]map[string][]byte, error) {
+	if maxSize <= 0 {
+		return nil, nil, fmt.Errorf("invalid shard size %d", maxSize)
+	}
+	type chunk struct {
+		file, key string
+		data      []byte
+	}
+	var (
+		shards  [][]chunk
+		current []chunk
+		size    int
+	)
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		data := files[name]
+		for i := 0; i == 0 || len(data) > 0; i++ {
+			key := fmt.Sprintf("%s.%d", name, i)
+			// Start a new shard if not even a single byte of the file fits anymore.
+			need := len(key) + min(len(data), 1)
+			if size+need > maxSize && len(current) > 0 {
+				shards = append(shards, current)
+				current, size = nil, 0
+			}
+			if size+need > maxSize {
+				return nil, nil, fmt.Errorf("shard size %d too small for key %q", maxSize, key)
+			}
+			n := min(len(data), maxSize-size-len(key))
+			current = append(current, chunk{file: name, key: key, data: data[:n]})
+			size += len(key) + n
+			data = data[n:]
+		}
+	}
+	if len(current) > 0 {
+		shards = append(shards, current)
+	}
+
+	m := &Manifest{Namespace: namespace}
+	result := map[string]map[string][]byte{}
+	for _, chunks := range shards {
+		h := sha256.New()
+		shard := map[string][]byte{}
+		for _, c := range chunks {
+			// Length-prefix keys so that different splits can't produce the same hash.
+			fmt.Fprintf(h, "%d:%s%d:", len(c.key), c.key, len(c.data))
+			h.Write(c.data)
+			shard[c.key] = c.data
+		}
+		name := fmt.Sprintf("%s-%s", prefix, hex.EncodeToString(h.Sum(nil))[:10])
+		result[name] = shard
+
+		for _, c := range chunks {
+			if len(m.Files) == 0 || m.Files[len(m.Files)-1].Name != c.file {
+				sum := sha256.Sum256(files[c.file])
+				m.Files = append(m.Files, File{Name: c.file, SHA256: hex.EncodeToString(sum[:])})
+			}
+			f := &m.Files[len(m.Files)-1]
+			f.Chunks = append(f.Chunks, Chunk{ConfigMap: name, Key: c.key})
+		}
+	}
+	return m, result, nil
+}
+
+// ConfigMapGetter retrieves a ConfigMap.
+type ConfigMapGetter func(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error)
+
+// Assemble fetches the shards referenced by the manifest and returns the content of all files
+// by name. It fails if any file doesn't match its checksum.
+func Assemble(ctx context.Context, m *Manifest, get ConfigMapGetter) (map[string][]byte, error) {
+	shards := map[string]*corev1.ConfigMap{}
+	for _, name := range m.ConfigMaps() {
+		cm, err := get(ctx, m.Namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("get shard %s/%s: %w", m.Namespace, name, err)
+		}
+		shards[name] = cm
+	}
+
+	files := map[string][]byte{}
+	for _, f := range m.Files {
+		var data []byte
+		for _, c := range f.Chunks {
+			cm := shards[c.ConfigMap]
+			if b, ok := cm.BinaryData[c.Key]; ok {
+				data = append(data, b...)
+			} else if s, ok := cm.Data[c.Key]; ok {
+				data = append(data, s...)
+			} else {
+				return nil, fmt.Errorf("key %q missing in shard %s/%s", c.Key, m.Namespace, c.ConfigMap)
+			}
+		}
+		if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != f.SHA256 {
+			return nil, fmt.Errorf("checksum mismatch for file %q", f.Name)
+		}
+		files[f.Name] = data
+	}
+	return files, nil
+}
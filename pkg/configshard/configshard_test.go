@@ -0,0 +1,179 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configshard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func getter(shards map[string]map[string][]byte) ConfigMapGetter {
+	return func(_ context.Context, _, name string) (*corev1.ConfigMap, error) {
+		data, ok := shards[name]
+		if !ok {
+			return nil, fmt.Errorf("not found: %s", name)
+		}
+		return &corev1.ConfigMap{BinaryData: data}, nil
+	}
+}
+
+func TestSplitAssemble(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string][]byte
+		maxSize    int
+		wantShards int
+	}{
+		{
+			name:       "single shard",
+			files:      map[string][]byte{"a.yaml": []byte("foo"), "b.yaml": []byte("bar"), "empty.yaml": nil},
+			maxSize:    100,
+			wantShards: 1,
+		},
+		{
+			name:       "large file",
+			files:      map[string][]byte{"config.yaml": []byte(strings.Repeat("x", 1000))},
+			maxSize:    100,
+			wantShards: 12,
+		},
+		{
+			name: "many files",
+			files: map[string][]byte{
+				"a.yaml": []byte(strings.Repeat("a", 40)),
+				"b.yaml": []byte(strings.Repeat("b", 40)),
+				"c.yaml": []byte(strings.Repeat("c", 40)),
+			},
+			maxSize:    100,
+			wantShards: 2,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m, shards, err := Split("ns", "collector", tc.files, tc.maxSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(shards) != tc.wantShards {
+				t.Errorf("expected %d shards, got %d", tc.wantShards, len(shards))
+			}
+			for name, data := range shards {
+				if !strings.HasPrefix(name, "collector-") {
+					t.Errorf("unexpected shard name %q", name)
+				}
+				size := 0
+				for k, v := range data {
+					size += len(k) + len(v)
+				}
+				if size > tc.maxSize {
+					t.Errorf("shard %q exceeds size: %d > %d", name, size, tc.maxSize)
+				}
+			}
+			if got := len(m.ConfigMaps()); got != len(shards) {
+				t.Errorf("expected manifest to reference %d shards, got %d", len(shards), got)
+			}
+
+			// The manifest must survive encoding.
+			b, err := json.Marshal(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, err = ParseManifest(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Assemble(t.Context(), m, getter(shards))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.files, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("unexpected files (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestSplitContentAddressed(t *testing.T) {
+	files := map[string][]byte{
+		"a.yaml": []byte(strings.Repeat("a", 60)),
+		"b.yaml": []byte(strings.Repeat("b", 60)),
+	}
+	_, first, err := Split("ns", "rules", files, 70)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, second, err := Split("ns", "rules", files, 70)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(first, second); diff != "" {
+		t.Fatalf("expected identical shards for identical input (-first, +second): %s", diff)
+	}
+
+	// Changing one file must only change the shards holding it.
+	files["b.yaml"] = []byte(strings.Repeat("B", 60))
+	_, third, err := Split("ns", "rules", files, 70)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var unchanged int
+	for name := range third {
+		if _, ok := first[name]; ok {
+			unchanged++
+		}
+	}
+	if unchanged == 0 || unchanged == len(third) {
+		t.Errorf("expected some but not all shards to be unchanged, got %d of %d", unchanged, len(third))
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	files := map[string][]byte{"config.yaml": []byte(strings.Repeat("x", 300))}
+	m, shards, err := Split("ns", "collector", files, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("missing shard", func(t *testing.T) {
+		missing := map[string]map[string][]byte{}
+		if _, err := Assemble(t.Context(), m, getter(missing)); err == nil {
+			t.Error("expected error")
+		}
+	})
+	t.Run("checksum mismatch", func(t *testing.T) {
+		corrupt := map[string]map[string][]byte{}
+		for name, data := range shards {
+			corrupt[name] = map[string][]byte{}
+			for k, v := range data {
+				corrupt[name][k] = []byte(strings.Repeat("y", len(v)))
+			}
+		}
+		if _, err := Assemble(t.Context(), m, getter(corrupt)); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestSplitTooSmall(t *testing.T) {
+	if _, _, err := Split("ns", "collector", map[string][]byte{"config.yaml": []byte("x")}, 5); err == nil {
+		t.Error("expected error")
+	}
+}
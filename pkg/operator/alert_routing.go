@@ -0,0 +1,353 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"text/template/parse"
+
+	"github.com/go-logr/logr"
+	alertmanagerconfig "github.com/prometheus/alertmanager/config"
+	alertmanagertemplate "github.com/prometheus/alertmanager/template"
+	yaml "gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+const (
+	// alertmanagerTemplatesDir is where the Alertmanager container mounts its config Secret,
+	// which holds the templates of AlertRoutings.
+	alertmanagerTemplatesDir = "/alertmanager/templates"
+
+	reasonAlertRoutingInvalid = "AlertRoutingInvalid"
+	reasonConfigInvalidBase   = "AlertmanagerConfigInvalid"
+)
+
+// applyAlertRoutings merges the AlertRoutings in allowed namespaces into the Alertmanager
+// config of the Secret and updates their status. AlertRoutings that would make the config
+// invalid are skipped.
+func (r *operatorConfigReconciler) applyAlertRoutings(ctx context.Context, secret *corev1.Secret, namespaces *monitoringv1.NamespaceFilter) error {
+	logger, _ := logr.FromContext(ctx)
+
+	var routings monitoringv1.AlertRoutingList
+	if err := r.client.List(ctx, &routings); err != nil {
+		return fmt.Errorf("list alert routings: %w", err)
+	}
+	if len(routings.Items) == 0 {
+		return nil
+	}
+	// Merge in a stable order so that the config only changes with the AlertRoutings.
+	slices.SortFunc(routings.Items, func(a, b monitoringv1.AlertRouting) int {
+		return strings.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	})
+	nsFilter, err := newNamespaceFilter(r.client, namespaces)
+	if err != nil {
+		return err
+	}
+
+	var config map[string]any
+	if err := yaml.Unmarshal(secret.Data[AlertmanagerConfigKey], &config); err != nil {
+		return fmt.Errorf("load alertmanager config: %w", err)
+	}
+	baseErr := validateAlertmanagerConfig(config)
+
+	now := metav1.Now()
+	var (
+		merged        int
+		statusUpdates []monitoringv1.MonitoringCRD
+	)
+	for i := range routings.Items {
+		ar := &routings.Items[i]
+		cond := &monitoringv1.MonitoringCondition{
+			Type:   monitoringv1.ConfigurationCreateSuccess,
+			Status: corev1.ConditionTrue,
+		}
+		if ok, err := nsFilter.allowed(ctx, ar.Namespace); err != nil {
+			return err
+		} else if !ok {
+			cond = namespaceIgnoredCondition(ar.Namespace)
+		} else if baseErr != nil {
+			cond.Status = corev1.ConditionFalse
+			cond.Reason = reasonConfigInvalidBase
+			cond.Message = fmt.Sprintf("the Alertmanager configuration of the OperatorConfig is invalid: %s", baseErr)
+		} else if result, templates, err := mergeAlertRouting(config, ar, merged); err != nil {
+			cond.Status = corev1.ConditionFalse
+			cond.Reason = reasonAlertRoutingInvalid
+			cond.Message = err.Error()
+			logger.Error(err, "merge alert routing", "namespace", ar.Namespace, "name", ar.Name)
+		} else {
+			config = result
+			merged++
+			for key, tmpl := range templates {
+				secret.Data[key] = tmpl
+			}
+		}
+		if ar.Status.SetMonitoringCondition(ar.GetGeneration(), now, cond) {
+			statusUpdates = append(statusUpdates, ar)
+		}
+	}
+	if merged > 0 {
+		b, err := yaml.Marshal(config)
+		if err != nil {
+			return fmt.Errorf("marshal alertmanager config: %w", err)
+		}
+		secret.Data[AlertmanagerConfigKey] = b
+	}
+
+	var errs []error
+	for _, obj := range statusUpdates {
+		if err := patchMonitoringStatus(ctx, r.client, obj, obj.GetMonitoringStatus()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// mergeAlertRouting returns a copy of the Alertmanager config with the AlertRouting merged
+// into it, along with the Secret data of its templates. The route of the AlertRouting is
+// inserted at the given position of the child routes of the root route and always continues
+// to the following routes.
+func mergeAlertRouting(config map[string]any, ar *monitoringv1.AlertRouting, pos int) (map[string]any, map[string][]byte, error) {
+	res, err := copyAlertmanagerConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	prefix := fmt.Sprintf("%s/%s/", ar.Namespace, ar.Name)
+	nsMatcher := fmt.Sprintf("namespace=%q", ar.Namespace)
+
+	var route map[string]any
+	if err := json.Unmarshal(ar.Spec.Route.Raw, &route); err != nil {
+		return nil, nil, fmt.Errorf("invalid route: %w", err)
+	}
+	if err := prefixReceivers(route, prefix); err != nil {
+		return nil, nil, err
+	}
+	if err := addMatcher(route, "matchers", nsMatcher); err != nil {
+		return nil, nil, err
+	}
+	// Alerts of the namespace must still reach the routes of the Alertmanager configuration,
+	// such as a catch-all of the platform admin.
+	route["continue"] = true
+	root, ok := res["route"].(map[string]any)
+	if !ok {
+		return nil, nil, errors.New("the Alertmanager configuration has no root route")
+	}
+	routes, _ := root["routes"].([]any)
+	root["routes"] = slices.Insert(routes, min(pos, len(routes)), any(route))
+
+	receivers, _ := res["receivers"].([]any)
+	for i, raw := range ar.Spec.Receivers {
+		var recv map[string]any
+		if err := json.Unmarshal(raw.Raw, &recv); err != nil {
+			return nil, nil, fmt.Errorf("invalid receiver %d: %w", i, err)
+		}
+		name, _ := recv["name"].(string)
+		if name == "" {
+			return nil, nil, fmt.Errorf("receiver %d has no name", i)
+		}
+		// Files of the Alertmanager, such as its configuration with the secrets of all
+		// receivers, must not be readable through the receivers of a namespace.
+		if err := checkNoFileFields(recv, "receiver "+name); err != nil {
+			return nil, nil, err
+		}
+		recv["name"] = prefix + name
+		receivers = append(receivers, recv)
+	}
+	res["receivers"] = receivers
+
+	if len(ar.Spec.InhibitRules) > 0 {
+		inhibitRules, _ := res["inhibit_rules"].([]any)
+		for i, raw := range ar.Spec.InhibitRules {
+			var rule map[string]any
+			if err := json.Unmarshal(raw.Raw, &rule); err != nil {
+				return nil, nil, fmt.Errorf("invalid inhibit rule %d: %w", i, err)
+			}
+			for _, key := range []string{"source_matchers", "target_matchers"} {
+				if err := addMatcher(rule, key, nsMatcher); err != nil {
+					return nil, nil, fmt.Errorf("inhibit rule %d: %w", i, err)
+				}
+			}
+			inhibitRules = append(inhibitRules, rule)
+		}
+		res["inhibit_rules"] = inhibitRules
+	}
+
+	templates := map[string][]byte{}
+	if len(ar.Spec.Templates) > 0 {
+		paths, _ := res["templates"].([]any)
+		namePrefix := fmt.Sprintf("%s_%s_", ar.Namespace, ar.Name)
+		for _, file := range slices.Sorted(maps.Keys(ar.Spec.Templates)) {
+			key := namePrefix + file
+			if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+				return nil, nil, fmt.Errorf("invalid template file name %q: %s", file, strings.Join(errs, ", "))
+			}
+			filePath := path.Join(alertmanagerTemplatesDir, key)
+			// Templates loaded by the Alertmanager configuration itself must not be replaced
+			// by those of a namespace.
+			for _, p := range paths {
+				if pattern, _ := p.(string); pattern != "" {
+					if ok, _ := path.Match(pattern, filePath); ok {
+						return nil, nil, fmt.Errorf("template %q is loaded by the templates %q of the Alertmanager configuration", file, pattern)
+					}
+				}
+			}
+			tmpl, err := alertmanagertemplate.New()
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := tmpl.Parse(strings.NewReader(ar.Spec.Templates[file])); err != nil {
+				return nil, nil, fmt.Errorf("invalid template %q: %w", file, err)
+			}
+			// Template names are shared by the whole Alertmanager configuration. Requiring the
+			// prefix keeps the templates of namespaces from replacing each other's or the
+			// default ones, as namespaces and names cannot contain underscores.
+			names, err := definedTemplates(key, ar.Spec.Templates[file])
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid template %q: %w", file, err)
+			}
+			for _, name := range names {
+				if !strings.HasPrefix(name, namePrefix) {
+					return nil, nil, fmt.Errorf("template %q defines %q, which does not start with %q", file, name, namePrefix)
+				}
+			}
+			templates[key] = []byte(ar.Spec.Templates[file])
+			paths = append(paths, filePath)
+		}
+		res["templates"] = paths
+	}
+
+	if err := validateAlertmanagerConfig(res); err != nil {
+		return nil, nil, err
+	}
+	return res, templates, nil
+}
+
+// definedTemplates returns the names of the templates defined in the text of a template
+// file with the given name.
+func definedTemplates(name, text string) ([]string, error) {
+	tree := parse.New(name)
+	tree.Mode = parse.SkipFuncCheck
+	trees := map[string]*parse.Tree{}
+	if _, err := tree.Parse(text, "", "", trees); err != nil {
+		return nil, err
+	}
+	delete(trees, name)
+	return slices.Sorted(maps.Keys(trees)), nil
+}
+
+// prefixReceivers prefixes the receivers of the route and its child routes.
+func prefixReceivers(route map[string]any, prefix string) error {
+	if recv, ok := route["receiver"]; ok {
+		name, ok := recv.(string)
+		if !ok {
+			return fmt.Errorf("invalid receiver %v", recv)
+		}
+		route["receiver"] = prefix + name
+	}
+	routes, ok := route["routes"]
+	if !ok {
+		return nil
+	}
+	children, ok := routes.([]any)
+	if !ok {
+		return fmt.Errorf("invalid routes %v", routes)
+	}
+	for _, child := range children {
+		childRoute, ok := child.(map[string]any)
+		if !ok {
+			return fmt.Errorf("invalid route %v", child)
+		}
+		if err := prefixReceivers(childRoute, prefix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkNoFileFields returns an error if the value has a field referring to a file, such as
+// credentials_file or password_file, at any level.
+func checkNoFileFields(v any, path string) error {
+	switch v := v.(type) {
+	case map[string]any:
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			if strings.HasSuffix(key, "_file") {
+				return fmt.Errorf("%s: field %s is not allowed", path, key)
+			}
+			if err := checkNoFileFields(v[key], path+"."+key); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, elem := range v {
+			if err := checkNoFileFields(elem, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addMatcher prepends the matcher to the list of matchers with the given key.
+func addMatcher(obj map[string]any, key, matcher string) error {
+	matchers := []any{matcher}
+	if m, ok := obj[key]; ok {
+		list, ok := m.([]any)
+		if !ok {
+			return fmt.Errorf("invalid %s %v", key, m)
+		}
+		matchers = append(matchers, list...)
+	}
+	obj[key] = matchers
+	return nil
+}
+
+// validateAlertmanagerConfig validates the config with the upstream Alertmanager loader.
+func validateAlertmanagerConfig(config map[string]any) error {
+	upstream := make(map[string]any, len(config))
+	for k, v := range config {
+		// Configuration of our fork, which the upstream loader does not know.
+		if k != "google_cloud" {
+			upstream[k] = v
+		}
+	}
+	b, err := yaml.Marshal(upstream)
+	if err != nil {
+		return err
+	}
+	_, err = alertmanagerconfig.Load(string(b))
+	return err
+}
+
+func copyAlertmanagerConfig(config map[string]any) (map[string]any, error) {
+	b, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var res map[string]any
+	if err := yaml.Unmarshal(b, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,226 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"slices"
+	"testing"
+
+	alertmanagerconfig "github.com/prometheus/alertmanager/config"
+	amlabels "github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+func TestAlertRouting(t *testing.T) {
+	opts := Options{
+		OperatorNamespace: "gmp-system",
+		PublicNamespace:   "gmp-public",
+	}
+	raw := func(s string) apiextensionsv1.JSON {
+		return apiextensionsv1.JSON{Raw: []byte(s)}
+	}
+	valid := &monitoringv1.AlertRouting{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "routing", Generation: 1},
+		Spec: monitoringv1.AlertRoutingSpec{
+			Route: raw(`{"receiver": "pager", "group_by": ["alertname"], "continue": false, "routes": [{"receiver": "chat", "matchers": ["severity=\"info\""]}]}`),
+			Receivers: []apiextensionsv1.JSON{
+				raw(`{"name": "pager", "webhook_configs": [{"url": "http://pager.example.com"}]}`),
+				raw(`{"name": "chat", "webhook_configs": [{"url": "http://chat.example.com"}]}`),
+			},
+			InhibitRules: []apiextensionsv1.JSON{
+				raw(`{"source_matchers": ["severity=\"critical\""], "target_matchers": ["severity=\"warning\""], "equal": ["alertname"]}`),
+			},
+			Templates: map[string]string{
+				"team.tmpl": `{{ define "team-a_routing_title" }}{{ .CommonLabels.alertname | toUpper }}{{ end }}`,
+			},
+		},
+	}
+	invalid := &monitoringv1.AlertRouting{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "routing", Generation: 1},
+		Spec: monitoringv1.AlertRoutingSpec{
+			Route: raw(`{"receiver": "missing"}`),
+		},
+	}
+	// Receivers must not read files of the Alertmanager, such as its configuration.
+	fileRef := &monitoringv1.AlertRouting{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-c", Name: "routing", Generation: 1},
+		Spec: monitoringv1.AlertRoutingSpec{
+			Route: raw(`{"receiver": "hook"}`),
+			Receivers: []apiextensionsv1.JSON{
+				raw(`{"name": "hook", "webhook_configs": [{"url": "http://hook.example.com", "http_config": {"authorization": {"credentials_file": "/etc/alertmanager/config_out/config.yaml"}}}]}`),
+			},
+		},
+	}
+	// Template names are shared by all namespaces and must not replace those of others.
+	sameTemplate := &monitoringv1.AlertRouting{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-d", Name: "routing", Generation: 1},
+		Spec: monitoringv1.AlertRoutingSpec{
+			Route: raw(`{"receiver": "hook"}`),
+			Receivers: []apiextensionsv1.JSON{
+				raw(`{"name": "hook", "webhook_configs": [{"url": "http://hook.example.com"}]}`),
+			},
+			Templates: map[string]string{
+				"team.tmpl": `{{ define "team-a_routing_title" }}{{ .CommonLabels.alertname }}{{ end }}`,
+			},
+		},
+	}
+	c := newFakeClientBuilder().WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: opts.PublicNamespace, Name: AlertmanagerPublicSecretName},
+			Data: map[string][]byte{AlertmanagerPublicSecretKey: []byte(`
+route:
+  receiver: default
+  routes:
+  - receiver: oncall
+    matchers: ['severity="critical"']
+receivers:
+- name: default
+- name: oncall
+`)},
+		},
+		valid,
+		invalid,
+		fileRef,
+		sameTemplate,
+	).Build()
+
+	r := newOperatorConfigReconciler(c, opts)
+	if err := r.ensureAlertmanagerConfigSecret(t.Context(), &monitoringv1.ManagedAlertmanagerSpec{}, &monitoringv1.NamespaceFilter{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var secret corev1.Secret
+	if err := c.Get(t.Context(), client.ObjectKey{Namespace: opts.OperatorNamespace, Name: AlertmanagerSecretName}, &secret); err != nil {
+		t.Fatal(err)
+	}
+	config, err := alertmanagerconfig.Load(string(secret.Data[AlertmanagerConfigKey]))
+	if err != nil {
+		t.Fatalf("load merged config: %s\n%s", err, secret.Data[AlertmanagerConfigKey])
+	}
+	if len(config.Route.Routes) != 2 {
+		t.Fatalf("expected two child routes, got %d", len(config.Route.Routes))
+	}
+	route := config.Route.Routes[0]
+	if route.Receiver != "team-a/routing/pager" || route.Routes[0].Receiver != "team-a/routing/chat" {
+		t.Errorf("unexpected receivers %q, %q", route.Receiver, route.Routes[0].Receiver)
+	}
+	if got := amlabels.Matchers(route.Matchers).String(); got != `{namespace="team-a"}` {
+		t.Errorf("expected route to match the namespace, got %s", got)
+	}
+	// Alerts of the namespace still reach the routes of the base config.
+	var matched []string
+	for _, r := range config.Route.Routes {
+		if !amlabels.Matchers(r.Matchers).Matches(model.LabelSet{"namespace": "team-a", "severity": "critical"}) {
+			continue
+		}
+		matched = append(matched, r.Receiver)
+		if !r.Continue {
+			break
+		}
+	}
+	if want := []string{"team-a/routing/pager", "oncall"}; !slices.Equal(matched, want) {
+		t.Errorf("expected alert to be routed to %v, got %v", want, matched)
+	}
+	var receivers []string
+	for _, recv := range config.Receivers {
+		receivers = append(receivers, recv.Name)
+	}
+	if want := []string{"default", "oncall", "team-a/routing/pager", "team-a/routing/chat"}; !slices.Equal(receivers, want) {
+		t.Errorf("expected receivers %v, got %v", want, receivers)
+	}
+	if len(config.InhibitRules) != 1 {
+		t.Fatalf("expected a single inhibit rule, got %d", len(config.InhibitRules))
+	}
+	if got := amlabels.Matchers(config.InhibitRules[0].SourceMatchers).String(); got != `{namespace="team-a",severity="critical"}` {
+		t.Errorf("unexpected source matchers %s", got)
+	}
+	if got := amlabels.Matchers(config.InhibitRules[0].TargetMatchers).String(); got != `{namespace="team-a",severity="warning"}` {
+		t.Errorf("unexpected target matchers %s", got)
+	}
+	if want := []string{alertmanagerTemplatesDir + "/team-a_routing_team.tmpl"}; !slices.Equal(config.Templates, want) {
+		t.Errorf("expected templates %v, got %v", want, config.Templates)
+	}
+	if _, ok := secret.Data["team-a_routing_team.tmpl"]; !ok {
+		t.Errorf("template missing in secret")
+	}
+
+	for _, tc := range []struct {
+		obj     *monitoringv1.AlertRouting
+		status  corev1.ConditionStatus
+		reason  string
+		message string
+	}{
+		{obj: valid, status: corev1.ConditionTrue},
+		{obj: invalid, status: corev1.ConditionFalse, reason: reasonAlertRoutingInvalid},
+		{
+			obj:     fileRef,
+			status:  corev1.ConditionFalse,
+			reason:  reasonAlertRoutingInvalid,
+			message: "receiver hook.webhook_configs[0].http_config.authorization: field credentials_file is not allowed",
+		},
+		{
+			obj:     sameTemplate,
+			status:  corev1.ConditionFalse,
+			reason:  reasonAlertRoutingInvalid,
+			message: `template "team.tmpl" defines "team-a_routing_title", which does not start with "team-d_routing_"`,
+		},
+	} {
+		var got monitoringv1.AlertRouting
+		if err := c.Get(t.Context(), client.ObjectKeyFromObject(tc.obj), &got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Status.Conditions) != 1 {
+			t.Fatalf("%s: expected a single condition, got %v", tc.obj.Namespace, got.Status.Conditions)
+		}
+		cond := got.Status.Conditions[0]
+		if cond.Status != tc.status || cond.Reason != tc.reason {
+			t.Errorf("%s: expected %s/%s, got %s/%s: %s", tc.obj.Namespace, tc.status, tc.reason, cond.Status, cond.Reason, cond.Message)
+		}
+		if tc.message != "" && cond.Message != tc.message {
+			t.Errorf("%s: expected message %q, got %q", tc.obj.Namespace, tc.message, cond.Message)
+		}
+	}
+}
+
+func TestMergeAlertRoutingBaseTemplates(t *testing.T) {
+	config := map[string]any{
+		"route":     map[string]any{"receiver": "default"},
+		"receivers": []any{map[string]any{"name": "default"}},
+		"templates": []any{alertmanagerTemplatesDir + "/*.tmpl"},
+	}
+	ar := &monitoringv1.AlertRouting{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "routing"},
+		Spec: monitoringv1.AlertRoutingSpec{
+			Route: apiextensionsv1.JSON{Raw: []byte(`{"receiver": "hook"}`)},
+			Receivers: []apiextensionsv1.JSON{
+				{Raw: []byte(`{"name": "hook", "webhook_configs": [{"url": "http://hook.example.com"}]}`)},
+			},
+			Templates: map[string]string{
+				"team.tmpl": `{{ define "team-a_routing_title" }}{{ .CommonLabels.alertname }}{{ end }}`,
+			},
+		},
+	}
+	// The templates of the base config would load the file of the AlertRouting.
+	_, _, err := mergeAlertRouting(config, ar, 0)
+	if want := `template "team.tmpl" is loaded by the templates "/alertmanager/templates/*.tmpl" of the Alertmanager configuration`; err == nil || err.Error() != want {
+		t.Fatalf("expected error %q, got %v", want, err)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AlertRouting defines how the managed Alertmanager routes alerts of its namespace. Its
+// routes, receivers, inhibit rules and templates are merged into the Alertmanager
+// configuration of the OperatorConfig. The route and inhibit rules only match alerts with
+// the namespace label of the resource.
+//
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+type AlertRouting struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of the routing of alerts in the namespace.
+	Spec AlertRoutingSpec `json:"spec"`
+	// Most recently observed status of the resource.
+	// +optional
+	Status AlertRoutingStatus `json:"status"`
+}
+
+func (r *AlertRouting) GetMonitoringStatus() *MonitoringStatus {
+	return &r.Status.MonitoringStatus
+}
+
+// AlertRoutingList is a list of AlertRoutings.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type AlertRoutingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []AlertRouting `json:"items"`
+}
+
+// AlertRoutingSpec contains the Alertmanager configuration of a namespace. All fields use
+// the format of the Alertmanager configuration file.
+type AlertRoutingSpec struct {
+	// The route for alerts of the namespace. It is added with a matcher on the namespace
+	// label as a child of the root route, ahead of the routes of the Alertmanager
+	// configuration. Its `continue` is always set, so that the alerts still reach the
+	// routes of the Alertmanager configuration. Receivers refer to the receivers of this
+	// resource.
+	// +kubebuilder:validation:Type=object
+	Route apiextensionsv1.JSON `json:"route"`
+	// Receivers of the route and its child routes. Fields that read files of the
+	// Alertmanager, such as `credentials_file`, are not allowed.
+	// +optional
+	Receivers []apiextensionsv1.JSON `json:"receivers,omitempty"`
+	// Inhibition rules. Their source and target matchers are restricted to the namespace.
+	// +optional
+	InhibitRules []apiextensionsv1.JSON `json:"inhibitRules,omitempty"`
+	// Notification templates keyed by file name. Template names are shared by the whole
+	// Alertmanager configuration, so the names of all defined templates must start with
+	// "<namespace>_<name>_" of the AlertRouting.
+	// +optional
+	Templates map[string]string `json:"templates,omitempty"`
+}
+
+// AlertRoutingStatus contains the status of an AlertRouting.
+type AlertRoutingStatus struct {
+	MonitoringStatus `json:",inline"`
+}
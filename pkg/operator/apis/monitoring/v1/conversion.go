@@ -0,0 +1,35 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+// v1 is the hub version all other versions of the API are converted through.
+
+// Hub marks PodMonitoring as a conversion hub.
+func (*PodMonitoring) Hub() {}
+
+// Hub marks ClusterPodMonitoring as a conversion hub.
+func (*ClusterPodMonitoring) Hub() {}
+
+// Hub marks Rules as a conversion hub.
+func (*Rules) Hub() {}
+
+// Hub marks ClusterRules as a conversion hub.
+func (*ClusterRules) Hub() {}
+
+// Hub marks GlobalRules as a conversion hub.
+func (*GlobalRules) Hub() {}
+
+// Hub marks OperatorConfig as a conversion hub.
+func (*OperatorConfig) Hub() {}
@@ -15,6 +15,9 @@
 package v1
 
 import (
+	"cmp"
+	"slices"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -83,6 +86,7 @@ type MonitoringStatus struct {
 
 // SetMonitoringCondition merges the provided valid condition if the resource generation changed or
 // there is a status condition state transition.
+// Conditions are sorted by type so that statuses with several condition types compare equal.
 func (status *MonitoringStatus) SetMonitoringCondition(gen int64, now metav1.Time, cond *MonitoringCondition) bool {
 	var (
 		specChanged              = status.ObservedGeneration != gen
@@ -107,7 +111,7 @@ func (status *MonitoringStatus) SetMonitoringCondition(gen int64, now metav1.Tim
 	cond.LastUpdateTime = now
 
 	// Check if the condition results in a transition of status state.
-	if old := conds[cond.Type]; old.Status == cond.Status {
+	if old, ok := conds[cond.Type]; ok && old.Status == cond.Status {
 		cond.LastTransitionTime = old.LastTransitionTime
 	} else {
 		cond.LastTransitionTime = cond.LastUpdateTime
@@ -126,6 +130,9 @@ func (status *MonitoringStatus) SetMonitoringCondition(gen int64, now metav1.Tim
 		for _, c := range conds {
 			status.Conditions = append(status.Conditions, *c)
 		}
+		slices.SortFunc(status.Conditions, func(a, b MonitoringCondition) int {
+			return cmp.Compare(a.Type, b.Type)
+		})
 	}
 
 	return update
@@ -63,6 +63,8 @@ func (*rulesValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admi
 	return nil, nil
 }
 
+// NewRulesValidator returns a validator that checks whether the rule files of Rules can be
+// generated. The webhook of the operator additionally checks query projects and lint checks.
 func NewRulesValidator() admission.CustomValidator {
 	return &rulesValidator{}
 }
@@ -81,6 +83,8 @@ func (*clusterRulesValidator) ValidateDelete(_ context.Context, _ runtime.Object
 	return nil, nil
 }
 
+// NewClusterRulesValidator returns a validator that checks whether the rule files of ClusterRules can be
+// generated. The webhook of the operator additionally checks query projects and lint checks.
 func NewClusterRulesValidator() admission.CustomValidator {
 	return &clusterRulesValidator{}
 }
@@ -99,6 +103,8 @@ func (*globalRulesValidator) ValidateDelete(_ context.Context, _ runtime.Object)
 	return nil, nil
 }
 
+// NewGlobalRulesValidator returns a validator that checks whether the rule files of GlobalRules can be
+// generated. The webhook of the operator additionally checks query projects and lint checks.
 func NewGlobalRulesValidator() admission.CustomValidator {
 	return &globalRulesValidator{}
 }
@@ -16,6 +16,7 @@ package v1
 
 import (
 	"fmt"
+	"net/url"
 
 	"github.com/prometheus/common/config"
 	prommodel "github.com/prometheus/common/model"
@@ -123,3 +124,48 @@ func (c *CollectionSpec) ScrapeConfigs() ([]*promconfig.ScrapeConfig, error) {
 		},
 	}, nil
 }
+
+// StaticURLsBase returns the common scheme and path of the static URLs along with their
+// host addresses.
+func (e *AlertmanagerEndpoints) StaticURLsBase() (*url.URL, []string, error) {
+	var (
+		base  *url.URL
+		hosts []string
+	)
+	for _, s := range e.StaticURLs {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid static URL %q: %w", s, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, nil, fmt.Errorf("invalid static URL %q: scheme must be http or https", s)
+		}
+		if u.Host == "" {
+			return nil, nil, fmt.Errorf("invalid static URL %q: missing host", s)
+		}
+		if u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+			return nil, nil, fmt.Errorf("invalid static URL %q: only scheme, host and path are allowed", s)
+		}
+		if base == nil {
+			base = &url.URL{Scheme: u.Scheme, Path: u.Path}
+		} else if u.Scheme != base.Scheme || u.Path != base.Path {
+			return nil, nil, fmt.Errorf("static URL %q must have the same scheme and path as %q", s, e.StaticURLs[0])
+		}
+		hosts = append(hosts, u.Host)
+	}
+	return base, hosts, nil
+}
+
+// AlertRelabelingConfigs returns the relabel configurations applied to alerts sent to
+// these Alertmanagers.
+func (e *AlertmanagerEndpoints) AlertRelabelingConfigs() ([]*relabel.Config, error) {
+	var res []*relabel.Config
+	for i, r := range e.AlertRelabelConfigs {
+		rcfg, err := convertRelabelingRule(r)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		res = append(res, rcfg)
+	}
+	return res, nil
+}
@@ -19,15 +19,19 @@ import (
 	"fmt"
 	"net/url"
 
+	prommodel "github.com/prometheus/common/model"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // OperatorConfig defines configuration of the gmp-operator.
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
 // +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Healthy",type=string,JSONPath=`.status.conditions[?(@.type=="Healthy")].status`
 type OperatorConfig struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -48,6 +52,52 @@ type OperatorConfig struct {
 	Features OperatorFeatures `json:"features,omitempty"`
 	// Scaling contains configuration options for scaling GMP.
 	Scaling ScalingSpec `json:"scaling,omitempty"`
+	// Namespaces restricts the namespaces in which PodMonitoring and Rules resources are
+	// considered, and in which ClusterPodMonitoring resources scrape pods and may reference
+	// secrets. By default, all namespaces are considered.
+	Namespaces NamespaceFilter `json:"namespaces,omitempty"`
+	// Workloads holds overrides that the operator applies to the workloads of the managed
+	// components.
+	Workloads WorkloadsSpec `json:"workloads,omitempty"`
+	// Status reports the health of the managed components.
+	// +optional
+	Status OperatorConfigStatus `json:"status,omitempty"`
+}
+
+// OperatorConfigConditionType is the type of a condition in the OperatorConfig status.
+type OperatorConfigConditionType string
+
+const (
+	// OperatorConfigHealthy is true if all other conditions are true.
+	OperatorConfigHealthy OperatorConfigConditionType = "Healthy"
+	// CollectorRolloutComplete indicates whether all collector pods run the current DaemonSet
+	// spec and are available.
+	CollectorRolloutComplete OperatorConfigConditionType = "CollectorRolloutComplete"
+	// CollectorConfigLoaded indicates whether all collectors loaded the current configuration.
+	CollectorConfigLoaded OperatorConfigConditionType = "CollectorConfigLoaded"
+	// ExportHealthy indicates whether collectors export samples without errors.
+	ExportHealthy OperatorConfigConditionType = "ExportHealthy"
+	// RuleEvaluatorConfigLoaded indicates whether the rule-evaluator reloaded its configuration
+	// successfully.
+	RuleEvaluatorConfigLoaded OperatorConfigConditionType = "RuleEvaluatorConfigLoaded"
+	// AlertmanagerConfigValid indicates whether the managed Alertmanager configuration is valid
+	// and was loaded.
+	AlertmanagerConfigValid OperatorConfigConditionType = "AlertmanagerConfigValid"
+	// WebhookCertificateValid indicates whether the certificate of the webhook server is valid
+	// and not about to expire.
+	WebhookCertificateValid OperatorConfigConditionType = "WebhookCertificateValid"
+)
+
+// OperatorConfigStatus reports the health of the managed components.
+type OperatorConfigStatus struct {
+	// The generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Represents the latest available observations of the health of the managed components.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 func (oc *OperatorConfig) Validate() error {
@@ -66,6 +116,9 @@ func (oc *OperatorConfig) Validate() error {
 	if err := validateRules(&oc.Rules); err != nil {
 		return fmt.Errorf("invalid rules config: %w", err)
 	}
+	if _, err := oc.Namespaces.LabelSelector(); err != nil {
+		return fmt.Errorf("invalid namespaces selector: %w", err)
+	}
 	return nil
 }
 
@@ -88,6 +141,39 @@ func validateRules(rules *RuleEvaluatorSpec) error {
 }
 
 func validateAlertManagerEndpoint(alertManagerEndpoint *AlertmanagerEndpoints) error {
+	discoveries := 0
+	if alertManagerEndpoint.Name != "" {
+		discoveries++
+		if alertManagerEndpoint.Namespace == "" {
+			return errors.New("namespace must be set if name is set")
+		}
+	}
+	if len(alertManagerEndpoint.StaticURLs) > 0 {
+		discoveries++
+		if _, _, err := alertManagerEndpoint.StaticURLsBase(); err != nil {
+			return err
+		}
+	}
+	if dns := alertManagerEndpoint.DNS; dns != nil {
+		discoveries++
+		if len(dns.Names) == 0 {
+			return errors.New("missing DNS names")
+		}
+		if dns.Type != "" && dns.Type != "SRV" && dns.Port == 0 {
+			return fmt.Errorf("missing port for DNS record type %q", dns.Type)
+		}
+		if dns.RefreshInterval != "" {
+			if _, err := prommodel.ParseDuration(dns.RefreshInterval); err != nil {
+				return fmt.Errorf("invalid DNS refresh interval: %w", err)
+			}
+		}
+	}
+	if discoveries != 1 {
+		return errors.New("exactly one of name, staticURLs and dns must be set")
+	}
+	if _, err := alertManagerEndpoint.AlertRelabelingConfigs(); err != nil {
+		return fmt.Errorf("invalid alert relabeling: %w", err)
+	}
 	if alertManagerEndpoint.Authorization != nil {
 		if err := validateSecretKeySelector(alertManagerEndpoint.Authorization.Credentials); err != nil {
 			return fmt.Errorf("invalid authorization credentials: %w", err)
@@ -132,6 +218,65 @@ func validateSecretKeySelector(secretKeySelector *corev1.SecretKeySelector) erro
 	return nil
 }
 
+// NamespaceFilter selects the namespaces whose monitoring resources are considered by the
+// operator. A namespace is selected if it is allowed explicitly or matches the selector, unless
+// it is denied. If neither a selector nor allowed namespaces are set, all namespaces that are not
+// denied are selected.
+type NamespaceFilter struct {
+	// Selector selects namespaces by their labels.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Allow lists namespaces that are selected regardless of their labels.
+	Allow []string `json:"allow,omitempty"`
+	// Deny lists namespaces that are never selected. It takes precedence over the selector
+	// and allowed namespaces.
+	Deny []string `json:"deny,omitempty"`
+}
+
+// LabelSelector returns the parsed selector, or nil if no selector is set.
+func (f *NamespaceFilter) LabelSelector() (labels.Selector, error) {
+	if f.Selector == nil {
+		return nil, nil
+	}
+	return metav1.LabelSelectorAsSelector(f.Selector)
+}
+
+// WorkloadsSpec holds overrides of the workloads of the managed components.
+type WorkloadsSpec struct {
+	// Collector holds overrides of the collector DaemonSet.
+	Collector *WorkloadSpec `json:"collector,omitempty"`
+	// RuleEvaluator holds overrides of the rule-evaluator Deployment.
+	RuleEvaluator *WorkloadSpec `json:"ruleEvaluator,omitempty"`
+	// Alertmanager holds overrides of the managed Alertmanager StatefulSet.
+	Alertmanager *WorkloadSpec `json:"alertmanager,omitempty"`
+}
+
+// WorkloadSpec holds overrides of the pod template of a workload, which the operator applies
+// with server-side apply and keeps reconciled. Unset fields keep the values of the deployed
+// manifests. Removing an override keeps its last applied value until the manifests are
+// applied again, except for extra environment variables, which are removed.
+type WorkloadSpec struct {
+	// Resources of the main container of the component.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Tolerations of the pods. They replace the tolerations of the manifests.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Affinity of the pods, including node affinity.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// PriorityClassName of the pods.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// ExtraArgs are added to the arguments of the main container. Flags replace existing
+	// arguments of the same flag and must be passed as a single argument, e.g.
+	// "--log.level=debug".
+	// +optional
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+	// Env holds additional environment variables of the main container.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+}
+
 // OperatorConfigList is a list of OperatorConfigs.
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 type OperatorConfigList struct {
@@ -151,6 +296,11 @@ type RuleEvaluatorSpec struct {
 	// If left blank, the rule-evaluator will try attempt to infer the Project ID
 	// from the environment.
 	QueryProjectID string `json:"queryProjectID,omitempty"`
+	// AllowedQueryProjectIDs are the GCP project IDs that Rules, ClusterRules and GlobalRules
+	// may evaluate their rules against instead of queryProjectID. The rule-evaluator
+	// credentials need metric read permissions against them.
+	// +listType=set
+	AllowedQueryProjectIDs []string `json:"allowedQueryProjectIDs,omitempty"`
 	// The base URL used for the generator URL in the alert notification payload.
 	// Should point to an instance of a query frontend that gives access to queryProjectID.
 	// +kubebuilder:validation:Format=uri
@@ -166,6 +316,14 @@ type RuleEvaluatorSpec struct {
 	// service account has the required permissions.
 	// +kubebuilder:validation:XValidation:rule="has(self.name) && self.name != ''",message="missing secret key selector name"
 	Credentials *corev1.SecretKeySelector `json:"credentials,omitempty"`
+	// Shards is the number of shards the rule groups of all Rules, ClusterRules and
+	// GlobalRules are split across. Each shard is evaluated by a separate rule-evaluator
+	// Deployment. Rule groups are assigned to shards by a stable hash of their namespace,
+	// object name and group name.
+	// Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=64
+	Shards int32 `json:"shards,omitempty"`
 }
 
 // CollectionSpec specifies how the operator configures collection of metric data.
@@ -200,8 +358,25 @@ type ExportSpec struct {
 type OperatorFeatures struct {
 	// Configuration of target status reporting.
 	TargetStatus TargetStatusSpec `json:"targetStatus,omitempty"`
+	// Configuration of rule evaluation status reporting.
+	RuleStatus RuleStatusSpec `json:"ruleStatus,omitempty"`
+	// Lint checks of Rules, ClusterRules and GlobalRules.
+	RuleLint RuleLintSpec `json:"ruleLint,omitempty"`
 	// Settings for the collector configuration propagation.
 	Config ConfigSpec `json:"config,omitempty"`
+	// Settings for the self-monitoring of the managed collection components.
+	SelfMonitoring SelfMonitoringSpec `json:"selfMonitoring,omitempty"`
+}
+
+// SelfMonitoringSpec holds configuration for the self-monitoring of the operator, collectors,
+// rule-evaluator, Alertmanager and their config-reloaders.
+type SelfMonitoringSpec struct {
+	// Enable scraping of all managed collection components and evaluation of alerting
+	// rules for config reload failures, export errors and incomplete target status.
+	Enabled bool `json:"enabled,omitempty"`
+	// Interval at which the components are scraped. Defaults to 30s.
+	// +kubebuilder:validation:Format=duration
+	Interval string `json:"interval,omitempty"`
 }
 
 // ConfigSpec holds configurations for the Prometheus configuration.
@@ -218,6 +393,35 @@ type TargetStatusSpec struct {
 	Enabled bool `json:"enabled,omitempty"`
 }
 
+// RuleStatusSpec holds configuration for rule evaluation status reporting.
+type RuleStatusSpec struct {
+	// Enable reporting of the evaluation health of each rule group and rule in the
+	// status of Rules, ClusterRules and GlobalRules.
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// RuleLintSpec holds the lint checks that are run when Rules, ClusterRules and GlobalRules
+// are created or updated. Findings are returned as warnings and do not reject the resource.
+type RuleLintSpec struct {
+	// Warn about rate(), irate() and increase() applied to metrics that are gauges by
+	// naming convention, i.e. whose name does not end in _total, _count, _sum or _bucket.
+	CounterFunctionOnGauge bool `json:"counterFunctionOnGauge,omitempty"`
+	// Warn about alerting rules without a `for` duration, which fire on the result of
+	// a single evaluation.
+	MissingFor bool `json:"missingFor,omitempty"`
+	// Warn about range selectors shorter than twice the interval of their rule group.
+	ShortRange bool `json:"shortRange,omitempty"`
+	// Warn about absent() and absent_over_time() in Rules. The selectors are scoped to the
+	// namespace of the Rules, so they always return a result for metrics without a
+	// namespace label.
+	ScopedAbsent bool `json:"scopedAbsent,omitempty"`
+	// Warn about recording rules that aggregate by labels with a high cardinality by
+	// convention, such as pod, instance or container_id.
+	HighCardinalityBy bool `json:"highCardinalityBy,omitempty"`
+	// Warn about templates in labels and annotations of alerting rules that don't parse.
+	InvalidTemplate bool `json:"invalidTemplate,omitempty"`
+}
+
 // CompressionType is the compression type.
 // +kubebuilder:validation:Enum=none;gzip
 type CompressionType string
@@ -301,15 +505,29 @@ type ManagedAlertmanagerSpec struct {
 	ExternalURL string `json:"externalURL,omitempty"`
 }
 
-// AlertmanagerEndpoints defines a selection of a single Endpoints object
-// containing alertmanager IPs to fire alerts against.
+// AlertmanagerEndpoints defines a set of Alertmanagers to fire alerts against. They are
+// discovered through a Kubernetes Endpoints or Service object, a static list of URLs, or DNS.
+// +kubebuilder:validation:XValidation:rule="(has(self.name) ? 1 : 0) + (has(self.staticURLs) ? 1 : 0) + (has(self.dns) ? 1 : 0) == 1",message="exactly one of name, staticURLs and dns must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.name) || (has(self.namespace) && self.namespace != '')",message="namespace must be set if name is set"
 type AlertmanagerEndpoints struct {
-	// Namespace of Endpoints object.
-	Namespace string `json:"namespace"`
-	// Name of Endpoints object in Namespace.
-	Name string `json:"name"`
+	// Namespace of the Endpoints or Service object. Required if name is set.
+	Namespace string `json:"namespace,omitempty"`
+	// Name of the Endpoints or Service object in Namespace.
+	Name string `json:"name,omitempty"`
+	// Role of the object referenced by Namespace and Name. With the Endpoints role, alerts are
+	// sent to each endpoint. With the Service role, alerts are sent to the address of the
+	// Service. Defaults to Endpoints.
+	// +kubebuilder:validation:Enum=Endpoints;Service
+	Role string `json:"role,omitempty"`
 	// Port the Alertmanager API is exposed on.
-	Port intstr.IntOrString `json:"port"`
+	Port intstr.IntOrString `json:"port,omitempty"`
+	// StaticURLs are the URLs of Alertmanagers outside of the cluster, for example
+	// "https://alertmanager.example.com/prefix". All URLs must have the same scheme and
+	// path, which take precedence over Scheme and PathPrefix.
+	// +kubebuilder:validation:MaxItems=100
+	StaticURLs []string `json:"staticURLs,omitempty"`
+	// DNS discovers Alertmanagers through DNS records.
+	DNS *AlertmanagerDNSDiscovery `json:"dns,omitempty"`
 	// Scheme to use when firing alerts.
 	Scheme string `json:"scheme,omitempty"`
 	// Prefix for the HTTP path alerts are pushed to.
@@ -324,6 +542,28 @@ type AlertmanagerEndpoints struct {
 	// Timeout is a per-target Alertmanager timeout when pushing alerts.
 	// +kubebuilder:validation:Format=duration
 	Timeout string `json:"timeout,omitempty"`
+	// AlertRelabelConfigs are applied to alerts before they are sent to these Alertmanagers.
+	// Alerts dropped by them are still sent to other Alertmanagers.
+	// +kubebuilder:validation:MaxItems=250
+	AlertRelabelConfigs []RelabelingRule `json:"alertRelabelConfigs,omitempty"`
+}
+
+// AlertmanagerDNSDiscovery discovers Alertmanagers through DNS records.
+type AlertmanagerDNSDiscovery struct {
+	// Names are the DNS names to query.
+	// +kubebuilder:validation:MinItems=1
+	Names []string `json:"names"`
+	// Type of the DNS records to query. SRV records provide the port of each Alertmanager.
+	// Defaults to SRV.
+	// +kubebuilder:validation:Enum=SRV;A;AAAA
+	Type string `json:"type,omitempty"`
+	// Port of the Alertmanagers. Required for A and AAAA records.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+	// RefreshInterval is how often the DNS names are resolved. Defaults to 30s.
+	// +kubebuilder:validation:Format=duration
+	RefreshInterval string `json:"refreshInterval,omitempty"`
 }
 
 // Authorization specifies a subset of the Authorization struct, that is
@@ -382,4 +622,34 @@ type VPASpec struct {
 	// In GKE, installing Vertical Pod Autoscaling requires a cluster restart, and therefore it also results in an operator restart.
 	// In other environments, the operator may need to be restarted to enable VPA to run the following check again and watch for the objects.
 	Enabled bool `json:"enabled,omitempty"`
+	// Collector configures the policy of the collector VPA.
+	// +optional
+	Collector *VPAPolicy `json:"collector,omitempty"`
+	// RuleEvaluator configures the policy of the rule-evaluator VPA.
+	// +optional
+	RuleEvaluator *VPAPolicy `json:"ruleEvaluator,omitempty"`
+	// Alertmanager configures the policy of the managed Alertmanager VPA.
+	// +optional
+	Alertmanager *VPAPolicy `json:"alertmanager,omitempty"`
+}
+
+// VPAPolicy configures the vertical pod autoscaling of the main container of a component.
+// Unset fields keep the defaults of the operator.
+type VPAPolicy struct {
+	// MinAllowed is the minimum amount of resources recommended for the container.
+	// +optional
+	MinAllowed corev1.ResourceList `json:"minAllowed,omitempty"`
+	// MaxAllowed is the maximum amount of resources recommended for the container.
+	// +optional
+	MaxAllowed corev1.ResourceList `json:"maxAllowed,omitempty"`
+	// ControlledResources are the resources for which recommendations are computed and
+	// applied. Defaults to CPU and memory.
+	// +optional
+	// +listType=set
+	ControlledResources []corev1.ResourceName `json:"controlledResources,omitempty"`
+	// UpdateMode controls when recommendations are applied to the pods.
+	// Defaults to "Auto".
+	// +kubebuilder:validation:Enum=Off;Initial;Recreate;Auto
+	// +optional
+	UpdateMode string `json:"updateMode,omitempty"`
 }
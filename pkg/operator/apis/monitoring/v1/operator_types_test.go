@@ -177,8 +177,9 @@ func TestOperatorConfigValidate(t *testing.T) {
 				Rules: RuleEvaluatorSpec{
 					Alerting: AlertingSpec{
 						Alertmanagers: []AlertmanagerEndpoints{{
-							Name: "bar",
-							TLS:  &TLSConfig{},
+							Namespace: "foo",
+							Name:      "bar",
+							TLS:       &TLSConfig{},
 							Authorization: &Authorization{
 								Credentials: &v1.SecretKeySelector{},
 							},
@@ -198,8 +199,9 @@ func TestOperatorConfigValidate(t *testing.T) {
 				Rules: RuleEvaluatorSpec{
 					Alerting: AlertingSpec{
 						Alertmanagers: []AlertmanagerEndpoints{{
-							Name: "bar",
-							TLS:  &TLSConfig{},
+							Namespace: "foo",
+							Name:      "bar",
+							TLS:       &TLSConfig{},
 							Authorization: &Authorization{
 								Credentials: &v1.SecretKeySelector{
 									LocalObjectReference: v1.LocalObjectReference{
@@ -222,7 +224,8 @@ func TestOperatorConfigValidate(t *testing.T) {
 				Rules: RuleEvaluatorSpec{
 					Alerting: AlertingSpec{
 						Alertmanagers: []AlertmanagerEndpoints{{
-							Name: "bar",
+							Namespace: "foo",
+							Name:      "bar",
 							TLS: &TLSConfig{
 								KeySecret: &v1.SecretKeySelector{},
 							},
@@ -242,7 +245,8 @@ func TestOperatorConfigValidate(t *testing.T) {
 				Rules: RuleEvaluatorSpec{
 					Alerting: AlertingSpec{
 						Alertmanagers: []AlertmanagerEndpoints{{
-							Name: "bar",
+							Namespace: "foo",
+							Name:      "bar",
 							TLS: &TLSConfig{
 								KeySecret: &v1.SecretKeySelector{
 									LocalObjectReference: v1.LocalObjectReference{
@@ -265,7 +269,8 @@ func TestOperatorConfigValidate(t *testing.T) {
 				Rules: RuleEvaluatorSpec{
 					Alerting: AlertingSpec{
 						Alertmanagers: []AlertmanagerEndpoints{{
-							Name: "bar",
+							Namespace: "foo",
+							Name:      "bar",
 							TLS: &TLSConfig{
 								CA: &SecretOrConfigMap{
 									Secret: &v1.SecretKeySelector{},
@@ -287,7 +292,8 @@ func TestOperatorConfigValidate(t *testing.T) {
 				Rules: RuleEvaluatorSpec{
 					Alerting: AlertingSpec{
 						Alertmanagers: []AlertmanagerEndpoints{{
-							Name: "bar",
+							Namespace: "foo",
+							Name:      "bar",
 							TLS: &TLSConfig{
 								CA: &SecretOrConfigMap{
 									Secret: &v1.SecretKeySelector{
@@ -318,7 +324,8 @@ func TestOperatorConfigValidate(t *testing.T) {
 				Rules: RuleEvaluatorSpec{
 					Alerting: AlertingSpec{
 						Alertmanagers: []AlertmanagerEndpoints{{
-							Name: "bar",
+							Namespace: "foo",
+							Name:      "bar",
 							TLS: &TLSConfig{
 								Cert: &SecretOrConfigMap{
 									Secret: &v1.SecretKeySelector{
@@ -349,7 +356,8 @@ func TestOperatorConfigValidate(t *testing.T) {
 				Rules: RuleEvaluatorSpec{
 					Alerting: AlertingSpec{
 						Alertmanagers: []AlertmanagerEndpoints{{
-							Name: "bar",
+							Namespace: "foo",
+							Name:      "bar",
 							TLS: &TLSConfig{
 								CA: &SecretOrConfigMap{
 									Secret: &v1.SecretKeySelector{
@@ -374,7 +382,8 @@ func TestOperatorConfigValidate(t *testing.T) {
 				Rules: RuleEvaluatorSpec{
 					Alerting: AlertingSpec{
 						Alertmanagers: []AlertmanagerEndpoints{{
-							Name: "bar",
+							Namespace: "foo",
+							Name:      "bar",
 							TLS: &TLSConfig{
 								Cert: &SecretOrConfigMap{
 									Secret: &v1.SecretKeySelector{},
@@ -396,7 +405,8 @@ func TestOperatorConfigValidate(t *testing.T) {
 				Rules: RuleEvaluatorSpec{
 					Alerting: AlertingSpec{
 						Alertmanagers: []AlertmanagerEndpoints{{
-							Name: "bar",
+							Namespace: "foo",
+							Name:      "bar",
 							TLS: &TLSConfig{
 								Cert: &SecretOrConfigMap{
 									Secret: &v1.SecretKeySelector{
@@ -411,6 +421,130 @@ func TestOperatorConfigValidate(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "rule manager static URLs and DNS",
+			oc: &OperatorConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "config",
+				},
+				Rules: RuleEvaluatorSpec{
+					Alerting: AlertingSpec{
+						Alertmanagers: []AlertmanagerEndpoints{
+							{
+								StaticURLs: []string{"https://am-1.example.com/prefix", "https://am-2.example.com/prefix"},
+								AlertRelabelConfigs: []RelabelingRule{{
+									Action:       "keep",
+									SourceLabels: []string{"severity"},
+									Regex:        "critical",
+								}},
+							},
+							{
+								DNS: &AlertmanagerDNSDiscovery{
+									Names: []string{"_web._tcp.alertmanager.example.com"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "rule manager multiple discoveries",
+			oc: &OperatorConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "config",
+				},
+				Rules: RuleEvaluatorSpec{
+					Alerting: AlertingSpec{
+						Alertmanagers: []AlertmanagerEndpoints{{
+							Namespace:  "foo",
+							Name:       "bar",
+							StaticURLs: []string{"https://am.example.com"},
+						}},
+					},
+				},
+			},
+			err: "invalid rules config: invalid alert manager endpoint `bar` (index 0): exactly one of name, staticURLs and dns must be set",
+		},
+		{
+			desc: "rule manager name without namespace",
+			oc: &OperatorConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "config",
+				},
+				Rules: RuleEvaluatorSpec{
+					Alerting: AlertingSpec{
+						Alertmanagers: []AlertmanagerEndpoints{{
+							Name: "bar",
+						}},
+					},
+				},
+			},
+			err: "invalid rules config: invalid alert manager endpoint `bar` (index 0): namespace must be set if name is set",
+		},
+		{
+			desc: "rule manager static URLs with different paths",
+			oc: &OperatorConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "config",
+				},
+				Rules: RuleEvaluatorSpec{
+					Alerting: AlertingSpec{
+						Alertmanagers: []AlertmanagerEndpoints{{
+							StaticURLs: []string{"https://am-1.example.com/a", "https://am-2.example.com/b"},
+						}},
+					},
+				},
+			},
+			err: "invalid rules config: invalid alert manager endpoint `` (index 0): static URL \"https://am-2.example.com/b\" must have the same scheme and path as \"https://am-1.example.com/a\"",
+		},
+		{
+			desc: "rule manager DNS A records without port",
+			oc: &OperatorConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "config",
+				},
+				Rules: RuleEvaluatorSpec{
+					Alerting: AlertingSpec{
+						Alertmanagers: []AlertmanagerEndpoints{{
+							DNS: &AlertmanagerDNSDiscovery{
+								Names: []string{"alertmanager.example.com"},
+								Type:  "A",
+							},
+						}},
+					},
+				},
+			},
+			err: "invalid rules config: invalid alert manager endpoint `` (index 0): missing port for DNS record type \"A\"",
+		},
+		{
+			desc: "rule manager alert relabeling onto protected label",
+			oc: &OperatorConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "config",
+				},
+				Rules: RuleEvaluatorSpec{
+					Alerting: AlertingSpec{
+						Alertmanagers: []AlertmanagerEndpoints{{
+							Namespace: "foo",
+							Name:      "bar",
+							AlertRelabelConfigs: []RelabelingRule{{
+								Action:      "replace",
+								TargetLabel: "cluster",
+								Replacement: "other",
+							}},
+						}},
+					},
+				},
+			},
+			err: "invalid rules config: invalid alert manager endpoint `bar` (index 0): invalid alert relabeling: rule 0: cannot relabel with action \"replace\" onto protected label \"cluster\"",
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.desc, func(t *testing.T) {
@@ -0,0 +1,109 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MonitoringQuota limits the scrape configuration of the PodMonitorings in its namespace.
+// If a namespace has multiple MonitoringQuotas, the most restrictive value of each limit
+// applies.
+// ClusterPodMonitorings are not limited by MonitoringQuotas and do not count towards their
+// usage, even if they select pods in the namespace.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+type MonitoringQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of the limits in the namespace.
+	Spec MonitoringQuotaSpec `json:"spec"`
+	// Most recently observed usage of the namespace.
+	// +optional
+	Status MonitoringQuotaStatus `json:"status"`
+}
+
+// MonitoringQuotaList is a list of MonitoringQuotas.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type MonitoringQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []MonitoringQuota `json:"items"`
+}
+
+// MonitoringQuotaSpec contains the limits of a MonitoringQuota. Unset limits are not enforced.
+type MonitoringQuotaSpec struct {
+	// Maximum number of scrape endpoints across all PodMonitorings in the namespace.
+	// PodMonitorings that would exceed it are rejected on admission. Existing PodMonitorings
+	// exceeding it, in order of their creation, are not scraped.
+	// +optional
+	Endpoints uint64 `json:"endpoints,omitempty"`
+	// Maximum number of samples accepted within a single scrape. Higher or unset sample
+	// limits of PodMonitorings in the namespace are lowered to it.
+	// +optional
+	SamplesPerScrape uint64 `json:"samplesPerScrape,omitempty"`
+	// Maximum number of targets of a single scrape endpoint scraped by each collector.
+	// If exceeded, all targets of the endpoint on that collector fail to be scraped.
+	// +optional
+	TargetsPerEndpoint uint64 `json:"targetsPerEndpoint,omitempty"`
+}
+
+// MonitoringQuotaStatus holds the usage of the namespace of a MonitoringQuota.
+type MonitoringQuotaStatus struct {
+	// The generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Usage of the namespace.
+	// +optional
+	Used MonitoringQuotaUsage `json:"used"`
+	// Last time the usage was updated.
+	// +optional
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// MonitoringQuotaUsage is the usage of a namespace that is limited by MonitoringQuotas.
+type MonitoringQuotaUsage struct {
+	// Number of PodMonitorings in the namespace.
+	PodMonitorings int64 `json:"podMonitorings"`
+	// Number of scrape endpoints across all PodMonitorings in the namespace.
+	Endpoints int64 `json:"endpoints"`
+	// Number of active targets across all PodMonitorings in the namespace. It is only
+	// reported if the target status feature is enabled in the OperatorConfig.
+	// +optional
+	Targets int64 `json:"targets,omitempty"`
+}
+
+// EffectiveQuota returns the most restrictive limits of the given MonitoringQuotas.
+func EffectiveQuota(quotas []MonitoringQuota) MonitoringQuotaSpec {
+	var res MonitoringQuotaSpec
+	for _, q := range quotas {
+		res.Endpoints = minLimit(res.Endpoints, q.Spec.Endpoints)
+		res.SamplesPerScrape = minLimit(res.SamplesPerScrape, q.Spec.SamplesPerScrape)
+		res.TargetsPerEndpoint = minLimit(res.TargetsPerEndpoint, q.Spec.TargetsPerEndpoint)
+	}
+	return res
+}
+
+// minLimit returns the lower of two limits, where zero means no limit.
+func minLimit(a, b uint64) uint64 {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
@@ -115,6 +115,36 @@ func RulesResource() metav1.GroupVersionResource {
 	}
 }
 
+// MonitoringQuotaResource returns a MonitoringQuota GroupVersionResource.
+// This can be used to enforce API types.
+func MonitoringQuotaResource() metav1.GroupVersionResource {
+	return metav1.GroupVersionResource{
+		Group:    monitoring.GroupName,
+		Version:  Version,
+		Resource: "monitoringquotas",
+	}
+}
+
+// AlertRoutingResource returns an AlertRouting GroupVersionResource.
+// This can be used to enforce API types.
+func AlertRoutingResource() metav1.GroupVersionResource {
+	return metav1.GroupVersionResource{
+		Group:    monitoring.GroupName,
+		Version:  Version,
+		Resource: "alertroutings",
+	}
+}
+
+// SilenceResource returns a Silence GroupVersionResource.
+// This can be used to enforce API types.
+func SilenceResource() metav1.GroupVersionResource {
+	return metav1.GroupVersionResource{
+		Group:    monitoring.GroupName,
+		Version:  Version,
+		Resource: "silences",
+	}
+}
+
 // Adds the list of known types to Scheme.
 func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
@@ -132,6 +162,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&GlobalRulesList{},
 		&OperatorConfig{},
 		&OperatorConfigList{},
+		&MonitoringQuota{},
+		&MonitoringQuotaList{},
+		&AlertRouting{},
+		&AlertRoutingList{},
+		&Silence{},
+		&SilenceList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
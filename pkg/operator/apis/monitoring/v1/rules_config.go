@@ -16,6 +16,7 @@ package v1
 
 import (
 	"fmt"
+	"maps"
 
 	model "github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/google/export"
@@ -26,32 +27,46 @@ import (
 )
 
 func (r *Rules) RuleGroupsConfig(projectID, location, cluster string) (string, error) {
-	return ruleGroupsConfig(r.Spec.Groups, map[string]string{
+	lset := map[string]string{
 		export.KeyProjectID: projectID,
 		export.KeyLocation:  location,
 		export.KeyCluster:   cluster,
 		export.KeyNamespace: r.Namespace,
-	})
+	}
+	return ruleGroupsConfig(r.Spec.Groups, querySelectors(lset, r.Spec.QueryProjectID), lset)
 }
 
 func (r *ClusterRules) RuleGroupsConfig(projectID, location, cluster string) (string, error) {
-	return ruleGroupsConfig(r.Spec.Groups, map[string]string{
+	lset := map[string]string{
 		export.KeyProjectID: projectID,
 		export.KeyLocation:  location,
 		export.KeyCluster:   cluster,
-	})
+	}
+	return ruleGroupsConfig(r.Spec.Groups, querySelectors(lset, r.Spec.QueryProjectID), lset)
 }
 
 func (r *GlobalRules) RuleGroupsConfig() (string, error) {
-	return ruleGroupsConfig(r.Spec.Groups, map[string]string{})
+	return ruleGroupsConfig(r.Spec.Groups, map[string]string{}, map[string]string{})
 }
 
-func ruleGroupsConfig(ruleGroups []RuleGroup, labelSet map[string]string) (string, error) {
+// querySelectors returns the labels that the metric selectors of rules are scoped to.
+// Rules that query another project do not select the project of the cluster, as the data
+// of the cluster may be written to, or read through, the queried project.
+func querySelectors(lset map[string]string, queryProjectID string) map[string]string {
+	if queryProjectID == "" {
+		return lset
+	}
+	selectors := maps.Clone(lset)
+	delete(selectors, export.KeyProjectID)
+	return selectors
+}
+
+func ruleGroupsConfig(ruleGroups []RuleGroup, selectors, labelSet map[string]string) (string, error) {
 	rs, err := fromAPIRules(ruleGroups)
 	if err != nil {
 		return "", fmt.Errorf("converting rules failed: %w", err)
 	}
-	if err := scope(&rs, labelSet); err != nil {
+	if err := scope(&rs, selectors, labelSet); err != nil {
 		return "", fmt.Errorf("isolating rules failed: %w", err)
 	}
 	result, err := yaml.Marshal(rs)
@@ -70,7 +85,7 @@ func fromAPIRules(groups []RuleGroup) (result rulefmt.RuleGroups, err error) {
 
 		for _, r := range g.Rules {
 			rule := rulefmt.RuleNode{
-				Labels:      r.Labels,
+				Labels:      mergeLabels(g.Labels, r.Labels),
 				Annotations: r.Annotations,
 			}
 			rule.Expr.SetString(r.Expr)
@@ -86,10 +101,17 @@ func fromAPIRules(groups []RuleGroup) (result rulefmt.RuleGroups, err error) {
 					return result, fmt.Errorf("parse 'for' duration: %w", err)
 				}
 			}
+			if r.KeepFiringFor != "" {
+				rule.KeepFiringFor, err = model.ParseDuration(r.KeepFiringFor)
+				if err != nil {
+					return result, fmt.Errorf("parse 'keepFiringFor' duration: %w", err)
+				}
+			}
 			rules = append(rules, rule)
 		}
 		group := rulefmt.RuleGroup{
 			Name:  g.Name,
+			Limit: int(g.Limit),
 			Rules: rules,
 		}
 		if g.Interval != "" {
@@ -98,6 +120,13 @@ func fromAPIRules(groups []RuleGroup) (result rulefmt.RuleGroups, err error) {
 				return result, fmt.Errorf("parse evaluation interval: %w", err)
 			}
 		}
+		if g.QueryOffset != "" {
+			queryOffset, err := model.ParseDuration(g.QueryOffset)
+			if err != nil {
+				return result, fmt.Errorf("parse query offset: %w", err)
+			}
+			group.QueryOffset = &queryOffset
+		}
 		result.Groups = append(result.Groups, group)
 	}
 	// Do a marshal/unmarshal cycle to run the upstream validation.
@@ -112,12 +141,25 @@ func fromAPIRules(groups []RuleGroup) (result rulefmt.RuleGroups, err error) {
 	return result, nil
 }
 
+// mergeLabels returns the labels of a rule group overridden by the labels of
+// one of its rules. The rule-evaluator's rule file format has no group labels,
+// so they are attached to every rule instead.
+func mergeLabels(groupLabels, ruleLabels map[string]string) map[string]string {
+	if len(groupLabels) == 0 {
+		return ruleLabels
+	}
+	result := make(map[string]string, len(groupLabels)+len(ruleLabels))
+	maps.Copy(result, groupLabels)
+	maps.Copy(result, ruleLabels)
+	return result
+}
+
 // scope all rules in the given groups to the given labels. All metric selectors
-// check for equality on the labels and all rule results are annotated with them again.
-// This ensures that the scope is preserved in output data, even if the given label keys
-// are aggregated away.
+// check for equality on the selector labels and all rule results are annotated with
+// the labels again. This ensures that the scope is preserved in output data, even if the
+// given label keys are aggregated away.
 // An error is returned if metric selectors have a conflicting selector set.
-func scope(groups *rulefmt.RuleGroups, lset map[string]string) error {
+func scope(groups *rulefmt.RuleGroups, selectors, lset map[string]string) error {
 	for _, g := range groups.Groups {
 		for i, r := range g.Rules {
 			expr, err := parser.ParseExpr(r.Expr.Value)
@@ -129,7 +171,7 @@ func scope(groups *rulefmt.RuleGroups, lset map[string]string) error {
 			err = walkExpr(expr, func(n parser.Node) error {
 				vs, ok := n.(*parser.VectorSelector)
 				if ok {
-					for name, value := range lset {
+					for name, value := range selectors {
 						if err := setSelector(vs, name, value); err != nil {
 							return fmt.Errorf("set isolation selector %s=%q on %s: %w", name, value, vs, err)
 						}
@@ -65,6 +65,94 @@ func TestGenerateRules(t *testing.T) {
             location: us-central1
             namespace: test-namespace
             project_id: "123"
+`,
+			wantErr: false,
+		},
+		{
+			name: "query project",
+			apiRules: &Rules{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test-namespace",
+				},
+				Spec: RulesSpec{
+					QueryProjectID: "team-proj",
+					Groups: []RuleGroup{
+						{
+							Name: "test-group",
+							Rules: []Rule{
+								{
+									Record: "test_record",
+									Expr:   "test_expr",
+								},
+							},
+						},
+					},
+				},
+			},
+			// The queried project is not filtered by the project of the cluster, but the
+			// results are still written to it.
+			want: `groups:
+    - name: test-group
+      rules:
+        - record: test_record
+          expr: test_expr{cluster="test-cluster",location="us-central1",namespace="test-namespace"}
+          labels:
+            cluster: test-cluster
+            location: us-central1
+            namespace: test-namespace
+            project_id: "123"
+`,
+			wantErr: false,
+		},
+		{
+			name: "group and alerting rule options",
+			apiRules: &Rules{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "test-namespace",
+				},
+				Spec: RulesSpec{
+					Groups: []RuleGroup{
+						{
+							Name:        "test-group",
+							Interval:    "30s",
+							QueryOffset: "1m",
+							Limit:       10,
+							Labels: map[string]string{
+								"team":     "a",
+								"severity": "warning",
+							},
+							Rules: []Rule{
+								{
+									Alert:         "test_alert",
+									Expr:          "test_expr",
+									For:           "5m",
+									KeepFiringFor: "10m",
+									Labels: map[string]string{
+										"severity": "critical",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want: `groups:
+    - name: test-group
+      interval: 30s
+      query_offset: 1m
+      limit: 10
+      rules:
+        - alert: test_alert
+          expr: test_expr{cluster="test-cluster",location="us-central1",namespace="test-namespace",project_id="123"}
+          for: 5m
+          keep_firing_for: 10m
+          labels:
+            cluster: test-cluster
+            location: us-central1
+            namespace: test-namespace
+            project_id: "123"
+            severity: critical
+            team: a
 `,
 			wantErr: false,
 		},
@@ -131,6 +219,48 @@ func TestGenerateRules(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "group namespace label",
+			apiRules: &Rules{
+				Spec: RulesSpec{
+					Groups: []RuleGroup{
+						{
+							Name: "test-group",
+							Labels: map[string]string{
+								export.KeyNamespace: "other",
+							},
+							Rules: []Rule{
+								{
+									Record: "test_record",
+									Expr:   "test_expr",
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid keep firing for",
+			apiRules: &Rules{
+				Spec: RulesSpec{
+					Groups: []RuleGroup{
+						{
+							Name: "test-group",
+							Rules: []Rule{
+								{
+									Alert:         "test_alert",
+									Expr:          "test_expr",
+									KeepFiringFor: "10",
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -187,6 +317,36 @@ func TestGenerateClusterRules(t *testing.T) {
             cluster: test-cluster
             location: us-central1
             project_id: "123"
+`,
+			wantErr: false,
+		},
+		{
+			name: "query project",
+			apiRules: &ClusterRules{
+				Spec: RulesSpec{
+					QueryProjectID: "team-proj",
+					Groups: []RuleGroup{
+						{
+							Name: "test-group",
+							Rules: []Rule{
+								{
+									Record: "test_record",
+									Expr:   "test_expr",
+								},
+							},
+						},
+					},
+				},
+			},
+			want: `groups:
+    - name: test-group
+      rules:
+        - record: test_record
+          expr: test_expr{cluster="test-cluster",location="us-central1"}
+          labels:
+            cluster: test-cluster
+            location: us-central1
+            project_id: "123"
 `,
 			wantErr: false,
 		},
@@ -429,10 +589,11 @@ func TestScope(t *testing.T) {
 	if len(errs) > 0 {
 		t.Fatalf("Unexpected input errors: %s", errs)
 	}
-	if err := scope(groups, map[string]string{
+	lset := map[string]string{
 		"l1": "v1",
 		"l2": "v2",
-	}); err != nil {
+	}
+	if err := scope(groups, lset, lset); err != nil {
 		t.Error(err)
 	}
 	want := `groups:
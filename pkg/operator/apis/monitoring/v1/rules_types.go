@@ -172,6 +172,13 @@ type GlobalRulesList struct {
 
 // RulesSpec contains specification parameters for a Rules resource.
 type RulesSpec struct {
+	// QueryProjectID is the GCP project ID to evaluate the rules against instead of the
+	// queryProjectID of the rule-evaluator. It must be listed in the allowedQueryProjectIDs
+	// of the rule-evaluator in the OperatorConfig.
+	// Metric selectors of Rules and ClusterRules are then not restricted to the project_id of
+	// the cluster, while rule results are still written to it. The "for" state of alerts is
+	// always restored from the queryProjectID of the rule-evaluator.
+	QueryProjectID string `json:"queryProjectID,omitempty"`
 	// A list of Prometheus rule groups.
 	Groups []RuleGroup `json:"groups"`
 }
@@ -185,6 +192,17 @@ type RuleGroup struct {
 	// +kubebuilder:validation:Format=duration
 	// +kubebuilder:default="1m"
 	Interval string `json:"interval,omitempty"`
+	// The duration by which the evaluation of the rules is delayed, e.g. to give
+	// late samples time to arrive. Must be a valid Prometheus duration.
+	// +kubebuilder:validation:Format=duration
+	QueryOffset string `json:"queryOffset,omitempty"`
+	// The maximum number of series a recording rule and alerts an alerting rule
+	// may produce. 0 means no limit.
+	// +kubebuilder:validation:Minimum=0
+	Limit int32 `json:"limit,omitempty"`
+	// A set of labels to attach to the results of all rules of the group.
+	// Labels of a rule take precedence over labels of the group.
+	Labels map[string]string `json:"labels,omitempty"`
 	// A list of rules that are executed sequentially as part of this group.
 	// +kubebuilder:validation:MinItems=1
 	Rules []Rule `json:"rules"`
@@ -194,6 +212,7 @@ type RuleGroup struct {
 // https://prometheus.io/docs/prometheus/latest/configuration/recording_rules/
 // +kubebuilder:validation:XValidation:rule="(has(self.record) ? 1 : 0) + (has(self.alert) ? 1 : 0) == 1",message="Must set exactly one of Record or Alert"
 // +kubebuilder:validation:XValidation:rule="!has(self.annotations) || has(self.alert)",message="Annotations are only allowed for alerting rules"
+// +kubebuilder:validation:XValidation:rule="!has(self.keepFiringFor) || has(self.alert)",message="KeepFiringFor is only allowed for alerting rules"
 type Rule struct {
 	// Record the result of the expression to this metric name.
 	// Only one of `record` and `alert` must be set.
@@ -208,6 +227,10 @@ type Rule struct {
 	// Only valid if `alert` is set.
 	// +kubebuilder:validation:Format=duration
 	For string `json:"for,omitempty"`
+	// The duration for which an alert produced by this rule keeps firing after its
+	// condition cleared. Only valid if `alert` is set.
+	// +kubebuilder:validation:Format=duration
+	KeepFiringFor string `json:"keepFiringFor,omitempty"`
 	// A set of labels to attach to the result of the query expression.
 	Labels map[string]string `json:"labels,omitempty"`
 	// A set of annotations to attach to alerts produced by the query expression.
@@ -218,4 +241,36 @@ type Rule struct {
 // RulesStatus contains status information for a Rules resource.
 type RulesStatus struct {
 	MonitoringStatus `json:",inline"`
+
+	// Represents the latest observed evaluation health of each rule group.
+	// Only populated if rule status reporting is enabled in the OperatorConfig.
+	RuleGroupStatuses []RuleGroupStatus `json:"ruleGroupStatuses,omitempty"`
+}
+
+// RuleGroupStatus holds the evaluation health of a rule group.
+type RuleGroupStatus struct {
+	// The name of the rule group.
+	Name string `json:"name"`
+	// Time of the last evaluation of the rule group.
+	LastEvaluation metav1.Time `json:"lastEvaluation,omitempty"`
+	// Duration of the last evaluation of the rule group in seconds. An evaluation
+	// duration close to the interval of the rule group indicates that evaluations
+	// may be missed.
+	EvaluationDurationSeconds string `json:"evaluationDurationSeconds,omitempty"`
+	// The evaluation health of each rule of the rule group, in the order of the rules.
+	RuleStatuses []RuleStatus `json:"ruleStatuses,omitempty"`
+}
+
+// RuleStatus holds the evaluation health of a single rule.
+type RuleStatus struct {
+	// The name of the recorded metric or the alert.
+	Name string `json:"name"`
+	// Health of the last evaluation. One of "ok", "err" or "unknown".
+	Health string `json:"health,omitempty"`
+	// Error of the last evaluation, e.g. a failed query.
+	LastError string `json:"lastError,omitempty"`
+	// Time of the last evaluation of the rule.
+	LastEvaluation metav1.Time `json:"lastEvaluation,omitempty"`
+	// Duration of the last evaluation of the rule in seconds.
+	EvaluationDurationSeconds string `json:"evaluationDurationSeconds,omitempty"`
 }
@@ -0,0 +1,88 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SilenceExpired indicates that the end of a Silence has passed.
+const SilenceExpired MonitoringConditionType = "Expired"
+
+// Silence mutes alerts of its namespace in the managed Alertmanager for a period of time.
+// The operator keeps a matching silence in the Alertmanager, restores it if the Alertmanager
+// loses its state and reports the Expired condition once the silence ended. Ended Silences
+// are deleted after a retention period so that they can still be extended until then.
+//
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Starts",type=date,JSONPath=`.spec.startsAt`
+// +kubebuilder:printcolumn:name="Ends",type=date,JSONPath=`.spec.endsAt`
+// +kubebuilder:printcolumn:name="Silence ID",type=string,JSONPath=`.status.silenceID`
+type Silence struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of the silenced alerts.
+	Spec SilenceSpec `json:"spec"`
+	// Most recently observed status of the resource.
+	// +optional
+	Status SilenceStatus `json:"status"`
+}
+
+func (s *Silence) GetMonitoringStatus() *MonitoringStatus {
+	return &s.Status.MonitoringStatus
+}
+
+// SilenceList is a list of Silences.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SilenceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Silence `json:"items"`
+}
+
+// SilenceSpec contains the specification of a Silence.
+type SilenceSpec struct {
+	// Matchers of the silenced alerts in the Alertmanager matcher format, for example
+	// `severity="warning"`. A matcher on the namespace label of the resource is added.
+	// +kubebuilder:validation:MinItems=1
+	Matchers []string `json:"matchers"`
+	// Start of the silence. Defaults to the creation time of the resource.
+	// +optional
+	StartsAt *metav1.Time `json:"startsAt,omitempty"`
+	// End of the silence.
+	EndsAt metav1.Time `json:"endsAt"`
+	// Comment shown with the silence in the Alertmanager.
+	// +optional
+	Comment string `json:"comment,omitempty"`
+	// Duration after the end of the silence after which the resource is deleted.
+	// Must be a valid Prometheus duration.
+	// +kubebuilder:validation:Format=duration
+	// +kubebuilder:default="24h"
+	// +optional
+	DeleteAfter string `json:"deleteAfter,omitempty"`
+}
+
+// SilenceStatus contains the status of a Silence.
+type SilenceStatus struct {
+	MonitoringStatus `json:",inline"`
+	// ID of the silence in the managed Alertmanager.
+	// +optional
+	SilenceID string `json:"silenceID,omitempty"`
+}
@@ -0,0 +1,247 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+// AnnotationV1Data holds the content of a v1 object that cannot be represented in v1alpha1.
+// It is restored when the object is converted back to v1 without changes made through v1alpha1.
+const AnnotationV1Data = "monitoring.googleapis.com/v1-data"
+
+// The v1alpha1 types are a subset of their v1 counterparts with identical field names, so
+// objects are converted by their JSON representation.
+
+// ConvertTo converts the PodMonitoring to the v1 hub version.
+func (src *PodMonitoring) ConvertTo(dst conversion.Hub) error {
+	return convertToHub(src, dst)
+}
+
+// ConvertFrom converts the PodMonitoring from the v1 hub version.
+func (dst *PodMonitoring) ConvertFrom(src conversion.Hub) error {
+	return convertFromHub(src, dst)
+}
+
+// ConvertTo converts the ClusterPodMonitoring to the v1 hub version.
+func (src *ClusterPodMonitoring) ConvertTo(dst conversion.Hub) error {
+	return convertToHub(src, dst)
+}
+
+// ConvertFrom converts the ClusterPodMonitoring from the v1 hub version.
+func (dst *ClusterPodMonitoring) ConvertFrom(src conversion.Hub) error {
+	return convertFromHub(src, dst)
+}
+
+// ConvertTo converts the Rules to the v1 hub version.
+func (src *Rules) ConvertTo(dst conversion.Hub) error {
+	return convertToHub(src, dst)
+}
+
+// ConvertFrom converts the Rules from the v1 hub version.
+func (dst *Rules) ConvertFrom(src conversion.Hub) error {
+	return convertFromHub(src, dst)
+}
+
+// ConvertTo converts the ClusterRules to the v1 hub version.
+func (src *ClusterRules) ConvertTo(dst conversion.Hub) error {
+	return convertToHub(src, dst)
+}
+
+// ConvertFrom converts the ClusterRules from the v1 hub version.
+func (dst *ClusterRules) ConvertFrom(src conversion.Hub) error {
+	return convertFromHub(src, dst)
+}
+
+// ConvertTo converts the GlobalRules to the v1 hub version.
+func (src *GlobalRules) ConvertTo(dst conversion.Hub) error {
+	return convertToHub(src, dst)
+}
+
+// ConvertFrom converts the GlobalRules from the v1 hub version.
+func (dst *GlobalRules) ConvertFrom(src conversion.Hub) error {
+	return convertFromHub(src, dst)
+}
+
+// ConvertTo converts the OperatorConfig to the v1 hub version.
+func (src *OperatorConfig) ConvertTo(dst conversion.Hub) error {
+	return convertToHub(src, dst)
+}
+
+// ConvertFrom converts the OperatorConfig from the v1 hub version.
+func (dst *OperatorConfig) ConvertFrom(src conversion.Hub) error {
+	return convertFromHub(src, dst)
+}
+
+func convertToHub(spoke client.Object, hub conversion.Hub) error {
+	dst, ok := hub.(client.Object)
+	if !ok {
+		return fmt.Errorf("unexpected hub type %T", hub)
+	}
+	spoke = spoke.DeepCopyObject().(client.Object)
+	data, hasData := spoke.GetAnnotations()[AnnotationV1Data]
+	removeV1Data(spoke)
+
+	if err := convertObject(spoke, dst, monitoringv1.SchemeGroupVersion); err != nil {
+		return err
+	}
+	if !hasData {
+		return nil
+	}
+	// Restore the v1 content if the object was not changed through v1alpha1 since.
+	restored, err := withContent(dst, []byte(data))
+	if err != nil {
+		return nil //nolint:nilerr // An invalid annotation must not block reading the object.
+	}
+	down := newObject(spoke)
+	if err := convertObject(restored, down, SchemeGroupVersion); err != nil {
+		return err
+	}
+	got, err := content(down)
+	if err != nil {
+		return err
+	}
+	want, err := content(spoke)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(got, want) {
+		reflect.ValueOf(dst).Elem().Set(reflect.ValueOf(restored).Elem())
+	}
+	return nil
+}
+
+func convertFromHub(hub conversion.Hub, spoke client.Object) error {
+	src, ok := hub.(client.Object)
+	if !ok {
+		return fmt.Errorf("unexpected hub type %T", hub)
+	}
+	if err := convertObject(src, spoke, SchemeGroupVersion); err != nil {
+		return err
+	}
+	removeV1Data(spoke)
+
+	// Keep the v1 content in an annotation if it does not survive the conversion.
+	up := newObject(src)
+	if err := convertObject(spoke, up, monitoringv1.SchemeGroupVersion); err != nil {
+		return err
+	}
+	got, err := content(up)
+	if err != nil {
+		return err
+	}
+	want, err := content(src)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(got, want) {
+		return nil
+	}
+	annotations := spoke.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AnnotationV1Data] = string(want)
+	spoke.SetAnnotations(annotations)
+	return nil
+}
+
+// convertObject replaces dst with the fields of src that are known to the type of dst.
+func convertObject(src, dst client.Object, gv schema.GroupVersion) error {
+	b, err := json.Marshal(src)
+	if err != nil {
+		return fmt.Errorf("marshal %T: %w", src, err)
+	}
+	v := reflect.ValueOf(dst).Elem()
+	v.Set(reflect.Zero(v.Type()))
+	if err := json.Unmarshal(b, dst); err != nil {
+		return fmt.Errorf("unmarshal %T: %w", dst, err)
+	}
+	dst.GetObjectKind().SetGroupVersionKind(gv.WithKind(v.Type().Name()))
+	return nil
+}
+
+// content returns the JSON encoding of the object without its type and metadata.
+func content(obj client.Object) ([]byte, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %T: %w", obj, err)
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	delete(m, "apiVersion")
+	delete(m, "kind")
+	delete(m, "metadata")
+	return json.Marshal(m)
+}
+
+// withContent returns a copy of obj with its content replaced by the given JSON.
+func withContent(obj client.Object, data []byte) (client.Object, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var objMap map[string]json.RawMessage
+	if err := json.Unmarshal(b, &objMap); err != nil {
+		return nil, err
+	}
+	for _, key := range []string{"apiVersion", "kind", "metadata"} {
+		if v, ok := objMap[key]; ok {
+			m[key] = v
+		} else {
+			delete(m, key)
+		}
+	}
+	if b, err = json.Marshal(m); err != nil {
+		return nil, err
+	}
+	res := newObject(obj)
+	if err := json.Unmarshal(b, res); err != nil {
+		return nil, err
+	}
+	res.GetObjectKind().SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+	return res, nil
+}
+
+func newObject(like client.Object) client.Object {
+	return reflect.New(reflect.TypeOf(like).Elem()).Interface().(client.Object)
+}
+
+func removeV1Data(obj client.Object) {
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[AnnotationV1Data]; !ok {
+		return
+	}
+	delete(annotations, AnnotationV1Data)
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	obj.SetAnnotations(annotations)
+}
@@ -0,0 +1,228 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+// conversionPair is a v1alpha1 type and its v1 hub.
+type conversionPair struct {
+	spoke func() conversion.Convertible
+	hub   func() conversion.Hub
+}
+
+var conversionPairs = []conversionPair{
+	{
+		spoke: func() conversion.Convertible { return &PodMonitoring{} },
+		hub:   func() conversion.Hub { return &monitoringv1.PodMonitoring{} },
+	},
+	{
+		spoke: func() conversion.Convertible { return &ClusterPodMonitoring{} },
+		hub:   func() conversion.Hub { return &monitoringv1.ClusterPodMonitoring{} },
+	},
+	{
+		spoke: func() conversion.Convertible { return &Rules{} },
+		hub:   func() conversion.Hub { return &monitoringv1.Rules{} },
+	},
+	{
+		spoke: func() conversion.Convertible { return &ClusterRules{} },
+		hub:   func() conversion.Hub { return &monitoringv1.ClusterRules{} },
+	},
+	{
+		spoke: func() conversion.Convertible { return &GlobalRules{} },
+		hub:   func() conversion.Hub { return &monitoringv1.GlobalRules{} },
+	},
+	{
+		spoke: func() conversion.Convertible { return &OperatorConfig{} },
+		hub:   func() conversion.Hub { return &monitoringv1.OperatorConfig{} },
+	},
+}
+
+var conversionSeeds = []string{
+	`{"metadata":{"name":"test","namespace":"default"}}`,
+	`{
+		"metadata": {"name": "test", "namespace": "default", "labels": {"a": "b"}, "annotations": {"c": "d"}},
+		"spec": {
+			"selector": {"matchLabels": {"app": "test"}},
+			"endpoints": [{"port": "metrics", "scheme": "https", "path": "/m", "interval": "10s", "timeout": "5s",
+				"params": {"x": ["y"]}, "metricRelabeling": [{"sourceLabels": ["a"], "regex": "b", "action": "drop"}],
+				"proxyUrl": "http://proxy:8080", "tls": {"serverName": "test", "insecureSkipVerify": true}}],
+			"targetLabels": {"metadata": ["pod", "container"], "fromPod": [{"from": "a", "to": "b"}]},
+			"limits": {"samples": 100, "labels": 10},
+			"filterRunning": false,
+			"groups": [{"name": "group", "interval": "1m", "rules": [
+				{"record": "job:up:sum", "expr": "sum by (job) (up)", "labels": {"a": "b"}},
+				{"alert": "Down", "expr": "up == 0", "for": "5m", "annotations": {"summary": "down"}, "keepFiringFor": "1m"}
+			]}]
+		},
+		"status": {
+			"observedGeneration": 2,
+			"conditions": [{"type": "ConfigurationCreateSuccess", "status": "True", "reason": "ok", "message": "msg"}],
+			"endpointStatuses": [{"name": "PodMonitoring/default/test/metrics", "activeTargets": 2}]
+		}
+	}`,
+	`{
+		"metadata": {"name": "config", "namespace": "gmp-public"},
+		"rules": {
+			"externalLabels": {"a": "b"},
+			"queryProjectID": "project",
+			"generatorUrl": "http://example.com",
+			"alerting": {"alertmanagers": [{"namespace": "ns", "name": "am", "port": 9093, "scheme": "https", "pathPrefix": "/p",
+				"apiVersion": "v2", "timeout": "10s", "tls": {"ca": {"secret": {"name": "ca", "key": "ca.crt"}}, "serverName": "am"},
+				"authorization": {"type": "Bearer", "credentials": {"name": "token", "key": "token"}}}]},
+			"credentials": {"name": "creds", "key": "key.json"}
+		},
+		"collection": {
+			"externalLabels": {"c": "d"},
+			"filter": {"matchOneOf": ["{job=\"a\"}"]},
+			"credentials": {"name": "creds", "key": "key.json"},
+			"kubeletScraping": {"interval": "30s"},
+			"compression": "gzip"
+		},
+		"exports": [{"url": "http://export"}],
+		"managedAlertmanager": {"configSecret": {"name": "am", "key": "config.yaml"}},
+		"features": {"targetStatus": {"enabled": true}}
+	}`,
+}
+
+// FuzzConvertToHub checks that v1alpha1 objects survive a round trip through v1.
+func FuzzConvertToHub(f *testing.F) {
+	for _, seed := range conversionSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, pair := range conversionPairs {
+			spoke := pair.spoke()
+			if !decodeObject(data, spoke) {
+				continue
+			}
+			spokeObj := spoke.(client.Object)
+			if _, ok := spokeObj.GetAnnotations()[AnnotationV1Data]; ok {
+				continue
+			}
+			hub := pair.hub()
+			if err := spoke.ConvertTo(hub); err != nil {
+				t.Fatalf("convert %T to hub: %s", spoke, err)
+			}
+			got := pair.spoke()
+			if err := got.ConvertFrom(hub); err != nil {
+				t.Fatalf("convert %T from hub: %s", got, err)
+			}
+			expectEqualJSON(t, spoke, got)
+		}
+	})
+}
+
+// FuzzConvertFromHub checks that v1 objects survive a round trip through v1alpha1, including
+// fields that do not exist in v1alpha1.
+func FuzzConvertFromHub(f *testing.F) {
+	for _, seed := range conversionSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, pair := range conversionPairs {
+			hub := pair.hub()
+			if !decodeObject(data, hub) {
+				continue
+			}
+			if _, ok := hub.(client.Object).GetAnnotations()[AnnotationV1Data]; ok {
+				continue
+			}
+			spoke := pair.spoke()
+			if err := spoke.ConvertFrom(hub); err != nil {
+				t.Fatalf("convert %T from hub: %s", spoke, err)
+			}
+			// Round trip through the API server encoding of the v1alpha1 object.
+			stored := pair.spoke()
+			if !decodeObject(mustMarshal(t, spoke), stored) {
+				t.Fatalf("decode converted %T", spoke)
+			}
+			got := pair.hub()
+			if err := stored.ConvertTo(got); err != nil {
+				t.Fatalf("convert %T to hub: %s", stored, err)
+			}
+			expectEqualJSON(t, hub, got)
+		}
+	})
+}
+
+func TestConvertFromHubModified(t *testing.T) {
+	hub := &monitoringv1.PodMonitoring{}
+	if !decodeObject([]byte(conversionSeeds[1]), hub) {
+		t.Fatal("decode seed")
+	}
+	spoke := &PodMonitoring{}
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := spoke.Annotations[AnnotationV1Data]; !ok {
+		t.Fatalf("expected annotation %q on converted object", AnnotationV1Data)
+	}
+	// Changes made through v1alpha1 discard the v1 content of the annotation.
+	spoke.Spec.Endpoints[0].Interval = "20s"
+
+	got := &monitoringv1.PodMonitoring{}
+	if err := spoke.ConvertTo(got); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got.Annotations[AnnotationV1Data]; ok {
+		t.Errorf("unexpected annotation %q on hub", AnnotationV1Data)
+	}
+	if got.Spec.Endpoints[0].Interval != "20s" {
+		t.Errorf("expected interval 20s, got %q", got.Spec.Endpoints[0].Interval)
+	}
+	if got.Spec.Endpoints[0].TLS != nil || got.Spec.FilterRunning != nil {
+		t.Errorf("expected v1 fields to be dropped, got %+v", got.Spec)
+	}
+}
+
+// decodeObject decodes data into obj and normalizes it by encoding it once more, so that
+// lossy encodings, e.g. of timestamps, do not fail the comparison.
+func decodeObject(data []byte, obj any) bool {
+	if err := json.Unmarshal(data, obj); err != nil {
+		return false
+	}
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(b, obj) == nil
+}
+
+func mustMarshal(t *testing.T, obj any) []byte {
+	t.Helper()
+	b, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func expectEqualJSON(t *testing.T, want, got runtime.Object) {
+	t.Helper()
+	want.GetObjectKind().SetGroupVersionKind(got.GetObjectKind().GroupVersionKind())
+	if w, g := mustMarshal(t, want), mustMarshal(t, got); !bytes.Equal(w, g) {
+		t.Errorf("%T changed after round trip:\nwant: %s\ngot:  %s", got, w, g)
+	}
+}
@@ -19,12 +19,16 @@ import (
 	"cmp"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
 	"path"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/prometheus/common/config"
@@ -92,12 +96,24 @@ func setupCollectionControllers(op *Operator) error {
 			enqueueConst(objRequest),
 			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
 		).
+		// Quotas limit the scrape configs generated for PodMonitorings.
+		Watches(
+			&monitoringv1.MonitoringQuota{},
+			enqueueConst(objRequest),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
 		// Any update to a ClusterNodeMonitoring requires regenerating the config.
 		Watches(
 			&monitoringv1.ClusterNodeMonitoring{},
 			enqueueConst(objRequest),
 			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
 		).
+		// Namespace labels may be matched by the namespaces filter.
+		Watches(
+			&corev1.Namespace{},
+			enqueueConst(objRequest),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}),
+		).
 		// The configuration we generate for the collectors.
 		Watches(
 			&corev1.ConfigMap{},
@@ -127,13 +143,44 @@ func setupCollectionControllers(op *Operator) error {
 type collectionReconciler struct {
 	client client.Client
 	opts   Options
+	// scrapeConfigs caches the scrape configs generated for each monitoring object.
+	scrapeConfigs *configCache[generatedScrapeConfigs]
+}
+
+// generatedScrapeConfigs holds the scrape configs generated for a single object.
+type generatedScrapeConfigs struct {
+	configs []*promconfig.ScrapeConfig
+	secrets monitoringv1.PrometheusSecretConfigs
 }
 
 func newCollectionReconciler(c client.Client, opts Options) *collectionReconciler {
-	return &collectionReconciler{
-		client: c,
-		opts:   opts,
+	r := &collectionReconciler{
+		client:        c,
+		opts:          opts,
+		scrapeConfigs: newConfigCache[generatedScrapeConfigs]("collector"),
 	}
+	r.scrapeConfigs.secretVersions = r.secretVersions
+	return r
+}
+
+// secretVersions returns the resource versions of the secrets referenced by the scrape configs.
+// Secrets the operator cannot read, such as those outside of the namespaces it watches secrets
+// in, have an empty version.
+func (r *collectionReconciler) secretVersions(ctx context.Context, res generatedScrapeConfigs) string {
+	keys := map[client.ObjectKey]struct{}{}
+	for _, c := range res.secrets {
+		keys[client.ObjectKey{Namespace: c.Namespace, Name: c.Name}] = struct{}{}
+	}
+	versions := make([]string, 0, len(keys))
+	for key := range keys {
+		var secret corev1.Secret
+		if err := r.client.Get(ctx, key, &secret); err != nil {
+			secret.ResourceVersion = ""
+		}
+		versions = append(versions, key.String()+"="+secret.ResourceVersion)
+	}
+	slices.Sort(versions)
+	return strings.Join(versions, ",")
 }
 
 func patchMonitoringStatus(ctx context.Context, kubeClient client.Client, obj client.Object, status *monitoringv1.MonitoringStatus) error {
@@ -172,13 +219,15 @@ func (r *collectionReconciler) Reconcile(ctx context.Context, req reconcile.Requ
 		return reconcile.Result{}, fmt.Errorf("ensure collector secrets: %w", err)
 	}
 	// Deploy Prometheus collector as a node agent.
-	if err := r.ensureCollectorDaemonSet(ctx); err != nil {
+	if err := r.ensureCollectorDaemonSet(ctx, config.Workloads.Collector); err != nil {
 		return reconcile.Result{}, fmt.Errorf("ensure collector daemon set: %w", err)
 	}
 
-	if err := r.ensureCollectorConfig(ctx, &config.Collection, config.Features.Config.Compression, config.Exports); err != nil {
+	start := time.Now()
+	if err := r.ensureCollectorConfig(ctx, &config.Collection, config.Features.Config.Compression, config.Exports, &config.Namespaces, &config.Features.SelfMonitoring); err != nil {
 		return reconcile.Result{}, fmt.Errorf("ensure collector config: %w", err)
 	}
+	configGenerationDuration.WithLabelValues(NameCollector).Observe(time.Since(start).Seconds())
 
 	return reconcile.Result{}, nil
 }
@@ -217,7 +266,7 @@ func (r *collectionReconciler) ensureCollectorSecrets(ctx context.Context, spec
 }
 
 // ensureCollectorDaemonSet populates the collector DaemonSet with operator-provided values.
-func (r *collectionReconciler) ensureCollectorDaemonSet(ctx context.Context) error {
+func (r *collectionReconciler) ensureCollectorDaemonSet(ctx context.Context, spec *monitoringv1.WorkloadSpec) error {
 	logger, _ := logr.FromContext(ctx)
 
 	var ds appsv1.DaemonSet
@@ -227,8 +276,10 @@ func (r *collectionReconciler) ensureCollectorDaemonSet(ctx context.Context) err
 	if apierrors.IsNotFound(err) {
 		logger.Error(err, "collector DaemonSet does not exist")
 		return nil
+	} else if err != nil {
+		return err
 	}
-	return err
+	return applyWorkloadOverrides(ctx, r.client, &ds, &ds.Spec.Template, CollectorPrometheusContainerName, spec)
 }
 
 func gzipData(data []byte) ([]byte, error) {
@@ -269,8 +320,8 @@ func setConfigMapData(cm *corev1.ConfigMap, c monitoringv1.CompressionType, key,
 }
 
 // ensureCollectorConfig generates the collector config and creates or updates it.
-func (r *collectionReconciler) ensureCollectorConfig(ctx context.Context, spec *monitoringv1.CollectionSpec, configCompression monitoringv1.CompressionType, exports []monitoringv1.ExportSpec) error {
-	cfg, updates, err := r.makeCollectorConfig(ctx, spec, exports)
+func (r *collectionReconciler) ensureCollectorConfig(ctx context.Context, spec *monitoringv1.CollectionSpec, configCompression monitoringv1.CompressionType, exports []monitoringv1.ExportSpec, namespaces *monitoringv1.NamespaceFilter, selfMonitoring *monitoringv1.SelfMonitoringSpec) error {
+	cfg, updates, err := r.makeCollectorConfig(ctx, spec, exports, namespaces, selfMonitoring)
 	if err != nil {
 		return fmt.Errorf("generate Prometheus config: %w", err)
 	}
@@ -298,18 +349,17 @@ func (r *collectionReconciler) ensureCollectorConfig(ctx context.Context, spec *
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: r.opts.OperatorNamespace,
 			Name:      NameCollector,
+			Annotations: map[string]string{
+				annotationConfigHash: configHash(cfgEncoded),
+			},
 		},
 	}
 	if err := setConfigMapData(cm, configCompression, configFilename, string(cfgEncoded)); err != nil {
 		return err
 	}
 
-	if err := r.client.Update(ctx, cm); apierrors.IsNotFound(err) {
-		if err := r.client.Create(ctx, cm); err != nil {
-			return fmt.Errorf("create Prometheus config: %w", err)
-		}
-	} else if err != nil {
-		return fmt.Errorf("update Prometheus config: %w", err)
+	if err := writeConfigMap(ctx, r.client, cm); err != nil {
+		return fmt.Errorf("write Prometheus config: %w", err)
 	}
 
 	// Reconcile any status updates.
@@ -335,6 +385,24 @@ func (r *collectionReconciler) ensureCollectorConfig(ctx context.Context, spec *
 	return errors.Join(errs...)
 }
 
+// annotationConfigHash is set on the collector ConfigMap to the hash of the uncompressed
+// configuration, which collectors report once loaded.
+const annotationConfigHash = "monitoring.googleapis.com/config-hash"
+
+// configHash returns the hex-encoded SHA256 hash of the configuration, matching the
+// hash reported by the config-reloader.
+func configHash(cfg []byte) string {
+	sum := sha256.Sum256(cfg)
+	return hex.EncodeToString(sum[:])
+}
+
+// The following job names are reserved by GMP for ClusterNodeMonitoring in the
+// gmp-system namespace. They will not be generated if kubeletScraping is enabled.
+const (
+	reservedCAdvisorJobName = "gmp-kubelet-cadvisor"
+	reservedKubeletJobName  = "gmp-kubelet-metrics"
+)
+
 type update struct {
 	object monitoringv1.MonitoringCRD
 	spec   bool
@@ -343,7 +411,7 @@ type update struct {
 
 // makeCollectorConfig returns the Prometheus configuration based on the scrape configurations, the
 // list of objects to update and any error.
-func (r *collectionReconciler) makeCollectorConfig(ctx context.Context, spec *monitoringv1.CollectionSpec, exports []monitoringv1.ExportSpec) (*promconfig.Config, []update, error) {
+func (r *collectionReconciler) makeCollectorConfig(ctx context.Context, spec *monitoringv1.CollectionSpec, exports []monitoringv1.ExportSpec, namespaces *monitoringv1.NamespaceFilter, selfMonitoring *monitoringv1.SelfMonitoringSpec) (*promconfig.Config, []update, error) {
 	logger, _ := logr.FromContext(ctx)
 
 	cfg := &promconfig.Config{
@@ -357,12 +425,30 @@ func (r *collectionReconciler) makeCollectorConfig(ctx context.Context, spec *mo
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create kubelet scrape config: %w", err)
 	}
+	projectID, location, cluster := resolveLabels(r.opts.ProjectID, r.opts.Location, r.opts.Cluster, spec.ExternalLabels)
+
+	if selfMonitoring != nil && selfMonitoring.Enabled {
+		selfCfgs, err := selfMonitoringScrapeConfigs(r.opts.OperatorNamespace, projectID, location, cluster, selfMonitoring)
+		if err != nil {
+			return nil, nil, err
+		}
+		cfg.ScrapeConfigs = append(cfg.ScrapeConfigs, selfCfgs...)
+	}
 
 	cfg.RemoteWriteConfigs, err = makeRemoteWriteConfig(exports)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create export config: %w", err)
 	}
 
+	nsFilter, err := newNamespaceFilter(r.client, namespaces)
+	if err != nil {
+		return nil, nil, err
+	}
+	quotas, err := listQuotas(ctx, r.client)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Generate a separate scrape job for every endpoint in every PodMonitoring.
 	var (
 		podMons         monitoringv1.PodMonitoringList
@@ -373,17 +459,54 @@ func (r *collectionReconciler) makeCollectorConfig(ctx context.Context, spec *mo
 		return nil, nil, fmt.Errorf("failed to list PodMonitorings: %w", err)
 	}
 
+	// Admit PodMonitorings in order of creation, so that newer ones cannot push existing ones
+	// out of the endpoint quota of their namespace.
+	slices.SortFunc(podMons.Items, func(a, b monitoringv1.PodMonitoring) int {
+		return cmp.Or(
+			a.CreationTimestamp.Compare(b.CreationTimestamp.Time),
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+	quotaEndpoints := map[string]uint64{}
+
 	usedSecrets := monitoringv1.PrometheusSecretConfigs{}
-	projectID, location, cluster := resolveLabels(r.opts.ProjectID, r.opts.Location, r.opts.Cluster, spec.ExternalLabels)
+	env := fmt.Sprintf("%s/%s/%s", projectID, location, cluster)
 	var updates []update
 
 	// Mark status updates in batch with single timestamp.
 	for _, pmon := range podMons.Items {
+		if ok, err := nsFilter.allowed(ctx, pmon.Namespace); err != nil {
+			return nil, nil, err
+		} else if !ok {
+			if pmon.Status.SetMonitoringCondition(pmon.GetGeneration(), metav1.Now(), namespaceIgnoredCondition(pmon.Namespace)) {
+				updates = append(updates, update{
+					object: &pmon,
+					status: true,
+				})
+			}
+			continue
+		}
+		quota := quotas[pmon.Namespace]
+		endpoints := quotaEndpoints[pmon.Namespace] + uint64(len(pmon.Spec.Endpoints))
+		if quota.Endpoints > 0 && endpoints > quota.Endpoints {
+			if pmon.Status.SetMonitoringCondition(pmon.GetGeneration(), metav1.Now(), quotaExceededCondition(quota)) {
+				updates = append(updates, update{
+					object: &pmon,
+					status: true,
+				})
+			}
+			continue
+		}
 		cond := &monitoringv1.MonitoringCondition{
 			Type:   monitoringv1.ConfigurationCreateSuccess,
 			Status: corev1.ConditionTrue,
 		}
-		cfgs, err := pmon.ScrapeConfigs(projectID, location, cluster, usedSecrets)
+		res, err := r.scrapeConfigs.get(ctx, &pmon, env, func() (generatedScrapeConfigs, error) {
+			pool := monitoringv1.PrometheusSecretConfigs{}
+			cfgs, err := pmon.ScrapeConfigs(projectID, location, cluster, pool)
+			return generatedScrapeConfigs{configs: cfgs, secrets: pool}, err
+		})
 		if err != nil {
 			msg := "generating scrape config failed for PodMonitoring endpoint"
 			cond = &monitoringv1.MonitoringCondition{
@@ -394,7 +517,11 @@ func (r *collectionReconciler) makeCollectorConfig(ctx context.Context, spec *mo
 			}
 			logger.Error(err, msg, "namespace", pmon.Namespace, "name", pmon.Name)
 		} else {
-			cfg.ScrapeConfigs = append(cfg.ScrapeConfigs, cfgs...)
+			cfg.ScrapeConfigs = append(cfg.ScrapeConfigs, applyQuota(quota, res.configs)...)
+			quotaEndpoints[pmon.Namespace] = endpoints
+			for ref, c := range res.secrets {
+				usedSecrets.Set(ref, c)
+			}
 		}
 
 		updateStatus := pmon.Status.SetMonitoringCondition(pmon.GetGeneration(), metav1.Now(), cond)
@@ -416,7 +543,11 @@ func (r *collectionReconciler) makeCollectorConfig(ctx context.Context, spec *mo
 			Type:   monitoringv1.ConfigurationCreateSuccess,
 			Status: corev1.ConditionTrue,
 		}
-		cfgs, err := cmon.ScrapeConfigs(projectID, location, cluster, usedSecrets)
+		res, err := r.scrapeConfigs.get(ctx, &cmon, env, func() (generatedScrapeConfigs, error) {
+			pool := monitoringv1.PrometheusSecretConfigs{}
+			cfgs, err := cmon.ScrapeConfigs(projectID, location, cluster, pool)
+			return generatedScrapeConfigs{configs: cfgs, secrets: pool}, err
+		})
 		if err != nil {
 			msg := "generating scrape config failed for ClusterPodMonitoring endpoint"
 			cond = &monitoringv1.MonitoringCondition{
@@ -426,8 +557,26 @@ func (r *collectionReconciler) makeCollectorConfig(ctx context.Context, spec *mo
 				Message: msg,
 			}
 			logger.Error(err, msg, "namespace", cmon.Namespace, "name", cmon.Name)
+		} else if ns, err := nsFilter.deniedSecretNamespace(ctx, res.secrets); err != nil {
+			return nil, nil, err
+		} else if ns != "" {
+			// Secrets must not be read from namespaces the operator is configured to ignore.
+			cond = &monitoringv1.MonitoringCondition{
+				Type:    monitoringv1.ConfigurationCreateSuccess,
+				Status:  corev1.ConditionFalse,
+				Reason:  reasonNamespaceIgnored,
+				Message: fmt.Sprintf("referenced secrets in namespace %q are excluded by the namespaces filter of the OperatorConfig", ns),
+			}
 		} else {
+			// Targets in namespaces the operator is configured to ignore must not be scraped.
+			cfgs, err := nsFilter.filterScrapeConfigs(ctx, res.configs)
+			if err != nil {
+				return nil, nil, err
+			}
 			cfg.ScrapeConfigs = append(cfg.ScrapeConfigs, cfgs...)
+			for ref, c := range res.secrets {
+				usedSecrets.Set(ref, c)
+			}
 		}
 
 		updateStatus := cmon.Status.SetMonitoringCondition(cmon.GetGeneration(), metav1.Now(), cond)
@@ -446,12 +595,6 @@ func (r *collectionReconciler) makeCollectorConfig(ctx context.Context, spec *mo
 	if err := r.client.List(ctx, &clusterNodeMons); err != nil {
 		return nil, nil, fmt.Errorf("failed to list ClusterNodeMonitorings: %w", err)
 	}
-	// The following job names are reserved by GMP for ClusterNodeMonitoring in the
-	// gmp-system namespace. They will not be generated if kubeletScraping is enabled.
-	var (
-		reservedCAdvisorJobName = "gmp-kubelet-cadvisor"
-		reservedKubeletJobName  = "gmp-kubelet-metrics"
-	)
 	// Mark status updates in batch with single timestamp.
 	for _, cnmon := range clusterNodeMons.Items {
 		if spec.KubeletScraping != nil && (cnmon.Name == reservedKubeletJobName || cnmon.Name == reservedCAdvisorJobName) {
@@ -462,7 +605,10 @@ func (r *collectionReconciler) makeCollectorConfig(ctx context.Context, spec *mo
 			Type:   monitoringv1.ConfigurationCreateSuccess,
 			Status: corev1.ConditionTrue,
 		}
-		cfgs, err := cnmon.ScrapeConfigs(projectID, location, cluster)
+		res, err := r.scrapeConfigs.get(ctx, &cnmon, env, func() (generatedScrapeConfigs, error) {
+			cfgs, err := cnmon.ScrapeConfigs(projectID, location, cluster)
+			return generatedScrapeConfigs{configs: cfgs}, err
+		})
 		if err != nil {
 			msg := "generating scrape config failed for ClusterNodeMonitoring endpoint"
 			cond = &monitoringv1.MonitoringCondition{
@@ -473,7 +619,7 @@ func (r *collectionReconciler) makeCollectorConfig(ctx context.Context, spec *mo
 			}
 			logger.Error(err, msg, "namespace", cnmon.Namespace, "name", cnmon.Name)
 		} else {
-			cfg.ScrapeConfigs = append(cfg.ScrapeConfigs, cfgs...)
+			cfg.ScrapeConfigs = append(cfg.ScrapeConfigs, res.configs...)
 		}
 		if cnmon.Status.SetMonitoringCondition(cnmon.GetGeneration(), metav1.Now(), cond) {
 			updates = append(updates, update{
@@ -483,6 +629,9 @@ func (r *collectionReconciler) makeCollectorConfig(ctx context.Context, spec *mo
 		}
 	}
 
+	// All current objects were requested, so remaining entries belong to deleted objects.
+	r.scrapeConfigs.prune()
+
 	// Sort to ensure reproducible configs.
 	slices.SortFunc(cfg.ScrapeConfigs, func(a, b *promconfig.ScrapeConfig) int {
 		return cmp.Compare(a.JobName, b.JobName)
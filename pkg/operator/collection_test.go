@@ -60,7 +60,10 @@ func newFakeClientBuilder() *fake.ClientBuilder {
 		WithStatusSubresource(&monitoringv1.ClusterNodeMonitoring{}).
 		WithStatusSubresource(&monitoringv1.Rules{}).
 		WithStatusSubresource(&monitoringv1.ClusterRules{}).
-		WithStatusSubresource(&monitoringv1.GlobalRules{})
+		WithStatusSubresource(&monitoringv1.GlobalRules{}).
+		WithStatusSubresource(&monitoringv1.OperatorConfig{}).
+		WithStatusSubresource(&monitoringv1.AlertRouting{}).
+		WithStatusSubresource(&monitoringv1.Silence{})
 }
 
 func TestCollectionReconcile(t *testing.T) {
@@ -0,0 +1,146 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	configCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "prometheus_engine_config_cache_requests_total",
+		Help: "Number of lookups of configuration generated for a single object, by cache and result.",
+	}, []string{"cache", "result"})
+
+	configGenerationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "prometheus_engine_config_generation_duration_seconds",
+		Help:    "Duration of generating and writing a configuration, by configuration.",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"config"})
+)
+
+func registerConfigMetrics(registry prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{configCacheRequests, configGenerationDuration} {
+		if err := registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configCacheKey identifies the inputs of the configuration generated for an object.
+type configCacheKey struct {
+	uid        types.UID
+	generation int64
+	// env holds inputs shared by all objects, such as the resolved project, location and cluster.
+	env string
+	// secrets holds the resource versions of the secrets referenced by the generated configuration.
+	secrets string
+}
+
+type configCacheEntry[T any] struct {
+	key   configCacheKey
+	value T
+	err   error
+	// used indicates whether the entry was requested since the last prune.
+	used bool
+}
+
+// configCache holds the configuration generated for individual objects so that only objects
+// that changed are regenerated.
+type configCache[T any] struct {
+	name string
+	// secretVersions returns the resource versions of the secrets referenced by a generated
+	// configuration, if it can reference any.
+	secretVersions func(context.Context, T) string
+
+	mu      sync.Mutex
+	entries map[types.UID]*configCacheEntry[T]
+}
+
+func newConfigCache[T any](name string) *configCache[T] {
+	return &configCache[T]{
+		name:    name,
+		entries: map[types.UID]*configCacheEntry[T]{},
+	}
+}
+
+// get returns the cached result for the object or generates and caches it. Errors are cached as
+// well, since generation is deterministic for the same inputs. Objects without a UID or
+// generation, as returned by fake clients, are never cached. A nil cache disables caching.
+func (c *configCache[T]) get(ctx context.Context, obj client.Object, env string, generate func() (T, error)) (T, error) {
+	if c == nil || obj.GetUID() == "" || obj.GetGeneration() == 0 {
+		return generate()
+	}
+	key := configCacheKey{
+		uid:        obj.GetUID(),
+		generation: obj.GetGeneration(),
+		env:        env,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key.uid]; ok {
+		// The referenced secrets are only known from the generated configuration.
+		key.secrets = c.getSecretVersions(ctx, e.value)
+		if e.key == key {
+			e.used = true
+			configCacheRequests.WithLabelValues(c.name, "hit").Inc()
+			return e.value, e.err
+		}
+	}
+	configCacheRequests.WithLabelValues(c.name, "miss").Inc()
+
+	value, err := generate()
+	key.secrets = c.getSecretVersions(ctx, value)
+	c.entries[key.uid] = &configCacheEntry[T]{
+		key:   key,
+		value: value,
+		err:   err,
+		used:  true,
+	}
+	return value, err
+}
+
+func (c *configCache[T]) getSecretVersions(ctx context.Context, value T) string {
+	if c.secretVersions == nil {
+		return ""
+	}
+	return c.secretVersions(ctx, value)
+}
+
+// prune drops the entries of all objects that weren't requested since the last prune, i.e.
+// objects that were deleted.
+func (c *configCache[T]) prune() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for uid, e := range c.entries {
+		if !e.used {
+			delete(c.entries, uid)
+			continue
+		}
+		e.used = false
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/testr"
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	yaml "gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+func TestConfigCache(t *testing.T) {
+	cache := newConfigCache[string]("test")
+	var calls int
+	generate := func(value string, err error) func() (string, error) {
+		return func() (string, error) {
+			calls++
+			return value, err
+		}
+	}
+	obj := &monitoringv1.Rules{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "rules", UID: "uid-1", Generation: 1},
+	}
+
+	steps := []struct {
+		desc      string
+		mutate    func()
+		env       string
+		generate  func() (string, error)
+		want      string
+		wantErr   bool
+		wantCalls int
+	}{
+		{
+			desc:      "initial miss",
+			env:       "env",
+			generate:  generate("a", nil),
+			want:      "a",
+			wantCalls: 1,
+		},
+		{
+			desc:      "hit",
+			env:       "env",
+			generate:  generate("b", nil),
+			want:      "a",
+			wantCalls: 1,
+		},
+		{
+			desc:      "generation changed",
+			mutate:    func() { obj.Generation = 2 },
+			env:       "env",
+			generate:  generate("b", nil),
+			want:      "b",
+			wantCalls: 2,
+		},
+		{
+			desc:      "environment changed",
+			env:       "other-env",
+			generate:  generate("c", nil),
+			want:      "c",
+			wantCalls: 3,
+		},
+		{
+			desc:      "error is cached",
+			mutate:    func() { obj.Generation = 3 },
+			env:       "other-env",
+			generate:  generate("", errors.New("invalid")),
+			wantErr:   true,
+			wantCalls: 4,
+		},
+		{
+			desc:      "cached error",
+			env:       "other-env",
+			generate:  generate("d", nil),
+			wantErr:   true,
+			wantCalls: 4,
+		},
+		{
+			desc:      "no UID bypasses cache",
+			mutate:    func() { obj.UID = "" },
+			env:       "other-env",
+			generate:  generate("e", nil),
+			want:      "e",
+			wantCalls: 5,
+		},
+	}
+	for _, step := range steps {
+		if step.mutate != nil {
+			step.mutate()
+		}
+		got, err := cache.get(t.Context(), obj, step.env, step.generate)
+		if (err != nil) != step.wantErr {
+			t.Fatalf("%s: unexpected error: %v", step.desc, err)
+		}
+		if got != step.want {
+			t.Errorf("%s: expected %q, got %q", step.desc, step.want, got)
+		}
+		if calls != step.wantCalls {
+			t.Errorf("%s: expected %d generate calls, got %d", step.desc, step.wantCalls, calls)
+		}
+	}
+}
+
+func TestConfigCachePrune(t *testing.T) {
+	cache := newConfigCache[string]("test")
+	a := &monitoringv1.Rules{ObjectMeta: metav1.ObjectMeta{Name: "a", UID: "uid-a", Generation: 1}}
+	b := &monitoringv1.Rules{ObjectMeta: metav1.ObjectMeta{Name: "b", UID: "uid-b", Generation: 1}}
+	gen := func() (string, error) { return "", nil }
+
+	for _, obj := range []*monitoringv1.Rules{a, b} {
+		if _, err := cache.get(t.Context(), obj, "", gen); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cache.prune()
+	if len(cache.entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(cache.entries))
+	}
+
+	// Only a is requested in the next pass, so b was deleted.
+	if _, err := cache.get(t.Context(), a, "", gen); err != nil {
+		t.Fatal(err)
+	}
+	cache.prune()
+	if _, ok := cache.entries["uid-b"]; ok || len(cache.entries) != 1 {
+		t.Errorf("expected only entry of a to remain, got %v", cache.entries)
+	}
+
+	// A nil cache disables caching.
+	var nilCache *configCache[string]
+	nilCache.prune()
+	if _, err := nilCache.get(t.Context(), a, "", gen); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCollectorConfigCached(t *testing.T) {
+	ctx := logr.NewContext(t.Context(), testr.New(t))
+	opts := Options{
+		ProjectID: "test-proj",
+		Location:  "us-central1",
+		Cluster:   "test-cluster",
+	}
+	if err := opts.defaultAndValidate(logr.Discard()); err != nil {
+		t.Fatal(err)
+	}
+	podMon := &monitoringv1.PodMonitoring{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "app", UID: "uid-1", Generation: 1},
+		Spec: monitoringv1.PodMonitoringSpec{
+			Endpoints: []monitoringv1.ScrapeEndpoint{{
+				Port:     intstr.FromString("metrics"),
+				Interval: "10s",
+			}},
+		},
+	}
+	c := newFakeClientBuilder().WithObjects(podMon).Build()
+	r := newCollectionReconciler(c, opts)
+	spec := &monitoringv1.CollectionSpec{}
+
+	hits := testutil.ToFloat64(configCacheRequests.WithLabelValues("collector", "hit"))
+	first, _, err := r.makeCollectorConfig(ctx, spec, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _, err := r.makeCollectorConfig(ctx, spec, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(marshalConfig(t, first), marshalConfig(t, second)); diff != "" {
+		t.Errorf("unexpected config from cache (-want, +got): %s", diff)
+	}
+	if got := testutil.ToFloat64(configCacheRequests.WithLabelValues("collector", "hit")) - hits; got != 1 {
+		t.Errorf("expected 1 cache hit, got %v", got)
+	}
+
+	// Changed external labels must not be served from the cache.
+	spec.ExternalLabels = map[string]string{"cluster": "other-cluster"}
+	third, _, err := r.makeCollectorConfig(ctx, spec, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if marshalConfig(t, first) == marshalConfig(t, third) {
+		t.Error("expected scrape configs to change with external labels")
+	}
+}
+
+func TestCollectorConfigCachedSecretVersions(t *testing.T) {
+	ctx := logr.NewContext(t.Context(), testr.New(t))
+	opts := Options{
+		ProjectID: "test-proj",
+		Location:  "us-central1",
+		Cluster:   "test-cluster",
+	}
+	if err := opts.defaultAndValidate(logr.Discard()); err != nil {
+		t.Fatal(err)
+	}
+	podMon := &monitoringv1.PodMonitoring{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "app", UID: "uid-1", Generation: 1},
+		Spec: monitoringv1.PodMonitoringSpec{
+			Endpoints: []monitoringv1.ScrapeEndpoint{{
+				Port:     intstr.FromString("metrics"),
+				Interval: "10s",
+				HTTPClientConfig: monitoringv1.HTTPClientConfig{
+					Authorization: &monitoringv1.Auth{
+						Credentials: &monitoringv1.SecretSelector{
+							Secret: &monitoringv1.SecretKeySelector{Name: "token", Key: "token"},
+						},
+					},
+				},
+			}},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "token"},
+		StringData: map[string]string{"token": "a"},
+	}
+	c := newFakeClientBuilder().WithObjects(podMon, secret).Build()
+	r := newCollectionReconciler(c, opts)
+	spec := &monitoringv1.CollectionSpec{}
+
+	requests := func(result string) float64 {
+		return testutil.ToFloat64(configCacheRequests.WithLabelValues("collector", result))
+	}
+	for range 2 {
+		if _, _, err := r.makeCollectorConfig(ctx, spec, nil, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A changed secret must not be served from the cache.
+	misses := requests("miss")
+	secret.StringData = map[string]string{"token": "b"}
+	if err := c.Update(ctx, secret); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := r.makeCollectorConfig(ctx, spec, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := requests("miss") - misses; got != 1 {
+		t.Errorf("expected 1 cache miss after the secret changed, got %v", got)
+	}
+
+	hits := requests("hit")
+	if _, _, err := r.makeCollectorConfig(ctx, spec, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := requests("hit") - hits; got != 1 {
+		t.Errorf("expected 1 cache hit for the unchanged secret, got %v", got)
+	}
+}
+
+func marshalConfig(t *testing.T, cfg any) string {
+	t.Helper()
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/GoogleCloudPlatform/prometheus-engine/pkg/configshard"
+)
+
+// labelConfigShardOf is set on shard ConfigMaps to the name of the ConfigMap holding their manifest.
+const labelConfigShardOf = "monitoring.googleapis.com/config-shard-of"
+
+// maxConfigMapDataSize is the data size above which generated configuration is split across
+// multiple ConfigMaps. It leaves headroom for metadata and encoding below the 1 MiB object
+// size limit.
+var maxConfigMapDataSize = 768 * 1024
+
+// maxConfigMapShardSize returns the raw data size of a shard ConfigMap. Shards hold their data
+// in binaryData, which is base64-encoded in the stored object, so their raw data is limited
+// such that the encoded data does not exceed maxConfigMapDataSize.
+func maxConfigMapShardSize() int {
+	return base64.StdEncoding.DecodedLen(maxConfigMapDataSize)
+}
+
+// writeConfigMap creates or updates a generated ConfigMap. If its data is too large for a
+// single object, the data is moved into immutable shard ConfigMaps and the ConfigMap only holds
+// a manifest that the config-reloader uses to reassemble the files.
+func writeConfigMap(ctx context.Context, c client.Client, cm *corev1.ConfigMap) error {
+	logger, _ := logr.FromContext(ctx)
+
+	// Config-reloaders may still be reading the shards of the manifest we are about to replace,
+	// so those are only deleted with the next change.
+	keep := map[string]bool{}
+	var prev corev1.ConfigMap
+	if err := c.Get(ctx, client.ObjectKeyFromObject(cm), &prev); err == nil {
+		if data, ok := prev.Data[configshard.ManifestKey]; ok {
+			m, err := configshard.ParseManifest([]byte(data))
+			if err != nil {
+				logger.Error(err, "parse previous shard manifest", "configmap", cm.Name)
+			} else {
+				for _, name := range m.ConfigMaps() {
+					keep[name] = true
+				}
+			}
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("get ConfigMap %q: %w", cm.Name, err)
+	}
+
+	if size := configMapDataSize(cm); size > maxConfigMapDataSize {
+		files := map[string][]byte{}
+		for k, v := range cm.Data {
+			files[k] = []byte(v)
+		}
+		maps.Copy(files, cm.BinaryData)
+
+		m, shards, err := configshard.Split(cm.Namespace, cm.Name, files, maxConfigMapShardSize())
+		if err != nil {
+			return fmt.Errorf("split ConfigMap %q: %w", cm.Name, err)
+		}
+		for name, data := range shards {
+			keep[name] = true
+
+			labels := map[string]string{labelConfigShardOf: cm.Name}
+			maps.Copy(labels, cm.Labels)
+			shard := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: cm.Namespace,
+					Name:      name,
+					Labels:    labels,
+				},
+				Immutable:  ptr.To(true),
+				BinaryData: data,
+			}
+			// Shards are content-addressed, so an existing shard already has the expected data.
+			if err := c.Create(ctx, shard); err != nil && !apierrors.IsAlreadyExists(err) {
+				return fmt.Errorf("create ConfigMap shard %q: %w", name, err)
+			}
+		}
+		manifest, err := json.Marshal(m)
+		if err != nil {
+			return fmt.Errorf("marshal shard manifest: %w", err)
+		}
+		logger.Info("ConfigMap exceeds size limit, splitting into shards", "configmap", cm.Name, "size", size, "shards", len(shards))
+
+		cm.Data = map[string]string{configshard.ManifestKey: string(manifest)}
+		cm.BinaryData = nil
+	}
+
+	if err := c.Update(ctx, cm); apierrors.IsNotFound(err) {
+		if err := c.Create(ctx, cm); err != nil {
+			return fmt.Errorf("create ConfigMap %q: %w", cm.Name, err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("update ConfigMap %q: %w", cm.Name, err)
+	}
+
+	var shards corev1.ConfigMapList
+	if err := c.List(ctx, &shards, client.InNamespace(cm.Namespace), client.MatchingLabels{labelConfigShardOf: cm.Name}); err != nil {
+		return fmt.Errorf("list ConfigMap shards: %w", err)
+	}
+	for i := range shards.Items {
+		shard := &shards.Items[i]
+		if keep[shard.Name] {
+			continue
+		}
+		if err := c.Delete(ctx, shard); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("delete ConfigMap shard %q: %w", shard.Name, err)
+		}
+	}
+	return nil
+}
+
+func configMapDataSize(cm *corev1.ConfigMap) int {
+	var size int
+	for k, v := range cm.Data {
+		size += len(k) + len(v)
+	}
+	for k, v := range cm.BinaryData {
+		size += len(k) + len(v)
+	}
+	return size
+}
@@ -0,0 +1,180 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/GoogleCloudPlatform/prometheus-engine/pkg/configshard"
+)
+
+func TestWriteConfigMapShards(t *testing.T) {
+	prevMax := maxConfigMapDataSize
+	maxConfigMapDataSize = 1000
+	t.Cleanup(func() { maxConfigMapDataSize = prevMax })
+
+	ctx := t.Context()
+	c := newFakeClientBuilder().Build()
+
+	newConfigMap := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: DefaultOperatorNamespace,
+				Name:      NameCollector,
+			},
+			Data: data,
+		}
+	}
+	listShards := func() []string {
+		var cms corev1.ConfigMapList
+		if err := c.List(ctx, &cms, client.MatchingLabels{labelConfigShardOf: NameCollector}); err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, cm := range cms.Items {
+			if cm.Immutable == nil || !*cm.Immutable {
+				t.Errorf("expected shard %q to be immutable", cm.Name)
+			}
+			names = append(names, cm.Name)
+		}
+		slices.Sort(names)
+		return names
+	}
+	assemble := func() (map[string][]byte, []string) {
+		var cm corev1.ConfigMap
+		if err := c.Get(ctx, client.ObjectKey{Namespace: DefaultOperatorNamespace, Name: NameCollector}, &cm); err != nil {
+			t.Fatal(err)
+		}
+		m, err := configshard.ParseManifest([]byte(cm.Data[configshard.ManifestKey]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files, err := configshard.Assemble(ctx, m, func(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+			var shard corev1.ConfigMap
+			err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &shard)
+			return &shard, err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return files, m.ConfigMaps()
+	}
+
+	// A small config is written as-is.
+	if err := writeConfigMap(ctx, c, newConfigMap(map[string]string{configFilename: "small"})); err != nil {
+		t.Fatal(err)
+	}
+	var cm corev1.ConfigMap
+	if err := c.Get(ctx, client.ObjectKey{Namespace: DefaultOperatorNamespace, Name: NameCollector}, &cm); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]string{configFilename: "small"}, cm.Data); diff != "" {
+		t.Errorf("unexpected data (-want, +got): %s", diff)
+	}
+	if shards := listShards(); len(shards) != 0 {
+		t.Errorf("expected no shards, got %v", shards)
+	}
+
+	// A large config is split across shards.
+	first := strings.Repeat("a", 2500)
+	if err := writeConfigMap(ctx, c, newConfigMap(map[string]string{configFilename: first})); err != nil {
+		t.Fatal(err)
+	}
+	files, firstShards := assemble()
+	if diff := cmp.Diff(first, string(files[configFilename])); diff != "" {
+		t.Errorf("unexpected assembled config (-want, +got): %s", diff)
+	}
+	if len(firstShards) != 4 {
+		t.Errorf("expected 4 shards, got %v", firstShards)
+	}
+	if diff := cmp.Diff(firstShards, listShards()); diff != "" {
+		t.Errorf("unexpected shards (-want, +got): %s", diff)
+	}
+
+	// Shards of the previous manifest are retained for config-reloaders that may still read them.
+	second := strings.Repeat("b", 2500)
+	if err := writeConfigMap(ctx, c, newConfigMap(map[string]string{configFilename: second})); err != nil {
+		t.Fatal(err)
+	}
+	files, secondShards := assemble()
+	if diff := cmp.Diff(second, string(files[configFilename])); diff != "" {
+		t.Errorf("unexpected assembled config (-want, +got): %s", diff)
+	}
+	want := slices.Sorted(slices.Values(append(slices.Clone(firstShards), secondShards...)))
+	if diff := cmp.Diff(want, listShards()); diff != "" {
+		t.Errorf("unexpected shards (-want, +got): %s", diff)
+	}
+
+	// Once the config fits again, stale shards are cleaned up.
+	if err := writeConfigMap(ctx, c, newConfigMap(map[string]string{configFilename: "small"})); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(secondShards, listShards()); diff != "" {
+		t.Errorf("unexpected shards (-want, +got): %s", diff)
+	}
+	if err := writeConfigMap(ctx, c, newConfigMap(map[string]string{configFilename: "small"})); err != nil {
+		t.Fatal(err)
+	}
+	if shards := listShards(); len(shards) != 0 {
+		t.Errorf("expected no shards, got %v", shards)
+	}
+}
+
+func TestWriteConfigMapShardsEncodedSize(t *testing.T) {
+	ctx := t.Context()
+	c := newFakeClientBuilder().Build()
+
+	// Configs well beyond the limit, with files that do and don't fit into a single shard.
+	data := map[string]string{
+		configFilename: strings.Repeat("scrape_configs: []\n", 200_000),
+		"rules.yaml":   strings.Repeat("groups: []\n", 50_000),
+	}
+	if err := writeConfigMap(ctx, c, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: DefaultOperatorNamespace,
+			Name:      NameCollector,
+		},
+		Data: data,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var cms corev1.ConfigMapList
+	if err := c.List(ctx, &cms, client.MatchingLabels{labelConfigShardOf: NameCollector}); err != nil {
+		t.Fatal(err)
+	}
+	if len(cms.Items) < 2 {
+		t.Fatalf("expected multiple shards, got %d", len(cms.Items))
+	}
+	for _, cm := range cms.Items {
+		b, err := json.Marshal(cm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Leave headroom for metadata and the request below the 1 MiB object size limit.
+		if limit := maxConfigMapDataSize + 16*1024; len(b) > limit {
+			t.Errorf("encoded shard %q has %d bytes, expected at most %d", cm.Name, len(b), limit)
+		}
+	}
+}
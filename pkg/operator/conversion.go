@@ -0,0 +1,142 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+	monitoringv1alpha1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1alpha1"
+)
+
+// convertPath is the path of the conversion webhook for all CRDs serving multiple versions.
+const convertPath = "/convert"
+
+// convertibleCRDs are the CRDs served in v1alpha1 and v1, which are converted by the operator.
+// OperatorConfigs only exist in the public namespace.
+var convertibleCRDs = []struct {
+	name      string
+	namespace func(*Options) string
+}{
+	{name: "podmonitorings.monitoring.googleapis.com"},
+	{name: "clusterpodmonitorings.monitoring.googleapis.com"},
+	{name: "rules.monitoring.googleapis.com"},
+	{name: "clusterrules.monitoring.googleapis.com"},
+	{name: "globalrules.monitoring.googleapis.com"},
+	{
+		name:      "operatorconfigs.monitoring.googleapis.com",
+		namespace: func(opts *Options) string { return opts.PublicNamespace },
+	},
+}
+
+// setConversionWebhookCABundle points the conversion webhooks of the convertible CRDs to the
+// operator and sets their CA bundle.
+func setConversionWebhookCABundle(ctx context.Context, kubeClient client.Client, namespace string, caBundle []byte) error {
+	for _, c := range convertibleCRDs {
+		var crd apiextensionsv1.CustomResourceDefinition
+		err := kubeClient.Get(ctx, client.ObjectKey{Name: c.name}, &crd)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		conv := crd.Spec.Conversion
+		if conv == nil || conv.Strategy != apiextensionsv1.WebhookConverter || conv.Webhook == nil || conv.Webhook.ClientConfig == nil {
+			continue
+		}
+		conv.Webhook.ClientConfig.CABundle = caBundle
+		if svc := conv.Webhook.ClientConfig.Service; svc != nil {
+			svc.Namespace = namespace
+		}
+		if err := kubeClient.Update(ctx, &crd); err != nil {
+			return fmt.Errorf("update CRD %s: %w", c.name, err)
+		}
+	}
+	return nil
+}
+
+// setupStorageVersionMigration migrates objects still stored as v1alpha1 once the operator
+// becomes the leader.
+func setupStorageVersionMigration(op *Operator) error {
+	err := op.manager.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		logger := op.logger.WithName("storage-version-migration")
+		for {
+			err := migrateStorageVersions(ctx, logger, op.client, &op.opts)
+			if err == nil {
+				return nil
+			}
+			logger.Error(err, "migrating storage versions failed; retrying in 1m...")
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Minute):
+			}
+		}
+	}))
+	if err != nil {
+		return fmt.Errorf("add storage version migration: %w", err)
+	}
+	return nil
+}
+
+// migrateStorageVersions rewrites all objects of convertible CRDs that list v1alpha1 as a
+// stored version, which stores them as v1. Afterwards v1alpha1 is removed from the stored
+// versions, so that it can eventually be removed from the CRDs.
+func migrateStorageVersions(ctx context.Context, logger logr.Logger, kubeClient client.Client, opts *Options) error {
+	for _, c := range convertibleCRDs {
+		var crd apiextensionsv1.CustomResourceDefinition
+		err := kubeClient.Get(ctx, client.ObjectKey{Name: c.name}, &crd)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if !slices.Contains(crd.Status.StoredVersions, monitoringv1alpha1.Version) {
+			continue
+		}
+		var listOpts []client.ListOption
+		if c.namespace != nil {
+			listOpts = append(listOpts, client.InNamespace(c.namespace(opts)))
+		}
+		var list unstructured.UnstructuredList
+		list.SetGroupVersionKind(monitoringv1.SchemeGroupVersion.WithKind(crd.Spec.Names.ListKind))
+		if err := kubeClient.List(ctx, &list, listOpts...); err != nil {
+			return fmt.Errorf("list %s: %w", crd.Spec.Names.Plural, err)
+		}
+		for i := range list.Items {
+			// An update without changes writes the object in the current storage version. A
+			// conflict means it was written concurrently, which stored it as well.
+			if err := kubeClient.Update(ctx, &list.Items[i]); err != nil && !apierrors.IsConflict(err) && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("update %s %s: %w", crd.Spec.Names.Kind, client.ObjectKeyFromObject(&list.Items[i]), err)
+			}
+		}
+		crd.Status.StoredVersions = []string{monitoringv1.Version}
+		if err := kubeClient.Status().Update(ctx, &crd); err != nil {
+			return fmt.Errorf("update stored versions of CRD %s: %w", c.name, err)
+		}
+		logger.Info("migrated storage version", "crd", c.name, "objects", len(list.Items))
+	}
+	return nil
+}
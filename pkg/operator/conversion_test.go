@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+func TestConversionWebhook(t *testing.T) {
+	opts := Options{
+		OperatorNamespace: "gmp-system-test",
+		PublicNamespace:   "gmp-public",
+	}
+	crd := func(name, kind string, storedVersions ...string) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: kind, ListKind: kind + "List"},
+				Conversion: &apiextensionsv1.CustomResourceConversion{
+					Strategy: apiextensionsv1.WebhookConverter,
+					Webhook: &apiextensionsv1.WebhookConversion{
+						ClientConfig: &apiextensionsv1.WebhookClientConfig{
+							Service: &apiextensionsv1.ServiceReference{Namespace: "gmp-system", Name: NameOperator},
+						},
+					},
+				},
+			},
+			Status: apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: storedVersions},
+		}
+	}
+	pm := &monitoringv1.PodMonitoring{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+	}
+	c := newFakeClientBuilder().
+		WithStatusSubresource(&apiextensionsv1.CustomResourceDefinition{}).
+		WithObjects(
+			crd("podmonitorings.monitoring.googleapis.com", "PodMonitoring", "v1alpha1", "v1"),
+			crd("operatorconfigs.monitoring.googleapis.com", "OperatorConfig", "v1"),
+			pm,
+		).Build()
+
+	t.Run("ca bundle", func(t *testing.T) {
+		caBundle := []byte("ca")
+		if err := setConversionWebhookCABundle(t.Context(), c, opts.OperatorNamespace, caBundle); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"podmonitorings.monitoring.googleapis.com", "operatorconfigs.monitoring.googleapis.com"} {
+			var got apiextensionsv1.CustomResourceDefinition
+			if err := c.Get(t.Context(), client.ObjectKey{Name: name}, &got); err != nil {
+				t.Fatal(err)
+			}
+			clientConfig := got.Spec.Conversion.Webhook.ClientConfig
+			if !bytes.Equal(clientConfig.CABundle, caBundle) {
+				t.Errorf("%s: expected CA bundle %q, got %q", name, caBundle, clientConfig.CABundle)
+			}
+			if clientConfig.Service.Namespace != opts.OperatorNamespace {
+				t.Errorf("%s: expected service namespace %q, got %q", name, opts.OperatorNamespace, clientConfig.Service.Namespace)
+			}
+		}
+	})
+
+	t.Run("storage version migration", func(t *testing.T) {
+		var before monitoringv1.PodMonitoring
+		if err := c.Get(t.Context(), client.ObjectKeyFromObject(pm), &before); err != nil {
+			t.Fatal(err)
+		}
+		if err := migrateStorageVersions(t.Context(), logr.Discard(), c, &opts); err != nil {
+			t.Fatal(err)
+		}
+		var got apiextensionsv1.CustomResourceDefinition
+		if err := c.Get(t.Context(), client.ObjectKey{Name: "podmonitorings.monitoring.googleapis.com"}, &got); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(got.Status.StoredVersions, []string{"v1"}) {
+			t.Errorf("expected stored versions [v1], got %v", got.Status.StoredVersions)
+		}
+		var after monitoringv1.PodMonitoring
+		if err := c.Get(t.Context(), client.ObjectKeyFromObject(pm), &after); err != nil {
+			t.Fatal(err)
+		}
+		if after.ResourceVersion == before.ResourceVersion {
+			t.Errorf("expected PodMonitoring to be rewritten, resource version is still %q", after.ResourceVersion)
+		}
+	})
+}
@@ -101,6 +101,11 @@ func getObjectByScrapeJobKey(key string) (monitoringv1.PodMonitoringCRD, error)
 			return nil, fmt.Errorf("invalid kubelet scrape key format %q", key)
 		}
 		return nil, nil
+	case selfMonitoringJobPrefix:
+		if len(split) != 1 {
+			return nil, fmt.Errorf("invalid self-monitoring scrape key format %q", key)
+		}
+		return nil, nil
 	case "PodMonitoring":
 		return setNamespacedObjectByScrapeJobKey(&monitoringv1.PodMonitoring{}, split, key)
 	case "ClusterPodMonitoring":
@@ -153,6 +158,14 @@ func parseScrapePool(pool string) (scrapePool, error) {
 			key:   split[0],
 			group: split[1],
 		}, nil
+	case selfMonitoringJobPrefix:
+		if len(split) != 3 {
+			return scrapePool{}, fmt.Errorf("invalid self-monitoring scrape pool format %q", pool)
+		}
+		return scrapePool{
+			key:   split[0],
+			group: split[1] + "/" + split[2],
+		}, nil
 	case "PodMonitoring":
 		if len(split) != 4 {
 			return scrapePool{}, fmt.Errorf("invalid PodMonitoring scrape pool format %q", pool)
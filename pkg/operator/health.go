@@ -0,0 +1,619 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	yaml "gopkg.in/yaml.v3"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+const (
+	// healthPollInterval is how often component health is re-evaluated in the absence
+	// of changes to the watched resources.
+	healthPollInterval = time.Minute
+	// healthMetricsTimeout is the timeout of fetching the metrics of a single pod.
+	healthMetricsTimeout = 10 * time.Second
+	// webhookCertExpiryWarning is the remaining validity of the webhook serving certificate
+	// below which it is reported as unhealthy. Managed certificates are renewed well before.
+	webhookCertExpiryWarning = 7 * 24 * time.Hour
+)
+
+// Reasons of the OperatorConfig conditions.
+const (
+	reasonHealthy          = "Healthy"
+	reasonUnhealthy        = "ComponentsUnhealthy"
+	reasonNotFound         = "NotFound"
+	reasonRolloutProgress  = "RolloutInProgress"
+	reasonRolloutComplete  = "RolloutComplete"
+	reasonConfigLoaded     = "ConfigLoaded"
+	reasonConfigNotLoaded  = "ConfigNotLoaded"
+	reasonReloadFailed     = "ReloadFailed"
+	reasonScaledDown       = "ScaledDown"
+	reasonSendErrors       = "SendErrors"
+	reasonNoSendErrors     = "NoSendErrors"
+	reasonConfigValid      = "ConfigValid"
+	reasonConfigInvalid    = "ConfigInvalid"
+	reasonCertificateValid = "CertificateValid"
+	reasonCertificateError = "CertificateError"
+	reasonCertificateSoon  = "CertificateExpiringSoon"
+	reasonNotApplicable    = "NotApplicable"
+)
+
+// Metrics read from the components to determine their health.
+const (
+	metricReloadSuccessful = "reloader_last_reload_successful"
+	metricConfigHash       = "config_reloader_config_hash_info"
+	metricSendErrors       = "gcm_export_samples_send_errors_total"
+)
+
+// Responsible for fetching the metrics of a pod at the given port.
+type getMetricsFn func(ctx context.Context, httpClient *http.Client, pod *corev1.Pod, port int32) (map[string]*dto.MetricFamily, error)
+
+// podMetricsKey identifies the metrics of a pod at a named container port.
+type podMetricsKey struct {
+	uid  types.UID
+	port string
+}
+
+// healthState holds the running pods of the components and their metrics, which are
+// fetched once per evaluation.
+type healthState struct {
+	collectorPods     []*corev1.Pod
+	ruleEvaluatorPods []*corev1.Pod
+	alertmanagerPods  []*corev1.Pod
+	// metrics holds the metrics of each pod and port that could be fetched.
+	metrics map[podMetricsKey]map[string]*dto.MetricFamily
+}
+
+// podMetrics returns the metrics of the pod at the named port, or false if they could not
+// be fetched.
+func (s *healthState) podMetrics(pod *corev1.Pod, portName string) (map[string]*dto.MetricFamily, bool) {
+	mfs, ok := s.metrics[podMetricsKey{uid: pod.UID, port: portName}]
+	return mfs, ok
+}
+
+// healthReconciler reports the health of the managed collection components in the
+// status conditions of the OperatorConfig.
+type healthReconciler struct {
+	client     client.Client
+	opts       Options
+	httpClient *http.Client
+	getMetrics getMetricsFn
+	now        func() time.Time
+
+	mtx sync.Mutex
+	// sendErrors holds the export send errors of each collector pod at the previous
+	// evaluation, so that only new errors are reported.
+	sendErrors map[types.UID]float64
+}
+
+func setupHealthController(op *Operator) error {
+	objRequest := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: op.opts.PublicNamespace,
+			Name:      NameOperatorConfig,
+		},
+	}
+	objFilterOperatorConfig := namespacedNamePredicate{
+		namespace: op.opts.PublicNamespace,
+		name:      NameOperatorConfig,
+	}
+	objFilterCollector := namespacedNamePredicate{
+		namespace: op.opts.OperatorNamespace,
+		name:      NameCollector,
+	}
+	objFilterRuleEvaluator := namespacedNamePredicate{
+		namespace: op.opts.OperatorNamespace,
+		name:      NameRuleEvaluator,
+	}
+
+	err := ctrl.NewControllerManagedBy(op.manager).
+		Named("operator-health").
+		For(
+			&monitoringv1.OperatorConfig{},
+			builder.WithPredicates(objFilterOperatorConfig),
+		).
+		// Rollouts and config changes are reflected without waiting for the next poll.
+		Watches(
+			&appsv1.DaemonSet{},
+			enqueueConst(objRequest),
+			builder.WithPredicates(objFilterCollector),
+		).
+		Watches(
+			&corev1.ConfigMap{},
+			enqueueConst(objRequest),
+			builder.WithPredicates(objFilterCollector),
+		).
+		Watches(
+			&appsv1.Deployment{},
+			enqueueConst(objRequest),
+			builder.WithPredicates(objFilterRuleEvaluator),
+		).
+		Complete(newHealthReconciler(op.manager.GetClient(), op.opts, op.opts.CollectorHTTPClient))
+	if err != nil {
+		return fmt.Errorf("create operator health controller: %w", err)
+	}
+	return nil
+}
+
+func newHealthReconciler(c client.Client, opts Options, httpClient *http.Client) *healthReconciler {
+	return &healthReconciler{
+		client:     c,
+		opts:       opts,
+		httpClient: httpClient,
+		getMetrics: getMetrics,
+		now:        time.Now,
+		sendErrors: map[types.UID]float64{},
+	}
+}
+
+func (r *healthReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	logger, _ := logr.FromContext(ctx)
+	logger.Info("evaluating operator health")
+
+	var config monitoringv1.OperatorConfig
+	if err := r.client.Get(ctx, req.NamespacedName, &config); apierrors.IsNotFound(err) {
+		return reconcile.Result{}, nil
+	} else if err != nil {
+		return reconcile.Result{}, fmt.Errorf("get OperatorConfig: %w", err)
+	}
+	state, err := r.healthState(ctx)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	conditions := []metav1.Condition{}
+	for _, eval := range []func(context.Context, *monitoringv1.OperatorConfig, *healthState) (metav1.Condition, error){
+		r.collectorRollout,
+		r.collectorConfigLoaded,
+		r.export,
+		r.ruleEvaluatorConfigLoaded,
+		r.alertmanagerConfigValid,
+		r.webhookCertificate,
+	} {
+		cond, err := eval(ctx, &config, state)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		conditions = append(conditions, cond)
+	}
+	conditions = append(conditions, aggregateHealth(conditions))
+
+	status := config.Status.DeepCopy()
+	status.ObservedGeneration = config.Generation
+	for _, cond := range conditions {
+		cond.ObservedGeneration = config.Generation
+		meta.SetStatusCondition(&status.Conditions, cond)
+	}
+	if equality.Semantic.DeepEqual(status, &config.Status) {
+		return reconcile.Result{RequeueAfter: healthPollInterval}, nil
+	}
+	patch := client.MergeFrom(config.DeepCopy())
+	config.Status = *status
+	if err := r.client.Status().Patch(ctx, &config, patch); err != nil {
+		return reconcile.Result{}, fmt.Errorf("patch OperatorConfig status: %w", err)
+	}
+	return reconcile.Result{RequeueAfter: healthPollInterval}, nil
+}
+
+// healthState lists the running pods of the components and fetches the metrics of each
+// of their ports that the conditions are based on.
+func (r *healthReconciler) healthState(ctx context.Context) (*healthState, error) {
+	var (
+		state = &healthState{}
+		err   error
+	)
+	if state.collectorPods, err = r.collectorPods(ctx); err != nil {
+		return nil, err
+	}
+	if state.ruleEvaluatorPods, err = r.runningPods(ctx, labels.SelectorFromSet(rulesLabels())); err != nil {
+		return nil, err
+	}
+	if state.alertmanagerPods, err = r.runningPods(ctx, labels.SelectorFromSet(alertmanagerLabels())); err != nil {
+		return nil, err
+	}
+
+	var reqs []podMetricsRequest
+	for _, pod := range state.collectorPods {
+		reqs = append(reqs,
+			podMetricsRequest{pod: pod, port: CollectorConfigReloaderContainerPortName},
+			podMetricsRequest{pod: pod, port: CollectorPrometheusContainerPortName},
+		)
+	}
+	for _, pod := range slices.Concat(state.ruleEvaluatorPods, state.alertmanagerPods) {
+		reqs = append(reqs, podMetricsRequest{pod: pod, port: CollectorConfigReloaderContainerPortName})
+	}
+	state.metrics = r.fetchMetrics(ctx, reqs)
+	return state, nil
+}
+
+type podMetricsRequest struct {
+	pod  *corev1.Pod
+	port string
+}
+
+// fetchMetrics fetches the metrics of the requested pods and ports concurrently. Metrics
+// that could not be fetched are omitted from the result.
+func (r *healthReconciler) fetchMetrics(ctx context.Context, reqs []podMetricsRequest) map[podMetricsKey]map[string]*dto.MetricFamily {
+	var (
+		mtx     sync.Mutex
+		wg      sync.WaitGroup
+		results = map[podMetricsKey]map[string]*dto.MetricFamily{}
+		reqCh   = make(chan podMetricsRequest)
+	)
+	for range max(int(r.opts.TargetPollConcurrency), 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for req := range reqCh {
+				mfs, err := r.podMetrics(ctx, req.pod, req.port)
+				if err != nil {
+					continue
+				}
+				mtx.Lock()
+				results[podMetricsKey{uid: req.pod.UID, port: req.port}] = mfs
+				mtx.Unlock()
+			}
+		}()
+	}
+	for _, req := range reqs {
+		reqCh <- req
+	}
+	close(reqCh)
+	wg.Wait()
+
+	return results
+}
+
+// collectorPods returns the running pods of the collector DaemonSet.
+func (r *healthReconciler) collectorPods(ctx context.Context) ([]*corev1.Pod, error) {
+	var ds appsv1.DaemonSet
+	if err := r.client.Get(ctx, client.ObjectKey{Namespace: r.opts.OperatorNamespace, Name: NameCollector}, &ds); apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("get collector DaemonSet: %w", err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(ds.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("parse collector selector: %w", err)
+	}
+	return r.runningPods(ctx, selector)
+}
+
+func (r *healthReconciler) runningPods(ctx context.Context, selector labels.Selector) ([]*corev1.Pod, error) {
+	var pods corev1.PodList
+	if err := r.client.List(ctx, &pods, client.InNamespace(r.opts.OperatorNamespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("list pods: %w", err)
+	}
+	var running []*corev1.Pod
+	for i := range pods.Items {
+		if pod := &pods.Items[i]; pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil {
+			running = append(running, pod)
+		}
+	}
+	return running, nil
+}
+
+func (r *healthReconciler) collectorRollout(ctx context.Context, _ *monitoringv1.OperatorConfig, _ *healthState) (metav1.Condition, error) {
+	cond := metav1.Condition{Type: string(monitoringv1.CollectorRolloutComplete)}
+
+	var ds appsv1.DaemonSet
+	if err := r.client.Get(ctx, client.ObjectKey{Namespace: r.opts.OperatorNamespace, Name: NameCollector}, &ds); apierrors.IsNotFound(err) {
+		return falseCondition(cond, reasonNotFound, "collector DaemonSet does not exist"), nil
+	} else if err != nil {
+		return cond, fmt.Errorf("get collector DaemonSet: %w", err)
+	}
+	s := ds.Status
+	msg := fmt.Sprintf("%d of %d collectors updated, %d available", s.UpdatedNumberScheduled, s.DesiredNumberScheduled, s.NumberAvailable)
+	if s.ObservedGeneration < ds.Generation || s.UpdatedNumberScheduled < s.DesiredNumberScheduled || s.NumberAvailable < s.DesiredNumberScheduled {
+		return falseCondition(cond, reasonRolloutProgress, msg), nil
+	}
+	return trueCondition(cond, reasonRolloutComplete, msg), nil
+}
+
+func (r *healthReconciler) collectorConfigLoaded(ctx context.Context, _ *monitoringv1.OperatorConfig, state *healthState) (metav1.Condition, error) {
+	cond := metav1.Condition{Type: string(monitoringv1.CollectorConfigLoaded)}
+
+	var cm corev1.ConfigMap
+	if err := r.client.Get(ctx, client.ObjectKey{Namespace: r.opts.OperatorNamespace, Name: NameCollector}, &cm); apierrors.IsNotFound(err) {
+		return falseCondition(cond, reasonNotFound, "collector ConfigMap does not exist"), nil
+	} else if err != nil {
+		return cond, fmt.Errorf("get collector ConfigMap: %w", err)
+	}
+	hash := cm.Annotations[annotationConfigHash]
+
+	var (
+		pods             = state.collectorPods
+		outdated, failed []string
+	)
+	for _, pod := range pods {
+		mfs, ok := state.podMetrics(pod, CollectorConfigReloaderContainerPortName)
+		if !ok || !reloadSuccessful(mfs) {
+			failed = append(failed, pod.Name)
+		} else if !hasConfigHash(mfs, hash) {
+			outdated = append(outdated, pod.Name)
+		}
+	}
+	switch {
+	case len(failed) > 0:
+		return falseCondition(cond, reasonReloadFailed, fmt.Sprintf("%d of %d collectors failed to reload the config: %s", len(failed), len(pods), podList(failed))), nil
+	case len(outdated) > 0:
+		return falseCondition(cond, reasonConfigNotLoaded, fmt.Sprintf("%d of %d collectors have not loaded the current config: %s", len(outdated), len(pods), podList(outdated))), nil
+	}
+	return trueCondition(cond, reasonConfigLoaded, fmt.Sprintf("%d collectors loaded the current config", len(pods))), nil
+}
+
+// export reports samples that failed to be sent to GCM since the previous evaluation.
+func (r *healthReconciler) export(_ context.Context, _ *monitoringv1.OperatorConfig, state *healthState) (metav1.Condition, error) {
+	cond := metav1.Condition{Type: string(monitoringv1.ExportHealthy)}
+
+	var (
+		total    float64
+		affected []string
+		seen     = map[types.UID]float64{}
+	)
+	for _, pod := range state.collectorPods {
+		mfs, ok := state.podMetrics(pod, CollectorPrometheusContainerPortName)
+		if !ok {
+			// Unreachable collectors are reported by the CollectorConfigLoaded condition.
+			if prev, ok := r.sendErrors[pod.UID]; ok {
+				seen[pod.UID] = prev
+			}
+			continue
+		}
+		errs := sumCounter(mfs[metricSendErrors])
+		seen[pod.UID] = errs
+		// The first evaluation of a pod only records the baseline. Counter resets are
+		// treated the same.
+		if prev, ok := r.sendErrors[pod.UID]; ok && errs > prev {
+			total += errs - prev
+			affected = append(affected, pod.Name)
+		}
+	}
+	r.sendErrors = seen
+
+	if total > 0 {
+		return falseCondition(cond, reasonSendErrors, fmt.Sprintf("%.0f samples failed to be sent by collectors %s since the last check", total, podList(affected))), nil
+	}
+	return trueCondition(cond, reasonNoSendErrors, "no samples failed to be sent since the last check"), nil
+}
+
+func (r *healthReconciler) ruleEvaluatorConfigLoaded(_ context.Context, _ *monitoringv1.OperatorConfig, state *healthState) (metav1.Condition, error) {
+	cond := metav1.Condition{Type: string(monitoringv1.RuleEvaluatorConfigLoaded)}
+
+	pods := state.ruleEvaluatorPods
+	if len(pods) == 0 {
+		return trueCondition(cond, reasonScaledDown, "no rule-evaluator is running"), nil
+	}
+	if failed := failedReloads(state, pods); len(failed) > 0 {
+		return falseCondition(cond, reasonReloadFailed, fmt.Sprintf("%d of %d rule-evaluators failed to reload the config: %s", len(failed), len(pods), podList(failed))), nil
+	}
+	return trueCondition(cond, reasonConfigLoaded, fmt.Sprintf("%d rule-evaluators loaded the config", len(pods))), nil
+}
+
+func (r *healthReconciler) alertmanagerConfigValid(ctx context.Context, config *monitoringv1.OperatorConfig, state *healthState) (metav1.Condition, error) {
+	cond := metav1.Condition{Type: string(monitoringv1.AlertmanagerConfigValid)}
+
+	// Mirror the secret selection of the rule-evaluator controller.
+	sel := &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: AlertmanagerPublicSecretName},
+		Key:                  AlertmanagerPublicSecretKey,
+	}
+	if spec := config.ManagedAlertmanager; spec != nil && spec.ConfigSecret != nil {
+		sel.Name = spec.ConfigSecret.Name
+		sel.Key = spec.ConfigSecret.Key
+	}
+	b, err := getSecretKeyBytes(ctx, r.client, r.opts.PublicNamespace, sel)
+	switch {
+	case apierrors.IsNotFound(err):
+		return trueCondition(cond, reasonNotApplicable, fmt.Sprintf("no config in secret %q, key %q, managed Alertmanager runs without receivers", sel.Name, sel.Key)), nil
+	case err != nil:
+		return falseCondition(cond, reasonConfigInvalid, err.Error()), nil
+	}
+	var amConfig map[string]any
+	if err := yaml.Unmarshal(b, &amConfig); err != nil {
+		return falseCondition(cond, reasonConfigInvalid, fmt.Sprintf("invalid config in secret %q, key %q: %s", sel.Name, sel.Key, err)), nil
+	}
+	if err := validateAlertmanagerConfig(amConfig); err != nil {
+		return falseCondition(cond, reasonConfigInvalid, fmt.Sprintf("invalid config in secret %q, key %q: %s", sel.Name, sel.Key, err)), nil
+	}
+
+	pods := state.alertmanagerPods
+	if failed := failedReloads(state, pods); len(failed) > 0 {
+		return falseCondition(cond, reasonReloadFailed, fmt.Sprintf("%d of %d Alertmanagers failed to reload the config: %s", len(failed), len(pods), podList(failed))), nil
+	}
+	return trueCondition(cond, reasonConfigValid, fmt.Sprintf("config in secret %q, key %q is valid", sel.Name, sel.Key)), nil
+}
+
+// webhookCertificate reports whether the certificate served by the webhook server of this
+// operator is valid and not about to expire.
+func (r *healthReconciler) webhookCertificate(context.Context, *monitoringv1.OperatorConfig, *healthState) (metav1.Condition, error) {
+	cond := metav1.Condition{Type: string(monitoringv1.WebhookCertificateValid)}
+	if r.opts.CertDir == "" {
+		return trueCondition(cond, reasonNotApplicable, "webhook server is not configured"), nil
+	}
+	b, err := os.ReadFile(filepath.Join(r.opts.CertDir, keyWebhookCert))
+	if err != nil {
+		return falseCondition(cond, reasonCertificateError, fmt.Sprintf("read certificate: %s", err)), nil
+	}
+	cert, err := parseCertPEM(b)
+	if err != nil {
+		return falseCondition(cond, reasonCertificateError, fmt.Sprintf("parse certificate: %s", err)), nil
+	}
+	now := r.now()
+	switch {
+	case now.After(cert.NotAfter):
+		return falseCondition(cond, reasonCertificateError, fmt.Sprintf("certificate expired at %s", cert.NotAfter.Format(time.RFC3339))), nil
+	case now.After(cert.NotAfter.Add(-webhookCertExpiryWarning)):
+		return falseCondition(cond, reasonCertificateSoon, fmt.Sprintf("certificate expires at %s", cert.NotAfter.Format(time.RFC3339))), nil
+	}
+	return trueCondition(cond, reasonCertificateValid, fmt.Sprintf("certificate valid until %s", cert.NotAfter.Format(time.RFC3339))), nil
+}
+
+// aggregateHealth returns the Healthy condition, which is only true if none of the
+// conditions is false.
+func aggregateHealth(conditions []metav1.Condition) metav1.Condition {
+	cond := metav1.Condition{Type: string(monitoringv1.OperatorConfigHealthy)}
+	var unhealthy []string
+	for _, c := range conditions {
+		if c.Status == metav1.ConditionFalse {
+			unhealthy = append(unhealthy, c.Type)
+		}
+	}
+	if len(unhealthy) > 0 {
+		return falseCondition(cond, reasonUnhealthy, "unhealthy: "+strings.Join(unhealthy, ", "))
+	}
+	return trueCondition(cond, reasonHealthy, "all components are healthy")
+}
+
+// failedReloads returns the names of the pods whose config-reloader failed to reload the
+// config or could not be reached.
+func failedReloads(state *healthState, pods []*corev1.Pod) []string {
+	var failed []string
+	for _, pod := range pods {
+		mfs, ok := state.podMetrics(pod, CollectorConfigReloaderContainerPortName)
+		if !ok || !reloadSuccessful(mfs) {
+			failed = append(failed, pod.Name)
+		}
+	}
+	return failed
+}
+
+func (r *healthReconciler) podMetrics(ctx context.Context, pod *corev1.Pod, portName string) (map[string]*dto.MetricFamily, error) {
+	logger, _ := logr.FromContext(ctx)
+
+	port, ok := containerPort(pod, portName)
+	if !ok {
+		return nil, fmt.Errorf("pod %q has no port %q", pod.Name, portName)
+	}
+	ctx, cancel := context.WithTimeout(ctx, healthMetricsTimeout)
+	defer cancel()
+	mfs, err := r.getMetrics(ctx, r.httpClient, pod, port)
+	if err != nil {
+		logger.Error(err, "fetching pod metrics failed", "pod", pod.Name, "port", portName)
+		return nil, err
+	}
+	return mfs, nil
+}
+
+func getMetrics(ctx context.Context, httpClient *http.Client, pod *corev1.Pod, port int32) (map[string]*dto.MetricFamily, error) {
+	if pod.Status.PodIP == "" {
+		return nil, errors.New("pod does not have IP allocated")
+	}
+	url := fmt.Sprintf("http://%s:%d/metrics", pod.Status.PodIP, port) //nolint:revive // Allow insecure http client
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	// The text format is always supported and is simplest to parse.
+	req.Header.Set("Accept", string(expfmt.NewFormat(expfmt.TypeTextPlain)))
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	var parser expfmt.TextParser
+	return parser.TextToMetricFamilies(resp.Body)
+}
+
+func containerPort(pod *corev1.Pod, name string) (int32, bool) {
+	for _, c := range pod.Spec.Containers {
+		for _, p := range c.Ports {
+			if p.Name == name {
+				return p.ContainerPort, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func reloadSuccessful(mfs map[string]*dto.MetricFamily) bool {
+	mf, ok := mfs[metricReloadSuccessful]
+	if !ok || len(mf.GetMetric()) == 0 {
+		return false
+	}
+	return mf.GetMetric()[0].GetGauge().GetValue() == 1
+}
+
+func hasConfigHash(mfs map[string]*dto.MetricFamily, hash string) bool {
+	for _, m := range mfs[metricConfigHash].GetMetric() {
+		for _, l := range m.GetLabel() {
+			if l.GetName() == "hash" && l.GetValue() == hash {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sumCounter(mf *dto.MetricFamily) float64 {
+	var sum float64
+	for _, m := range mf.GetMetric() {
+		sum += m.GetCounter().GetValue()
+	}
+	return sum
+}
+
+// podList formats pod names for condition messages, truncating long lists.
+func podList(names []string) string {
+	const maxNames = 5
+	slices.Sort(names)
+	if len(names) > maxNames {
+		return fmt.Sprintf("%s and %d more", strings.Join(names[:maxNames], ", "), len(names)-maxNames)
+	}
+	return strings.Join(names, ", ")
+}
+
+func trueCondition(c metav1.Condition, reason, msg string) metav1.Condition {
+	c.Status, c.Reason, c.Message = metav1.ConditionTrue, reason, msg
+	return c
+}
+
+func falseCondition(c metav1.Condition, reason, msg string) metav1.Condition {
+	c.Status, c.Reason, c.Message = metav1.ConditionFalse, reason, msg
+	return c
+}
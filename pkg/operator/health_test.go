@@ -0,0 +1,230 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+func TestHealthReconcile(t *testing.T) {
+	opts := Options{
+		OperatorNamespace: "gmp-system",
+		PublicNamespace:   "gmp-public",
+		CertDir:           t.TempDir(),
+	}
+	now := time.Now()
+	certPEM, _, err := generateWebhookCA(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(opts.CertDir, keyWebhookCert), certPEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	const hash = "abc"
+	pod := func(name string, labels map[string]string, ports ...corev1.ContainerPort) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: opts.OperatorNamespace, Name: name, Labels: labels, UID: types.UID(name)},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "main", Ports: ports}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.1"},
+		}
+	}
+	collectorLabels := map[string]string{LabelAppName: NameCollector}
+	collectorPorts := []corev1.ContainerPort{
+		{Name: CollectorPrometheusContainerPortName, ContainerPort: 19090},
+		{Name: CollectorConfigReloaderContainerPortName, ContainerPort: 19091},
+	}
+	reloaderPort := corev1.ContainerPort{Name: CollectorConfigReloaderContainerPortName, ContainerPort: 19093}
+
+	c := newFakeClientBuilder().WithObjects(
+		&monitoringv1.OperatorConfig{
+			ObjectMeta: metav1.ObjectMeta{Namespace: opts.PublicNamespace, Name: NameOperatorConfig, Generation: 3},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: opts.OperatorNamespace, Name: NameCollector, Generation: 2},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: collectorLabels},
+			},
+			Status: appsv1.DaemonSetStatus{
+				ObservedGeneration:     2,
+				DesiredNumberScheduled: 2,
+				UpdatedNumberScheduled: 2,
+				NumberAvailable:        2,
+			},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   opts.OperatorNamespace,
+				Name:        NameCollector,
+				Annotations: map[string]string{annotationConfigHash: hash},
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: opts.PublicNamespace, Name: AlertmanagerPublicSecretName},
+			Data:       map[string][]byte{AlertmanagerPublicSecretKey: []byte("route: [")},
+		},
+		pod("collector-a", collectorLabels, collectorPorts...),
+		pod("collector-b", collectorLabels, collectorPorts...),
+		pod("rule-evaluator-a", rulesLabels(), reloaderPort),
+	).Build()
+
+	// Metrics exposed by each pod and port.
+	metrics := map[string]string{
+		"collector-a/19091":      reloaderMetrics(1, hash),
+		"collector-b/19091":      reloaderMetrics(1, "old"),
+		"collector-a/19090":      sendErrorMetrics(0),
+		"collector-b/19090":      sendErrorMetrics(10),
+		"rule-evaluator-a/19093": reloaderMetrics(0, ""),
+	}
+	var (
+		fetchMtx sync.Mutex
+		fetches  = map[string]int{}
+	)
+	r := newHealthReconciler(c, opts, nil)
+	r.now = func() time.Time { return now }
+	r.getMetrics = func(_ context.Context, _ *http.Client, pod *corev1.Pod, port int32) (map[string]*dto.MetricFamily, error) {
+		key := fmt.Sprintf("%s/%d", pod.Name, port)
+		fetchMtx.Lock()
+		fetches[key]++
+		fetchMtx.Unlock()
+		text, ok := metrics[key]
+		if !ok {
+			return nil, fmt.Errorf("no metrics for %s/%d", pod.Name, port)
+		}
+		var parser expfmt.TextParser
+		return parser.TextToMetricFamilies(strings.NewReader(text))
+	}
+
+	reconcileHealth := func() *monitoringv1.OperatorConfig {
+		t.Helper()
+		req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: opts.PublicNamespace, Name: NameOperatorConfig}}
+		if _, err := r.Reconcile(t.Context(), req); err != nil {
+			t.Fatalf("reconcile: %s", err)
+		}
+		var config monitoringv1.OperatorConfig
+		if err := c.Get(t.Context(), client.ObjectKey{Namespace: opts.PublicNamespace, Name: NameOperatorConfig}, &config); err != nil {
+			t.Fatal(err)
+		}
+		return &config
+	}
+	expectCondition := func(config *monitoringv1.OperatorConfig, typ monitoringv1.OperatorConfigConditionType, status metav1.ConditionStatus, reason string) {
+		t.Helper()
+		cond := meta.FindStatusCondition(config.Status.Conditions, string(typ))
+		if cond == nil {
+			t.Fatalf("condition %s not found", typ)
+		}
+		if cond.Status != status || cond.Reason != reason {
+			t.Errorf("condition %s: expected %s/%s, got %s/%s: %s", typ, status, reason, cond.Status, cond.Reason, cond.Message)
+		}
+		if cond.ObservedGeneration != config.Generation {
+			t.Errorf("condition %s: expected observed generation %d, got %d", typ, config.Generation, cond.ObservedGeneration)
+		}
+	}
+
+	config := reconcileHealth()
+	// The metrics of each pod and port are fetched once, even if several conditions use them.
+	for key, n := range fetches {
+		if n != 1 {
+			t.Errorf("expected metrics of %s to be fetched once, got %d", key, n)
+		}
+	}
+	if len(fetches) != len(metrics) {
+		t.Errorf("expected metrics of %d pod ports to be fetched, got %v", len(metrics), fetches)
+	}
+	expectCondition(config, monitoringv1.CollectorRolloutComplete, metav1.ConditionTrue, reasonRolloutComplete)
+	expectCondition(config, monitoringv1.CollectorConfigLoaded, metav1.ConditionFalse, reasonConfigNotLoaded)
+	// The first evaluation only records the baseline of send errors.
+	expectCondition(config, monitoringv1.ExportHealthy, metav1.ConditionTrue, reasonNoSendErrors)
+	expectCondition(config, monitoringv1.RuleEvaluatorConfigLoaded, metav1.ConditionFalse, reasonReloadFailed)
+	expectCondition(config, monitoringv1.AlertmanagerConfigValid, metav1.ConditionFalse, reasonConfigInvalid)
+	expectCondition(config, monitoringv1.WebhookCertificateValid, metav1.ConditionTrue, reasonCertificateValid)
+	expectCondition(config, monitoringv1.OperatorConfigHealthy, metav1.ConditionFalse, reasonUnhealthy)
+
+	// A config that is valid YAML must still pass the Alertmanager config loader.
+	amSecret := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: opts.PublicNamespace, Name: AlertmanagerPublicSecretName}}
+	if err := c.Get(t.Context(), client.ObjectKeyFromObject(&amSecret), &amSecret); err != nil {
+		t.Fatal(err)
+	}
+	amSecret.Data[AlertmanagerPublicSecretKey] = []byte("route:\n  receiver: missing\n")
+	if err := c.Update(t.Context(), &amSecret); err != nil {
+		t.Fatal(err)
+	}
+	config = reconcileHealth()
+	expectCondition(config, monitoringv1.AlertmanagerConfigValid, metav1.ConditionFalse, reasonConfigInvalid)
+
+	// Fix all components, but have new send errors.
+	metrics["collector-b/19091"] = reloaderMetrics(1, hash)
+	metrics["collector-b/19090"] = sendErrorMetrics(15)
+	metrics["rule-evaluator-a/19093"] = reloaderMetrics(1, "")
+	if err := c.Delete(t.Context(), &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: opts.PublicNamespace, Name: AlertmanagerPublicSecretName}}); err != nil {
+		t.Fatal(err)
+	}
+
+	config = reconcileHealth()
+	expectCondition(config, monitoringv1.CollectorConfigLoaded, metav1.ConditionTrue, reasonConfigLoaded)
+	expectCondition(config, monitoringv1.ExportHealthy, metav1.ConditionFalse, reasonSendErrors)
+	expectCondition(config, monitoringv1.RuleEvaluatorConfigLoaded, metav1.ConditionTrue, reasonConfigLoaded)
+	expectCondition(config, monitoringv1.AlertmanagerConfigValid, metav1.ConditionTrue, reasonNotApplicable)
+	expectCondition(config, monitoringv1.OperatorConfigHealthy, metav1.ConditionFalse, reasonUnhealthy)
+
+	// No new send errors and the webhook certificate is about to expire.
+	cert, err := parseCertPEM(certPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.now = func() time.Time { return cert.NotAfter.Add(-time.Hour) }
+
+	config = reconcileHealth()
+	expectCondition(config, monitoringv1.ExportHealthy, metav1.ConditionTrue, reasonNoSendErrors)
+	expectCondition(config, monitoringv1.WebhookCertificateValid, metav1.ConditionFalse, reasonCertificateSoon)
+
+	r.now = func() time.Time { return now }
+	config = reconcileHealth()
+	expectCondition(config, monitoringv1.OperatorConfigHealthy, metav1.ConditionTrue, reasonHealthy)
+}
+
+func reloaderMetrics(success int, hash string) string {
+	text := fmt.Sprintf("# TYPE reloader_last_reload_successful gauge\nreloader_last_reload_successful %d\n", success)
+	if hash != "" {
+		text += fmt.Sprintf("# TYPE config_reloader_config_hash_info gauge\nconfig_reloader_config_hash_info{hash=%q} 1\n", hash)
+	}
+	return text
+}
+
+func sendErrorMetrics(errs int) string {
+	return fmt.Sprintf("# TYPE gcm_export_samples_send_errors_total counter\ngcm_export_samples_send_errors_total{project_id=\"p\"} %d\n", errs)
+}
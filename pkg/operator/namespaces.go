@@ -0,0 +1,201 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	prommodel "github.com/prometheus/common/model"
+	promconfig "github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/model/relabel"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+// reasonNamespaceIgnored is the condition reason of resources that are not considered because
+// of the namespaces filter of the OperatorConfig.
+const reasonNamespaceIgnored = "NamespaceIgnored"
+
+// namespaceFilter decides whether resources in a namespace are considered. A nil filter
+// considers all namespaces.
+type namespaceFilter struct {
+	client   client.Reader
+	spec     *monitoringv1.NamespaceFilter
+	selector labels.Selector
+	// results holds the decision for each namespace looked up so far. Filters are built for
+	// a single reconciliation, so namespace label changes are picked up by the next one.
+	results map[string]bool
+	// relabel is the relabeling rule of cluster-scoped scrape configs, once built.
+	relabel *relabel.Config
+}
+
+func newNamespaceFilter(c client.Reader, spec *monitoringv1.NamespaceFilter) (*namespaceFilter, error) {
+	if spec == nil || (spec.Selector == nil && len(spec.Allow) == 0 && len(spec.Deny) == 0) {
+		return nil, nil
+	}
+	selector, err := spec.LabelSelector()
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespaces selector: %w", err)
+	}
+	return &namespaceFilter{
+		client:   c,
+		spec:     spec,
+		selector: selector,
+		results:  map[string]bool{},
+	}, nil
+}
+
+// allowed returns whether resources in the namespace are considered.
+func (f *namespaceFilter) allowed(ctx context.Context, namespace string) (bool, error) {
+	if f == nil {
+		return true, nil
+	}
+	if ok, found := f.results[namespace]; found {
+		return ok, nil
+	}
+	ok, err := f.match(ctx, namespace)
+	if err != nil {
+		return false, err
+	}
+	f.results[namespace] = ok
+	return ok, nil
+}
+
+func (f *namespaceFilter) match(ctx context.Context, namespace string) (bool, error) {
+	switch {
+	case slices.Contains(f.spec.Deny, namespace):
+		return false, nil
+	case slices.Contains(f.spec.Allow, namespace):
+		return true, nil
+	case f.selector == nil:
+		// Without a selector, only explicitly allowed namespaces are selected, if any.
+		return len(f.spec.Allow) == 0, nil
+	}
+	var ns corev1.Namespace
+	if err := f.client.Get(ctx, client.ObjectKey{Name: namespace}, &ns); apierrors.IsNotFound(err) {
+		// The namespace is being deleted, or not yet in the cache. Match it without labels.
+		return f.selector.Matches(labels.Set{}), nil
+	} else if err != nil {
+		return false, fmt.Errorf("get namespace %q: %w", namespace, err)
+	}
+	return f.selector.Matches(labels.Set(ns.Labels)), nil
+}
+
+// deniedSecretNamespace returns the first namespace of the referenced secrets that isn't allowed,
+// or an empty string if all are allowed.
+func (f *namespaceFilter) deniedSecretNamespace(ctx context.Context, secrets monitoringv1.PrometheusSecretConfigs) (string, error) {
+	if f == nil {
+		return "", nil
+	}
+	namespaces := make([]string, 0, len(secrets))
+	for _, s := range secrets {
+		namespaces = append(namespaces, s.Namespace)
+	}
+	slices.Sort(namespaces)
+	for _, ns := range slices.Compact(namespaces) {
+		ok, err := f.allowed(ctx, ns)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return ns, nil
+		}
+	}
+	return "", nil
+}
+
+// filterScrapeConfigs returns the scrape configs of a cluster-scoped resource with a
+// relabeling rule that drops the targets in namespaces that aren't allowed. The input
+// configs may be shared with the config cache and are not modified.
+func (f *namespaceFilter) filterScrapeConfigs(ctx context.Context, cfgs []*promconfig.ScrapeConfig) ([]*promconfig.ScrapeConfig, error) {
+	if f == nil {
+		return cfgs, nil
+	}
+	if f.relabel == nil {
+		rule, err := f.relabelConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		f.relabel = rule
+	}
+	res := make([]*promconfig.ScrapeConfig, 0, len(cfgs))
+	for _, c := range cfgs {
+		filtered := *c
+		filtered.RelabelConfigs = append([]*relabel.Config{f.relabel}, c.RelabelConfigs...)
+		res = append(res, &filtered)
+	}
+	return res, nil
+}
+
+// relabelConfig returns a relabeling rule that only keeps targets in allowed namespaces.
+func (f *namespaceFilter) relabelConfig(ctx context.Context) (*relabel.Config, error) {
+	// Only denied namespaces don't depend on the existing namespaces. Otherwise, namespaces
+	// are only scraped once they are known to match.
+	if f.selector == nil && len(f.spec.Allow) == 0 {
+		return &relabel.Config{
+			Action:       relabel.Drop,
+			SourceLabels: prommodel.LabelNames{"__meta_kubernetes_namespace"},
+			Regex:        namespacesRegexp(f.spec.Deny),
+		}, nil
+	}
+	var namespaces corev1.NamespaceList
+	if err := f.client.List(ctx, &namespaces); err != nil {
+		return nil, fmt.Errorf("list namespaces: %w", err)
+	}
+	var allowed []string
+	for _, ns := range namespaces.Items {
+		ok, err := f.allowed(ctx, ns.Name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			allowed = append(allowed, ns.Name)
+		}
+	}
+	return &relabel.Config{
+		Action:       relabel.Keep,
+		SourceLabels: prommodel.LabelNames{"__meta_kubernetes_namespace"},
+		Regex:        namespacesRegexp(allowed),
+	}, nil
+}
+
+// namespacesRegexp returns a regular expression that matches exactly the given namespaces.
+func namespacesRegexp(namespaces []string) relabel.Regexp {
+	quoted := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		quoted = append(quoted, regexp.QuoteMeta(ns))
+	}
+	slices.Sort(quoted)
+	return relabel.MustNewRegexp(strings.Join(slices.Compact(quoted), "|"))
+}
+
+// namespaceIgnoredCondition returns the condition of resources that are ignored because of
+// their namespace.
+func namespaceIgnoredCondition(namespace string) *monitoringv1.MonitoringCondition {
+	return &monitoringv1.MonitoringCondition{
+		Type:    monitoringv1.ConfigurationCreateSuccess,
+		Status:  corev1.ConditionFalse,
+		Reason:  reasonNamespaceIgnored,
+		Message: fmt.Sprintf("namespace %q is excluded by the namespaces filter of the OperatorConfig", namespace),
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/testr"
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+func TestNamespaceFilter(t *testing.T) {
+	c := newFakeClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"monitoring": "gmp"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"monitoring": "self-managed"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-c"}},
+	).Build()
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"monitoring": "gmp"}}
+
+	testCases := []struct {
+		desc string
+		spec *monitoringv1.NamespaceFilter
+		want map[string]bool
+	}{
+		{
+			desc: "unset",
+			spec: &monitoringv1.NamespaceFilter{},
+			want: map[string]bool{"team-a": true, "team-b": true, "team-c": true, "missing": true},
+		},
+		{
+			desc: "deny",
+			spec: &monitoringv1.NamespaceFilter{Deny: []string{"team-b"}},
+			want: map[string]bool{"team-a": true, "team-b": false, "team-c": true, "missing": true},
+		},
+		{
+			desc: "allow",
+			spec: &monitoringv1.NamespaceFilter{Allow: []string{"team-c"}},
+			want: map[string]bool{"team-a": false, "team-b": false, "team-c": true, "missing": false},
+		},
+		{
+			desc: "selector",
+			spec: &monitoringv1.NamespaceFilter{Selector: selector},
+			want: map[string]bool{"team-a": true, "team-b": false, "team-c": false, "missing": false},
+		},
+		{
+			desc: "selector and allow",
+			spec: &monitoringv1.NamespaceFilter{Selector: selector, Allow: []string{"team-c"}},
+			want: map[string]bool{"team-a": true, "team-b": false, "team-c": true, "missing": false},
+		},
+		{
+			desc: "deny takes precedence",
+			spec: &monitoringv1.NamespaceFilter{Selector: selector, Allow: []string{"team-c"}, Deny: []string{"team-a", "team-c"}},
+			want: map[string]bool{"team-a": false, "team-b": false, "team-c": false, "missing": false},
+		},
+		{
+			desc: "empty selector",
+			spec: &monitoringv1.NamespaceFilter{Selector: &metav1.LabelSelector{}},
+			want: map[string]bool{"team-a": true, "team-b": true, "team-c": true, "missing": true},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f, err := newNamespaceFilter(c, tc.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]bool{}
+			for ns := range tc.want {
+				got[ns], err = f.allowed(t.Context(), ns)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected result (-want, +got): %s", diff)
+			}
+		})
+	}
+
+	_, err := newNamespaceFilter(c, &monitoringv1.NamespaceFilter{
+		Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "a", Operator: "Invalid"}}},
+	})
+	if err == nil {
+		t.Error("expected error for invalid selector")
+	}
+}
+
+func TestNamespaceFilterIgnoredResources(t *testing.T) {
+	ctx := logr.NewContext(t.Context(), testr.New(t))
+	opts := Options{
+		ProjectID: "test-proj",
+		Location:  "us-central1",
+		Cluster:   "test-cluster",
+	}
+	if err := opts.defaultAndValidate(logr.Discard()); err != nil {
+		t.Fatal(err)
+	}
+	endpoints := []monitoringv1.ScrapeEndpoint{{
+		Port:     intstr.FromString("metrics"),
+		Interval: "10s",
+	}}
+	podMons := []*monitoringv1.PodMonitoring{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "app"},
+			Spec:       monitoringv1.PodMonitoringSpec{Endpoints: endpoints},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "app"},
+			Spec:       monitoringv1.PodMonitoringSpec{Endpoints: endpoints},
+		},
+	}
+	clusterPodMon := &monitoringv1.ClusterPodMonitoring{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret-in-tenant"},
+		Spec: monitoringv1.ClusterPodMonitoringSpec{
+			Endpoints: []monitoringv1.ScrapeEndpoint{{
+				Port:     intstr.FromString("metrics"),
+				Interval: "10s",
+				HTTPClientConfig: monitoringv1.HTTPClientConfig{
+					Authorization: &monitoringv1.Auth{
+						Credentials: &monitoringv1.SecretSelector{
+							Secret: &monitoringv1.SecretKeySelector{Namespace: "tenant", Name: "token", Key: "token"},
+						},
+					},
+				},
+			}},
+		},
+	}
+	rules := []*monitoringv1.Rules{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "rules"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "rules"}},
+	}
+	objs := []client.Object{clusterPodMon}
+	for _, o := range podMons {
+		objs = append(objs, o)
+	}
+	for _, o := range rules {
+		objs = append(objs, o)
+	}
+	c := newFakeClientBuilder().WithObjects(objs...).Build()
+	namespaces := &monitoringv1.NamespaceFilter{Deny: []string{"tenant"}}
+
+	collection := newCollectionReconciler(c, opts)
+	cfg, _, err := collection.makeCollectorConfig(ctx, &monitoringv1.CollectionSpec{}, nil, namespaces, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jobs []string
+	for _, sc := range cfg.ScrapeConfigs {
+		jobs = append(jobs, sc.JobName)
+	}
+	if diff := cmp.Diff([]string{"PodMonitoring/team-a/app/metrics"}, jobs); diff != "" {
+		t.Errorf("unexpected scrape jobs (-want, +got): %s", diff)
+	}
+	if len(cfg.SecretConfigs) != 0 {
+		t.Errorf("expected no secret configs, got %v", cfg.SecretConfigs)
+	}
+	if err := collection.ensureCollectorConfig(ctx, &monitoringv1.CollectionSpec{}, monitoringv1.CompressionNone, nil, namespaces, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	rulesReconciler := newRulesReconciler(c, opts)
+	if err := rulesReconciler.ensureRuleConfigs(ctx, "", "", "", monitoringv1.CompressionNone, namespaces, false, 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	var cm corev1.ConfigMap
+	if err := c.Get(ctx, client.ObjectKey{Namespace: opts.OperatorNamespace, Name: nameRulesGenerated}, &cm); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cm.Data["rules__tenant__rules.yaml"]; ok {
+		t.Error("expected no rule file for Rules in ignored namespace")
+	}
+	if _, ok := cm.Data["rules__team-a__rules.yaml"]; !ok {
+		t.Error("expected rule file for Rules in selected namespace")
+	}
+
+	ignored := []monitoringv1.MonitoringCRD{podMons[1], clusterPodMon, rules[1]}
+	for _, obj := range ignored {
+		if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			t.Fatal(err)
+		}
+		conds := obj.GetMonitoringStatus().Conditions
+		if len(conds) != 1 || conds[0].Status != corev1.ConditionFalse || conds[0].Reason != reasonNamespaceIgnored {
+			t.Errorf("expected %s to be ignored, got conditions %v", client.ObjectKeyFromObject(obj), conds)
+			continue
+		}
+		if !strings.Contains(conds[0].Message, `"tenant"`) {
+			t.Errorf("expected condition message to name the namespace, got %q", conds[0].Message)
+		}
+	}
+}
+
+func TestNamespaceFilterClusterScrapeConfigs(t *testing.T) {
+	opts := Options{
+		ProjectID: "test-proj",
+		Location:  "us-central1",
+		Cluster:   "test-cluster",
+	}
+	if err := opts.defaultAndValidate(logr.Discard()); err != nil {
+		t.Fatal(err)
+	}
+	c := newFakeClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"monitoring": "gmp"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant"}},
+		&monitoringv1.ClusterPodMonitoring{
+			ObjectMeta: metav1.ObjectMeta{Name: "app"},
+			Spec: monitoringv1.ClusterPodMonitoringSpec{
+				Endpoints: []monitoringv1.ScrapeEndpoint{{
+					Port:     intstr.FromString("metrics"),
+					Interval: "10s",
+				}},
+			},
+		},
+	).Build()
+
+	testCases := []struct {
+		desc string
+		spec *monitoringv1.NamespaceFilter
+		want map[string]bool
+	}{
+		{
+			desc: "deny",
+			spec: &monitoringv1.NamespaceFilter{Deny: []string{"tenant"}},
+			// Namespaces that don't exist yet are scraped unless denied.
+			want: map[string]bool{"team-a": true, "team-b": true, "tenant": false, "new": true},
+		},
+		{
+			desc: "selector",
+			spec: &monitoringv1.NamespaceFilter{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"monitoring": "gmp"}}},
+			want: map[string]bool{"team-a": true, "team-b": false, "tenant": false, "new": false},
+		},
+		{
+			desc: "allow",
+			spec: &monitoringv1.NamespaceFilter{Allow: []string{"team-b", "tenant"}, Deny: []string{"tenant"}},
+			want: map[string]bool{"team-a": false, "team-b": true, "tenant": false, "new": false},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			collection := newCollectionReconciler(c, opts)
+			cfg, _, err := collection.makeCollectorConfig(t.Context(), &monitoringv1.CollectionSpec{}, nil, tc.spec, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(cfg.ScrapeConfigs) != 1 {
+				t.Fatalf("expected a single scrape config, got %d", len(cfg.ScrapeConfigs))
+			}
+			got := map[string]bool{}
+			for ns := range tc.want {
+				_, got[ns] = relabel.Process(labels.FromStrings("__meta_kubernetes_namespace", ns), cfg.ScrapeConfigs[0].RelabelConfigs[0])
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected scraped namespaces (-want, +got): %s", diff)
+			}
+		})
+	}
+}
@@ -28,7 +28,9 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	arv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensions "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -49,6 +51,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+	monitoringv1alpha1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1alpha1"
 )
 
 const (
@@ -60,6 +63,9 @@ const (
 
 	// NameOperator is a fixed name used in various resources managed by the operator.
 	NameOperator = "gmp-operator"
+	// NameOperatorLease is the name of the Lease used for leader election between operator
+	// replicas.
+	NameOperatorLease = "gmp-operator-leader"
 	// componentName is a fixed name used in various resources managed by the operator.
 	componentName = "managed_prometheus"
 
@@ -125,11 +131,16 @@ type Options struct {
 	ListenAddr string
 	// Cleanup resources without this annotation.
 	CleanupAnnotKey string
-	// The number of upper bound threads to use for target polling otherwise
-	// use the default.
+	// The number of upper bound threads to use for target polling and for fetching the
+	// metrics of component health, otherwise use the default.
 	TargetPollConcurrency uint16
-	// The HTTP client to use when targeting collector endpoints.
+	// The HTTP client to use when targeting collector and rule-evaluator endpoints.
 	CollectorHTTPClient *http.Client
+	// Whether replicas elect a leader that runs the controllers. Webhooks are served
+	// by all replicas.
+	LeaderElection bool
+	// The number of rule-evaluator replicas to run while there are rules to evaluate.
+	RuleEvaluatorReplicas int32
 }
 
 func (o *Options) defaultAndValidate(_ logr.Logger) error {
@@ -152,6 +163,9 @@ func (o *Options) defaultAndValidate(_ logr.Logger) error {
 		return errors.New("cluster must be set")
 	}
 
+	if o.RuleEvaluatorReplicas == 0 {
+		o.RuleEvaluatorReplicas = 1
+	}
 	if o.TargetPollConcurrency == 0 {
 		o.TargetPollConcurrency = defaultTargetPollConcurrency
 	}
@@ -177,6 +191,12 @@ func NewScheme() (*runtime.Scheme, error) {
 	if err := autoscalingv1.AddToScheme(sc); err != nil {
 		return nil, fmt.Errorf("add autoscalerv1 scheme: %w", err)
 	}
+	if err := monitoringv1alpha1.AddToScheme(sc); err != nil {
+		return nil, fmt.Errorf("add monitoringv1alpha1 scheme: %w", err)
+	}
+	if err := apiextensionsv1.AddToScheme(sc); err != nil {
+		return nil, fmt.Errorf("add apiextensionsv1 scheme: %w", err)
+	}
 	return sc, nil
 }
 
@@ -213,6 +233,15 @@ func New(logger logr.Logger, clientConfig *rest.Config, opts Options) (*Operator
 		&monitoringv1.ClusterNodeMonitoring{}: {
 			Field: fields.Everything(),
 		},
+		&monitoringv1.MonitoringQuota{}: {
+			Field: fields.Everything(),
+		},
+		&monitoringv1.AlertRouting{}: {
+			Field: fields.Everything(),
+		},
+		&monitoringv1.Silence{}: {
+			Field: fields.Everything(),
+		},
 		&monitoringv1.GlobalRules{}: {
 			Field: fields.Everything(),
 		},
@@ -222,6 +251,10 @@ func New(logger logr.Logger, clientConfig *rest.Config, opts Options) (*Operator
 		&monitoringv1.Rules{}: {
 			Field: fields.Everything(),
 		},
+		// Namespaces are matched against the namespaces filter of the OperatorConfig.
+		&corev1.Namespace{}: {
+			Field: fields.Everything(),
+		},
 		&corev1.Secret{}: {
 			Namespaces: map[string]cache.Config{
 				opts.OperatorNamespace: {},
@@ -246,10 +279,10 @@ func New(logger logr.Logger, clientConfig *rest.Config, opts Options) (*Operator
 				"metadata.name":      NameCollector,
 			}),
 		},
+		// The rule-evaluator and the Deployments of its further shards.
 		&appsv1.Deployment{}: {
 			Field: fields.SelectorFromSet(fields.Set{
 				"metadata.namespace": opts.OperatorNamespace,
-				"metadata.name":      NameRuleEvaluator,
 			}),
 		},
 		&appsv1.StatefulSet{}: {
@@ -258,6 +291,11 @@ func New(logger logr.Logger, clientConfig *rest.Config, opts Options) (*Operator
 				"metadata.name":      NameAlertmanager,
 			}),
 		},
+		&autoscalingv2.HorizontalPodAutoscaler{}: {
+			Field: fields.SelectorFromSet(fields.Set{
+				"metadata.namespace": opts.OperatorNamespace,
+			}),
+		},
 	}
 
 	// Determine whether VPA is installed in the cluster. If so, set up the scaling controller.
@@ -294,6 +332,14 @@ func New(logger logr.Logger, clientConfig *rest.Config, opts Options) (*Operator
 			BindAddress: "0",
 		},
 		HealthProbeBindAddress: opts.ProbeAddr,
+		// Only the leader runs controllers, including target status polling. Other replicas
+		// keep serving webhooks so admission continues during rollouts and node drains.
+		LeaderElection:          opts.LeaderElection,
+		LeaderElectionID:        NameOperatorLease,
+		LeaderElectionNamespace: opts.OperatorNamespace,
+		// Hand over leadership right away on graceful shutdown instead of waiting for
+		// the lease to expire.
+		LeaderElectionReleaseOnCancel: true,
 		// Manage cluster-wide and namespace resources at the same time.
 		NewCache: cache.NewCacheFunc(func(_ *rest.Config, options cache.Options) (cache.Cache, error) {
 			return cache.New(clientConfig, cache.Options{
@@ -344,9 +390,18 @@ func (o *Operator) Run(ctx context.Context, registry prometheus.Registerer) erro
 	if err := setupAdmissionWebhooks(ctx, o.logger, o.client, o.manager.GetWebhookServer().(*webhook.DefaultServer), &o.opts, o.vpaAvailable); err != nil {
 		return fmt.Errorf("init admission resources: %w", err)
 	}
+	if err := registerConfigMetrics(registry); err != nil {
+		return fmt.Errorf("register config metrics: %w", err)
+	}
+	if err := registerWebhookMetrics(registry); err != nil {
+		return fmt.Errorf("register webhook metrics: %w", err)
+	}
 	if err := setupCollectionControllers(o); err != nil {
 		return fmt.Errorf("setup collection controllers: %w", err)
 	}
+	if err := setupQuotaController(o); err != nil {
+		return fmt.Errorf("setup monitoring quota controller: %w", err)
+	}
 	if err := setupRulesControllers(o); err != nil {
 		return fmt.Errorf("setup rules controllers: %w", err)
 	}
@@ -361,6 +416,18 @@ func (o *Operator) Run(ctx context.Context, registry prometheus.Registerer) erro
 	if err := setupTargetStatusPoller(o, registry, o.opts.CollectorHTTPClient); err != nil {
 		return fmt.Errorf("setup target status processor: %w", err)
 	}
+	if err := setupRuleStatusPoller(o, registry, o.opts.CollectorHTTPClient); err != nil {
+		return fmt.Errorf("setup rule status processor: %w", err)
+	}
+	if err := setupHealthController(o); err != nil {
+		return fmt.Errorf("setup operator health controller: %w", err)
+	}
+	if err := setupSilenceController(o); err != nil {
+		return fmt.Errorf("setup silence controller: %w", err)
+	}
+	if err := setupStorageVersionMigration(o); err != nil {
+		return fmt.Errorf("setup storage version migration: %w", err)
+	}
 
 	o.logger.Info("starting GMP operator")
 	return o.manager.Start(ctx)
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"maps"
 	"path"
+	"strconv"
 	"strings"
 
 	gcmconfig "github.com/prometheus/prometheus/google/config"
@@ -30,6 +31,7 @@ import (
 	prommodel "github.com/prometheus/common/model"
 	promforkconfig "github.com/prometheus/prometheus/config"
 	"github.com/prometheus/prometheus/discovery"
+	discoverydns "github.com/prometheus/prometheus/discovery/dns"
 	discoverykube "github.com/prometheus/prometheus/discovery/kubernetes"
 	"github.com/prometheus/prometheus/discovery/targetgroup"
 	"github.com/prometheus/prometheus/model/labels"
@@ -37,6 +39,7 @@ import (
 	yaml "gopkg.in/yaml.v3"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -74,6 +77,7 @@ const (
 	CollectorPrometheusContainerPortName     = "prom-metrics"
 	CollectorConfigReloaderContainerPortName = "cfg-rel-metrics"
 	RuleEvaluatorContainerName               = "evaluator"
+	RuleEvaluatorContainerPortName           = "r-eval-metrics"
 	AlertmanagerContainerName                = "alertmanager"
 )
 
@@ -123,10 +127,18 @@ func setupOperatorConfigControllers(op *Operator) error {
 		namespace: op.opts.PublicNamespace,
 		name:      NameOperatorConfig,
 	}
-	// Rule-evaluator deployment filter.
-	objFilterRuleEvaluator := namespacedNamePredicate{
+	// Rule-evaluator deployment filter, including the deployments of all shards.
+	objFilterRuleEvaluator := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		if obj.GetNamespace() != op.opts.OperatorNamespace {
+			return false
+		}
+		_, ok := obj.GetLabels()[labelRuleShard]
+		return ok || obj.GetName() == NameRuleEvaluator
+	})
+	// Alertmanager statefulset filter.
+	objFilterAlertmanager := namespacedNamePredicate{
 		namespace: op.opts.OperatorNamespace,
-		name:      NameRuleEvaluator,
+		name:      NameAlertmanager,
 	}
 	// Rule-evaluator secret filter.
 	objFilterRuleEvaluatorSecret := namespacedNamePredicate{
@@ -155,6 +167,14 @@ func setupOperatorConfigControllers(op *Operator) error {
 				objFilterRuleEvaluator,
 				predicate.GenerationChangedPredicate{},
 			)).
+		// Detect and undo changes to workload overrides.
+		Watches(
+			&appsv1.StatefulSet{},
+			enqueueConst(objRequest),
+			builder.WithPredicates(
+				objFilterAlertmanager,
+				predicate.GenerationChangedPredicate{},
+			)).
 		Watches(
 			&corev1.Secret{},
 			enqueueConst(objRequest),
@@ -169,6 +189,16 @@ func setupOperatorConfigControllers(op *Operator) error {
 			&corev1.Secret{},
 			enqueueConst(objRequest),
 			builder.WithPredicates(objFilterAlertManagerSecret)).
+		// AlertRoutings are merged into the Alertmanager config.
+		Watches(
+			&monitoringv1.AlertRouting{},
+			enqueueConst(objRequest),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// Namespace labels may be matched by the namespaces filter.
+		Watches(
+			&corev1.Namespace{},
+			enqueueConst(objRequest),
+			builder.WithPredicates(predicate.LabelChangedPredicate{})).
 		Complete(newOperatorConfigReconciler(op.manager.GetClient(), op.opts))
 	if err != nil {
 		return fmt.Errorf("operator-config controller: %w", err)
@@ -218,11 +248,11 @@ func (r *operatorConfigReconciler) Reconcile(ctx context.Context, req reconcile.
 	}
 
 	// Ensure the alertmanager configuration is pulled from the spec.
-	if err := r.ensureAlertmanagerConfigSecret(ctx, config.ManagedAlertmanager); err != nil {
+	if err := r.ensureAlertmanagerConfigSecret(ctx, config.ManagedAlertmanager, &config.Namespaces); err != nil {
 		return reconcile.Result{}, fmt.Errorf("ensure alertmanager config secret: %w", err)
 	}
 
-	if err := r.ensureAlertmanagerStatefulSet(ctx, config.ManagedAlertmanager); err != nil {
+	if err := r.ensureAlertmanagerStatefulSet(ctx, config.Workloads.Alertmanager); err != nil {
 		return reconcile.Result{}, fmt.Errorf("ensure alertmanager statefulset: %w", err)
 	}
 
@@ -233,7 +263,7 @@ func (r *operatorConfigReconciler) Reconcile(ctx context.Context, req reconcile.
 	}
 
 	// Ensure the rule-evaluator deployment and volume mounts.
-	if err := r.ensureRuleEvaluatorDeployment(ctx); err != nil {
+	if err := r.ensureRuleEvaluatorDeployment(ctx, config.Workloads.RuleEvaluator, ruleShardCount(&config.Rules)); err != nil {
 		return reconcile.Result{}, fmt.Errorf("ensure rule-evaluator deploy: %w", err)
 	}
 
@@ -376,8 +406,9 @@ func (r *operatorConfigReconciler) ensureRuleEvaluatorSecrets(ctx context.Contex
 	return nil
 }
 
-// ensureAlertmanagerConfigSecret copies the managed Alertmanager config secret from gmp-public.
-func (r *operatorConfigReconciler) ensureAlertmanagerConfigSecret(ctx context.Context, spec *monitoringv1.ManagedAlertmanagerSpec) error {
+// ensureAlertmanagerConfigSecret copies the managed Alertmanager config secret from gmp-public
+// and merges the AlertRoutings of the allowed namespaces into it.
+func (r *operatorConfigReconciler) ensureAlertmanagerConfigSecret(ctx context.Context, spec *monitoringv1.ManagedAlertmanagerSpec, namespaces *monitoringv1.NamespaceFilter) error {
 	logger, _ := logr.FromContext(ctx)
 	pubNamespace := r.opts.PublicNamespace
 
@@ -440,6 +471,9 @@ func (r *operatorConfigReconciler) ensureAlertmanagerConfigSecret(ctx context.Co
 		}
 		secret.Data[AlertmanagerConfigKey] = b
 	}
+	if err := r.applyAlertRoutings(ctx, secret, namespaces); err != nil {
+		return fmt.Errorf("apply alert routings: %w", err)
+	}
 
 	if err := r.client.Update(ctx, secret); apierrors.IsNotFound(err) {
 		if err := r.client.Create(ctx, secret); err != nil {
@@ -494,13 +528,9 @@ func (config *alertmanagerConfig) UnmarshalYAML(value *yaml.Node) error {
 	return nil
 }
 
-// ensureAlertmanagerStatefulSet configures the managed Alertmanager instance
-// to reflect the provided spec.
-func (r *operatorConfigReconciler) ensureAlertmanagerStatefulSet(ctx context.Context, spec *monitoringv1.ManagedAlertmanagerSpec) error {
-	if spec == nil {
-		return nil
-	}
-
+// ensureAlertmanagerStatefulSet applies the workload overrides to the managed Alertmanager
+// instance.
+func (r *operatorConfigReconciler) ensureAlertmanagerStatefulSet(ctx context.Context, workload *monitoringv1.WorkloadSpec) error {
 	logger, _ := logr.FromContext(ctx)
 
 	var sset appsv1.StatefulSet
@@ -511,12 +541,14 @@ func (r *operatorConfigReconciler) ensureAlertmanagerStatefulSet(ctx context.Con
 	if apierrors.IsNotFound(err) {
 		logger.Error(err, "Alertmanager StatefulSet does not exist")
 		return nil
+	} else if err != nil {
+		return err
 	}
-	return err
+	return applyWorkloadOverrides(ctx, r.client, &sset, &sset.Spec.Template, AlertmanagerContainerName, workload)
 }
 
 // ensureRuleEvaluatorDeployment reconciles the Deployment for rule-evaluator.
-func (r *operatorConfigReconciler) ensureRuleEvaluatorDeployment(ctx context.Context) error {
+func (r *operatorConfigReconciler) ensureRuleEvaluatorDeployment(ctx context.Context, spec *monitoringv1.WorkloadSpec, shards int32) error {
 	logger, _ := logr.FromContext(ctx)
 
 	var deploy appsv1.Deployment
@@ -526,8 +558,64 @@ func (r *operatorConfigReconciler) ensureRuleEvaluatorDeployment(ctx context.Con
 	if apierrors.IsNotFound(err) {
 		logger.Error(err, "rule-evaluator Deployment does not exist")
 		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := applyWorkloadOverrides(ctx, r.client, &deploy, &deploy.Spec.Template, RuleEvaluatorContainerName, spec); err != nil {
+		return err
+	}
+	return r.ensureRuleEvaluatorShards(ctx, &deploy, int(shards))
+}
+
+// ensureRuleEvaluatorShards creates or updates a copy of the rule-evaluator Deployment for
+// each further shard and deletes those of shards beyond the shard count. Shards are only
+// updated if they differ from the rule-evaluator.
+func (r *operatorConfigReconciler) ensureRuleEvaluatorShards(ctx context.Context, base *appsv1.Deployment, shards int) error {
+	for i := 1; i < shards; i++ {
+		deploy := ruleEvaluatorShardDeployment(base, i)
+
+		var current appsv1.Deployment
+		if err := r.client.Get(ctx, client.ObjectKeyFromObject(deploy), &current); apierrors.IsNotFound(err) {
+			if err := r.client.Create(ctx, deploy); err != nil {
+				return fmt.Errorf("create rule-evaluator shard %q: %w", deploy.Name, err)
+			}
+			continue
+		} else if err != nil {
+			return fmt.Errorf("get rule-evaluator shard %q: %w", deploy.Name, err)
+		}
+		// The replicas are scaled along with the rule-evaluator by the rules reconciler.
+		deploy.Spec.Replicas = current.Spec.Replicas
+		// The rule-evaluator is read from the API server, so the Deployment of an unchanged
+		// shard equals the current one including the defaulted fields.
+		if equality.Semantic.DeepEqual(deploy.Labels, current.Labels) &&
+			equality.Semantic.DeepEqual(deploy.OwnerReferences, current.OwnerReferences) &&
+			equality.Semantic.DeepEqual(deploy.Spec, current.Spec) {
+			continue
+		}
+		// Keep other metadata, such as the revision annotation of the Deployment controller.
+		update := current.DeepCopy()
+		update.Labels = deploy.Labels
+		update.OwnerReferences = deploy.OwnerReferences
+		update.Spec = deploy.Spec
+		if err := r.client.Update(ctx, update); err != nil {
+			return fmt.Errorf("update rule-evaluator shard %q: %w", deploy.Name, err)
+		}
+	}
+
+	var deploys appsv1.DeploymentList
+	if err := r.client.List(ctx, &deploys, client.InNamespace(r.opts.OperatorNamespace), client.HasLabels{labelRuleShard}); err != nil {
+		return fmt.Errorf("list rule-evaluator shards: %w", err)
+	}
+	for i := range deploys.Items {
+		deploy := &deploys.Items[i]
+		if shard, ok := ruleShardOf(deploy); !ok || shard < shards {
+			continue
+		}
+		if err := r.client.Delete(ctx, deploy); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("delete rule-evaluator shard %q: %w", deploy.Name, err)
+		}
 	}
-	return err
+	return nil
 }
 
 // makeAlertmanagerConfigs creates the alertmanager_config entries as described in
@@ -546,10 +634,15 @@ func (r *operatorConfigReconciler) makeAlertmanagerConfigs(ctx context.Context,
 	// If the default Alertmanager exists, append it to the list of spec.Alertmanagers.
 	var amSvc corev1.Service
 	if resourceErr := r.client.Get(ctx, amNamespacedName, &amSvc); resourceErr == nil {
-		// Alertmanager service should have one port defined, otherwise ignore.
+		// Prefer the port named after the Alertmanager and fall back to the first one.
 		if ports := amSvc.Spec.Ports; len(ports) > 0 {
-			// Assume first port on service is the correct endpoint.
 			port := ports[0].Port
+			for _, p := range ports {
+				if p.Name == NameAlertmanager {
+					port = p.Port
+					break
+				}
+			}
 			svcDNSName := fmt.Sprintf("%s.%s:%d", amSvc.Name, amSvc.Namespace, port)
 			cfg := promforkconfig.DefaultAlertmanagerConfig
 			cfg.ServiceDiscoveryConfigs = discovery.Configs{
@@ -651,55 +744,41 @@ func (r *operatorConfigReconciler) makeAlertmanagerConfigs(ctx context.Context,
 			cfg.HTTPClientConfig.TLSConfig = tlsCfg
 		}
 
-		// Configure discovery of AM endpoints via Kubernetes API.
-		cfg.ServiceDiscoveryConfigs = discovery.Configs{
-			&discoverykube.SDConfig{
-				// Must instantiate a default client config explicitly as the follow_redirects
-				// field lacks the omitempty tag. Thus it looks like we explicitly set it to false
-				// even if left empty after marshalling.
-				HTTPClientConfig: promcommonconfig.DefaultHTTPClientConfig,
-				Role:             discoverykube.RoleEndpoint,
-				NamespaceDiscovery: discoverykube.NamespaceDiscovery{
-					Names: []string{am.Namespace},
-				},
-			},
-		}
-		svcNameRE, err := relabel.NewRegexp(am.Name)
-		if err != nil {
-			return nil, nil, fmt.Errorf("cannot build regex from service name %q: %w", am.Name, err)
-		}
-		cfg.RelabelConfigs = append(cfg.RelabelConfigs, &relabel.Config{
-			Action:       relabel.Keep,
-			SourceLabels: prommodel.LabelNames{"__meta_kubernetes_endpoints_name"},
-			Regex:        svcNameRE,
-		})
-		if am.Port.StrVal != "" {
-			re, err := relabel.NewRegexp(am.Port.String())
+		switch {
+		case len(am.StaticURLs) > 0:
+			base, hosts, err := am.StaticURLsBase()
 			if err != nil {
-				return nil, nil, fmt.Errorf("cannot build regex from port %q: %w", am.Port, err)
+				return nil, nil, err
+			}
+			cfg.Scheme = base.Scheme
+			cfg.PathPrefix = base.Path
+			group := &targetgroup.Group{}
+			for _, host := range hosts {
+				group.Targets = append(group.Targets, prommodel.LabelSet{prommodel.AddressLabel: prommodel.LabelValue(host)})
+			}
+			cfg.ServiceDiscoveryConfigs = discovery.Configs{discovery.StaticConfig{group}}
+		case am.DNS != nil:
+			dnsCfg := discoverydns.DefaultSDConfig
+			dnsCfg.Names = am.DNS.Names
+			dnsCfg.Port = int(am.DNS.Port)
+			if am.DNS.Type != "" {
+				dnsCfg.Type = am.DNS.Type
 			}
-			cfg.RelabelConfigs = append(cfg.RelabelConfigs, &relabel.Config{
-				Action:       relabel.Keep,
-				SourceLabels: prommodel.LabelNames{"__meta_kubernetes_endpoint_port_name"},
-				Regex:        re,
-			})
-		} else if am.Port.IntVal != 0 {
-			// The endpoints object does not provide a meta label for the port number. If the endpoint
-			// is backed by a pod we can inspect the pod port number label, but to make it work in general
-			// we simply override the port in the address label.
-			// If the endpoints has multiple ports, this will create duplicate targets but they will be
-			// deduplicated by the discovery engine.
-			re, err := relabel.NewRegexp(`(.+):\d+`)
+			if am.DNS.RefreshInterval != "" {
+				dnsCfg.RefreshInterval, err = prommodel.ParseDuration(am.DNS.RefreshInterval)
+				if err != nil {
+					return nil, nil, fmt.Errorf("invalid DNS refresh interval: %w", err)
+				}
+			}
+			cfg.ServiceDiscoveryConfigs = discovery.Configs{&dnsCfg}
+		default:
+			cfg.ServiceDiscoveryConfigs, cfg.RelabelConfigs, err = kubernetesAlertmanagerDiscovery(&am)
 			if err != nil {
-				return nil, nil, fmt.Errorf("building address regex failed: %w", err)
+				return nil, nil, err
 			}
-			cfg.RelabelConfigs = append(cfg.RelabelConfigs, &relabel.Config{
-				Action:       relabel.Replace,
-				SourceLabels: prommodel.LabelNames{"__address__"},
-				Regex:        re,
-				TargetLabel:  "__address__",
-				Replacement:  fmt.Sprintf("$1:%d", am.Port.IntVal),
-			})
+		}
+		if cfg.AlertRelabelConfigs, err = am.AlertRelabelingConfigs(); err != nil {
+			return nil, nil, fmt.Errorf("invalid alert relabeling: %w", err)
 		}
 
 		// TODO(pintohutch): add support for basic_auth, oauth2, proxy_url, follow_redirects.
@@ -711,6 +790,73 @@ func (r *operatorConfigReconciler) makeAlertmanagerConfigs(ctx context.Context,
 	return configs, secretData, nil
 }
 
+// kubernetesAlertmanagerDiscovery returns the discovery and relabel configs that select the
+// Alertmanagers of the Endpoints or Service object referenced by the endpoint.
+func kubernetesAlertmanagerDiscovery(am *monitoringv1.AlertmanagerEndpoints) (discovery.Configs, []*relabel.Config, error) {
+	role, nameLabel, portNameLabel := discoverykube.RoleEndpoint, "__meta_kubernetes_endpoints_name", "__meta_kubernetes_endpoint_port_name"
+	if am.Role == "Service" {
+		role, nameLabel, portNameLabel = discoverykube.RoleService, "__meta_kubernetes_service_name", "__meta_kubernetes_service_port_name"
+	}
+	// Configure discovery of AM endpoints via Kubernetes API.
+	discoveryCfgs := discovery.Configs{
+		&discoverykube.SDConfig{
+			// Must instantiate a default client config explicitly as the follow_redirects
+			// field lacks the omitempty tag. Thus it looks like we explicitly set it to false
+			// even if left empty after marshalling.
+			HTTPClientConfig: promcommonconfig.DefaultHTTPClientConfig,
+			Role:             role,
+			NamespaceDiscovery: discoverykube.NamespaceDiscovery{
+				Names: []string{am.Namespace},
+			},
+		},
+	}
+	svcNameRE, err := relabel.NewRegexp(am.Name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot build regex from service name %q: %w", am.Name, err)
+	}
+	relabelCfgs := []*relabel.Config{{
+		Action:       relabel.Keep,
+		SourceLabels: prommodel.LabelNames{prommodel.LabelName(nameLabel)},
+		Regex:        svcNameRE,
+	}}
+	switch {
+	case am.Port.StrVal != "":
+		re, err := relabel.NewRegexp(am.Port.String())
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot build regex from port %q: %w", am.Port, err)
+		}
+		relabelCfgs = append(relabelCfgs, &relabel.Config{
+			Action:       relabel.Keep,
+			SourceLabels: prommodel.LabelNames{prommodel.LabelName(portNameLabel)},
+			Regex:        re,
+		})
+	case am.Port.IntVal != 0 && role == discoverykube.RoleService:
+		relabelCfgs = append(relabelCfgs, &relabel.Config{
+			Action:       relabel.Keep,
+			SourceLabels: prommodel.LabelNames{"__meta_kubernetes_service_port_number"},
+			Regex:        relabel.MustNewRegexp(strconv.Itoa(int(am.Port.IntVal))),
+		})
+	case am.Port.IntVal != 0:
+		// The endpoints object does not provide a meta label for the port number. If the endpoint
+		// is backed by a pod we can inspect the pod port number label, but to make it work in general
+		// we simply override the port in the address label.
+		// If the endpoints has multiple ports, this will create duplicate targets but they will be
+		// deduplicated by the discovery engine.
+		re, err := relabel.NewRegexp(`(.+):\d+`)
+		if err != nil {
+			return nil, nil, fmt.Errorf("building address regex failed: %w", err)
+		}
+		relabelCfgs = append(relabelCfgs, &relabel.Config{
+			Action:       relabel.Replace,
+			SourceLabels: prommodel.LabelNames{"__address__"},
+			Regex:        re,
+			TargetLabel:  "__address__",
+			Replacement:  fmt.Sprintf("$1:%d", am.Port.IntVal),
+		})
+	}
+	return discoveryCfgs, relabelCfgs, nil
+}
+
 // getSecretOrConfigMapBytes is a helper function to conditionally fetch
 // the secret or configmap selector payloads.
 func getSecretOrConfigMapBytes(ctx context.Context, c client.Reader, namespace string, scm *monitoringv1.SecretOrConfigMap) ([]byte, error) {
@@ -28,6 +28,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -423,7 +424,7 @@ route:
 			)
 			kubeClient := fakeClient.Build()
 			reconciler := newOperatorConfigReconciler(kubeClient, operatorOpts)
-			require.NoError(t, reconciler.ensureAlertmanagerConfigSecret(ctx, operatorConfig.ManagedAlertmanager))
+			require.NoError(t, reconciler.ensureAlertmanagerConfigSecret(ctx, operatorConfig.ManagedAlertmanager, &operatorConfig.Namespaces))
 
 			// Get output secret from gmp-system.
 			b, err := getSecretKeyBytes(ctx, kubeClient, DefaultOperatorNamespace, operatorConfig.ManagedAlertmanager.ConfigSecret)
@@ -433,3 +434,76 @@ route:
 		})
 	}
 }
+
+func TestMakeAlertmanagerConfigs(t *testing.T) {
+	opts := Options{
+		OperatorNamespace: DefaultOperatorNamespace,
+		PublicNamespace:   DefaultPublicNamespace,
+	}
+	kubeClient := newFakeClientBuilder().WithObjects(&corev1.Service{
+		ObjectMeta: v1.ObjectMeta{Namespace: DefaultOperatorNamespace, Name: NameAlertmanager},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "cluster", Port: 9094},
+				{Name: NameAlertmanager, Port: 9093},
+			},
+		},
+	}).Build()
+	reconciler := newOperatorConfigReconciler(kubeClient, opts)
+
+	configs, _, err := reconciler.makeAlertmanagerConfigs(t.Context(), &monitoringv1.AlertingSpec{
+		Alertmanagers: []monitoringv1.AlertmanagerEndpoints{
+			{
+				Namespace: "monitoring",
+				Name:      "alertmanager",
+				Role:      "Service",
+				Port:      intstr.FromInt32(9093),
+			},
+			{
+				StaticURLs: []string{"https://am-1.example.com/prefix", "https://am-2.example.com:8443/prefix"},
+				AlertRelabelConfigs: []monitoringv1.RelabelingRule{{
+					Action:       "keep",
+					SourceLabels: []string{"severity"},
+					Regex:        "critical",
+				}},
+			},
+			{
+				DNS: &monitoringv1.AlertmanagerDNSDiscovery{
+					Names: []string{"_web._tcp.alertmanager.example.com"},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	out, err := yaml.Marshal(promforkconfig.Config{
+		AlertingConfig: promforkconfig.AlertingConfig{AlertmanagerConfigs: configs},
+	})
+	require.NoError(t, err)
+	// The generated config must be loadable by the rule-evaluator.
+	cfg, err := promforkconfig.Load(string(out), false, nil)
+	require.NoError(t, err)
+	require.Len(t, cfg.AlertingConfig.AlertmanagerConfigs, 4)
+
+	for _, want := range []string{
+		// The managed Alertmanager is addressed through its named port.
+		"- alertmanager.gmp-system:9093",
+		"role: service",
+		"source_labels: [__meta_kubernetes_service_port_number]",
+		"scheme: https",
+		"path_prefix: /prefix",
+		"- am-2.example.com:8443",
+		"alert_relabel_configs:",
+		"- _web._tcp.alertmanager.example.com",
+		"type: SRV",
+	} {
+		require.Contains(t, string(out), want)
+	}
+
+	_, _, err = reconciler.makeAlertmanagerConfigs(t.Context(), &monitoringv1.AlertingSpec{
+		Alertmanagers: []monitoringv1.AlertmanagerEndpoints{{
+			StaticURLs: []string{"https://am-1.example.com/a", "http://am-2.example.com/a"},
+		}},
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,236 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	promconfig "github.com/prometheus/prometheus/config"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+// reasonQuotaExceeded is the condition reason of PodMonitorings that are not scraped because
+// they exceed the MonitoringQuota of their namespace.
+const reasonQuotaExceeded = "QuotaExceeded"
+
+// listQuotas returns the effective quota of each namespace that has MonitoringQuotas.
+func listQuotas(ctx context.Context, c client.Reader, opts ...client.ListOption) (map[string]monitoringv1.MonitoringQuotaSpec, error) {
+	var quotas monitoringv1.MonitoringQuotaList
+	if err := c.List(ctx, &quotas, opts...); err != nil {
+		return nil, fmt.Errorf("list MonitoringQuotas: %w", err)
+	}
+	byNamespace := map[string][]monitoringv1.MonitoringQuota{}
+	for _, q := range quotas.Items {
+		byNamespace[q.Namespace] = append(byNamespace[q.Namespace], q)
+	}
+	res := make(map[string]monitoringv1.MonitoringQuotaSpec, len(byNamespace))
+	for ns, qs := range byNamespace {
+		res[ns] = monitoringv1.EffectiveQuota(qs)
+	}
+	return res, nil
+}
+
+// applyQuota returns the scrape configs with their limits lowered to the quota. The input
+// configs may be shared with the config cache and are not modified.
+func applyQuota(quota monitoringv1.MonitoringQuotaSpec, cfgs []*promconfig.ScrapeConfig) []*promconfig.ScrapeConfig {
+	if quota.SamplesPerScrape == 0 && quota.TargetsPerEndpoint == 0 {
+		return cfgs
+	}
+	res := make([]*promconfig.ScrapeConfig, 0, len(cfgs))
+	for _, c := range cfgs {
+		clamped := *c
+		clamped.SampleLimit = clampLimit(clamped.SampleLimit, quota.SamplesPerScrape)
+		clamped.TargetLimit = clampLimit(clamped.TargetLimit, quota.TargetsPerEndpoint)
+		res = append(res, &clamped)
+	}
+	return res
+}
+
+// clampLimit lowers a Prometheus limit to the quota, where zero means no limit for both.
+func clampLimit(limit uint, quota uint64) uint {
+	if quota == 0 || (limit != 0 && uint64(limit) <= quota) {
+		return limit
+	}
+	return uint(quota)
+}
+
+func quotaExceededCondition(quota monitoringv1.MonitoringQuotaSpec) *monitoringv1.MonitoringCondition {
+	return &monitoringv1.MonitoringCondition{
+		Type:    monitoringv1.ConfigurationCreateSuccess,
+		Status:  corev1.ConditionFalse,
+		Reason:  reasonQuotaExceeded,
+		Message: fmt.Sprintf("scrape endpoints in the namespace exceed the MonitoringQuota limit of %d", quota.Endpoints),
+	}
+}
+
+// podMonitoringQuotaValidator rejects PodMonitorings that exceed the MonitoringQuota of their
+// namespace.
+type podMonitoringQuotaValidator struct {
+	client client.Reader
+}
+
+func (v *podMonitoringQuotaValidator) ValidateCreate(ctx context.Context, o runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, o.(*monitoringv1.PodMonitoring))
+}
+
+func (v *podMonitoringQuotaValidator) ValidateUpdate(ctx context.Context, _, o runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, o.(*monitoringv1.PodMonitoring))
+}
+
+func (v *podMonitoringQuotaValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *podMonitoringQuotaValidator) validate(ctx context.Context, pm *monitoringv1.PodMonitoring) (admission.Warnings, error) {
+	quotas, err := listQuotas(ctx, v.client, client.InNamespace(pm.Namespace))
+	if err != nil {
+		return nil, err
+	}
+	quota, ok := quotas[pm.Namespace]
+	if !ok {
+		return nil, nil
+	}
+	var warnings admission.Warnings
+	if quota.SamplesPerScrape > 0 && pm.Spec.Limits != nil && pm.Spec.Limits.Samples > quota.SamplesPerScrape {
+		warnings = append(warnings, fmt.Sprintf("sample limit %d is lowered to %d by the MonitoringQuota of the namespace", pm.Spec.Limits.Samples, quota.SamplesPerScrape))
+	}
+	if quota.Endpoints == 0 {
+		return warnings, nil
+	}
+
+	var podMons monitoringv1.PodMonitoringList
+	if err := v.client.List(ctx, &podMons, client.InNamespace(pm.Namespace)); err != nil {
+		return nil, fmt.Errorf("list PodMonitorings: %w", err)
+	}
+	endpoints := uint64(len(pm.Spec.Endpoints))
+	for _, other := range podMons.Items {
+		if other.Name != pm.Name {
+			endpoints += uint64(len(other.Spec.Endpoints))
+		}
+	}
+	if endpoints > quota.Endpoints {
+		return warnings, fmt.Errorf("scrape endpoints in namespace %q would exceed the MonitoringQuota limit: %d > %d", pm.Namespace, endpoints, quota.Endpoints)
+	}
+	return warnings, nil
+}
+
+func setupQuotaController(op *Operator) error {
+	c := op.manager.GetClient()
+	err := ctrl.NewControllerManagedBy(op.manager).
+		Named("monitoring-quota").
+		// Status updates of quotas themselves must not trigger a reconciliation.
+		For(
+			&monitoringv1.MonitoringQuota{},
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		// Changes to PodMonitorings, including their target status, change the usage.
+		Watches(
+			&monitoringv1.PodMonitoring{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []reconcile.Request {
+				return quotaRequests(ctx, c, o.GetNamespace())
+			}),
+		).
+		Complete(newQuotaReconciler(c))
+	if err != nil {
+		return fmt.Errorf("create monitoring quota controller: %w", err)
+	}
+	return nil
+}
+
+// quotaRequests returns the requests of all MonitoringQuotas in the namespace.
+func quotaRequests(ctx context.Context, c client.Reader, namespace string) []reconcile.Request {
+	logger, _ := logr.FromContext(ctx)
+
+	var quotas monitoringv1.MonitoringQuotaList
+	if err := c.List(ctx, &quotas, client.InNamespace(namespace)); err != nil {
+		logger.Error(err, "list MonitoringQuotas", "namespace", namespace)
+		return nil
+	}
+	reqs := make([]reconcile.Request, 0, len(quotas.Items))
+	for _, q := range quotas.Items {
+		reqs = append(reqs, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: q.Namespace, Name: q.Name},
+		})
+	}
+	return reqs
+}
+
+// quotaReconciler reports the usage of the namespace in the status of MonitoringQuotas.
+type quotaReconciler struct {
+	client client.Client
+}
+
+func newQuotaReconciler(c client.Client) *quotaReconciler {
+	return &quotaReconciler{
+		client: c,
+	}
+}
+
+func (r *quotaReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	var quota monitoringv1.MonitoringQuota
+	if err := r.client.Get(ctx, req.NamespacedName, &quota); apierrors.IsNotFound(err) {
+		return reconcile.Result{}, nil
+	} else if err != nil {
+		return reconcile.Result{}, fmt.Errorf("get MonitoringQuota: %w", err)
+	}
+
+	var podMons monitoringv1.PodMonitoringList
+	if err := r.client.List(ctx, &podMons, client.InNamespace(req.Namespace)); err != nil {
+		return reconcile.Result{}, fmt.Errorf("list PodMonitorings: %w", err)
+	}
+	used := monitoringv1.MonitoringQuotaUsage{
+		PodMonitorings: int64(len(podMons.Items)),
+	}
+	for _, pm := range podMons.Items {
+		used.Endpoints += int64(len(pm.Spec.Endpoints))
+		for _, s := range pm.Status.EndpointStatuses {
+			used.Targets += s.ActiveTargets
+		}
+	}
+	if quota.Status.Used == used && quota.Status.ObservedGeneration == quota.Generation {
+		return reconcile.Result{}, nil
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"status": monitoringv1.MonitoringQuotaStatus{
+			ObservedGeneration: quota.Generation,
+			Used:               used,
+			LastUpdateTime:     metav1.Now(),
+		},
+	})
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.client.Status().Patch(ctx, &quota, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return reconcile.Result{}, fmt.Errorf("patch MonitoringQuota status: %w", err)
+	}
+	return reconcile.Result{}, nil
+}
@@ -0,0 +1,194 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/testr"
+	"github.com/google/go-cmp/cmp"
+	promconfig "github.com/prometheus/prometheus/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+func newQuota(name string, spec monitoringv1.MonitoringQuotaSpec) *monitoringv1.MonitoringQuota {
+	return &monitoringv1.MonitoringQuota{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: name, Generation: 1},
+		Spec:       spec,
+	}
+}
+
+func newQuotaPodMonitoring(name string, created time.Time, endpoints int, limits *monitoringv1.ScrapeLimits) *monitoringv1.PodMonitoring {
+	pm := &monitoringv1.PodMonitoring{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "team-a",
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: monitoringv1.PodMonitoringSpec{Limits: limits},
+	}
+	for i := range endpoints {
+		pm.Spec.Endpoints = append(pm.Spec.Endpoints, monitoringv1.ScrapeEndpoint{
+			Port:     intstr.FromInt(8080 + i),
+			Interval: "10s",
+		})
+	}
+	return pm
+}
+
+func TestApplyQuota(t *testing.T) {
+	cfgs := []*promconfig.ScrapeConfig{
+		{JobName: "unlimited"},
+		{JobName: "lower", SampleLimit: 10, TargetLimit: 1},
+		{JobName: "higher", SampleLimit: 1000, TargetLimit: 100},
+	}
+	got := applyQuota(monitoringv1.MonitoringQuotaSpec{SamplesPerScrape: 100, TargetsPerEndpoint: 5}, cfgs)
+
+	want := map[string][2]uint{
+		"unlimited": {100, 5},
+		"lower":     {10, 1},
+		"higher":    {100, 5},
+	}
+	for _, c := range got {
+		if diff := cmp.Diff(want[c.JobName], [2]uint{c.SampleLimit, c.TargetLimit}); diff != "" {
+			t.Errorf("unexpected limits of job %q (-want, +got): %s", c.JobName, diff)
+		}
+	}
+	// The input configs may be cached and must not be modified.
+	if cfgs[0].SampleLimit != 0 || cfgs[2].SampleLimit != 1000 {
+		t.Errorf("input configs were modified")
+	}
+}
+
+func TestPodMonitoringQuotaValidator(t *testing.T) {
+	now := time.Now()
+	c := newFakeClientBuilder().WithObjects(
+		newQuota("a", monitoringv1.MonitoringQuotaSpec{Endpoints: 5, SamplesPerScrape: 1000}),
+		newQuota("b", monitoringv1.MonitoringQuotaSpec{Endpoints: 3}),
+		newQuotaPodMonitoring("existing", now, 2, nil),
+	).Build()
+	v := &podMonitoringQuotaValidator{client: c}
+
+	// Updating the existing PodMonitoring doesn't count its previous endpoints.
+	if _, err := v.ValidateUpdate(t.Context(), nil, newQuotaPodMonitoring("existing", now, 3, nil)); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if _, err := v.ValidateCreate(t.Context(), newQuotaPodMonitoring("new", now, 1, nil)); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	// The more restrictive quota applies.
+	if _, err := v.ValidateCreate(t.Context(), newQuotaPodMonitoring("new", now, 2, nil)); err == nil {
+		t.Error("expected error for PodMonitoring exceeding the quota")
+	}
+	warnings, err := v.ValidateCreate(t.Context(), newQuotaPodMonitoring("new", now, 1, &monitoringv1.ScrapeLimits{Samples: 5000}))
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("expected a warning about the lowered sample limit, got %v", warnings)
+	}
+
+	// Namespaces without quotas are not limited.
+	pm := newQuotaPodMonitoring("other", now, 10, nil)
+	pm.Namespace = "team-b"
+	if warnings, err := v.ValidateCreate(t.Context(), pm); err != nil || len(warnings) > 0 {
+		t.Errorf("unexpected result: %v, %v", warnings, err)
+	}
+}
+
+func TestCollectorConfigQuota(t *testing.T) {
+	ctx := logr.NewContext(t.Context(), testr.New(t))
+	opts := Options{
+		ProjectID: "test-proj",
+		Location:  "us-central1",
+		Cluster:   "test-cluster",
+	}
+	if err := opts.defaultAndValidate(logr.Discard()); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	older := newQuotaPodMonitoring("b-older", now.Add(-time.Hour), 2, &monitoringv1.ScrapeLimits{Samples: 5000})
+	newer := newQuotaPodMonitoring("a-newer", now, 1, nil)
+	c := newFakeClientBuilder().WithObjects(
+		newQuota("quota", monitoringv1.MonitoringQuotaSpec{Endpoints: 2, SamplesPerScrape: 1000}),
+		older,
+		newer,
+	).Build()
+
+	r := newCollectionReconciler(c, opts)
+	cfg, _, err := r.makeCollectorConfig(ctx, &monitoringv1.CollectionSpec{}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]uint{}
+	for _, sc := range cfg.ScrapeConfigs {
+		got[sc.JobName] = sc.SampleLimit
+	}
+	want := map[string]uint{
+		"PodMonitoring/team-a/b-older/8080": 1000,
+		"PodMonitoring/team-a/b-older/8081": 1000,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected scrape jobs and sample limits (-want, +got): %s", diff)
+	}
+
+	if err := r.ensureCollectorConfig(ctx, &monitoringv1.CollectionSpec{}, monitoringv1.CompressionNone, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(newer), newer); err != nil {
+		t.Fatal(err)
+	}
+	conds := newer.Status.Conditions
+	if len(conds) != 1 || conds[0].Status != corev1.ConditionFalse || conds[0].Reason != reasonQuotaExceeded {
+		t.Errorf("expected PodMonitoring to exceed the quota, got conditions %v", conds)
+	}
+}
+
+func TestQuotaReconciler(t *testing.T) {
+	now := time.Now()
+	withTargets := newQuotaPodMonitoring("with-targets", now, 2, nil)
+	withTargets.Status.EndpointStatuses = []monitoringv1.ScrapeEndpointStatus{
+		{Name: "PodMonitoring/team-a/with-targets/8080", ActiveTargets: 3},
+		{Name: "PodMonitoring/team-a/with-targets/8081", ActiveTargets: 4},
+	}
+	quota := newQuota("quota", monitoringv1.MonitoringQuotaSpec{Endpoints: 10})
+	c := newFakeClientBuilder().
+		WithStatusSubresource(&monitoringv1.MonitoringQuota{}).
+		WithObjects(quota, withTargets, newQuotaPodMonitoring("other", now, 1, nil)).
+		Build()
+
+	r := newQuotaReconciler(c)
+	if _, err := r.Reconcile(t.Context(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(quota)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(t.Context(), client.ObjectKeyFromObject(quota), quota); err != nil {
+		t.Fatal(err)
+	}
+	want := monitoringv1.MonitoringQuotaUsage{PodMonitorings: 2, Endpoints: 3, Targets: 7}
+	if diff := cmp.Diff(want, quota.Status.Used); diff != "" {
+		t.Errorf("unexpected usage (-want, +got): %s", diff)
+	}
+	if quota.Status.ObservedGeneration != quota.Generation {
+		t.Errorf("expected observed generation %d, got %d", quota.Generation, quota.Status.ObservedGeneration)
+	}
+}
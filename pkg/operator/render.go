@@ -0,0 +1,275 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"slices"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/GoogleCloudPlatform/prometheus-engine/pkg/configshard"
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+// File names of the generated configuration in a RenderResult.
+const (
+	RenderCollectorConfigFile     = "collector/" + configFilename
+	RenderRuleEvaluatorConfigFile = "rule-evaluator/" + configFilename
+	renderRulesDir                = "rules"
+)
+
+// RenderResult holds the configuration the operator would generate for a set of resources.
+type RenderResult struct {
+	// Files maps file names to their content. Rule files are placed in the "rules" directory
+	// under the same name the rule-evaluator would load them with. If rule groups are split
+	// across shards, the rule files of each further shard are placed in a "shard-<index>"
+	// subdirectory.
+	Files map[string]string
+	// Failures lists resources for which no configuration could be generated. Those
+	// resources are omitted from the generated configuration, just like in a cluster.
+	Failures []RenderFailure
+}
+
+// RenderFailure describes a resource that failed configuration generation.
+type RenderFailure struct {
+	// Key identifies the resource, e.g. "PodMonitoring/default/example".
+	Key     string
+	Message string
+}
+
+// Render generates the collector configuration, the rule-evaluator configuration and the rule
+// files for the given objects without access to a cluster.
+//
+// It runs the same reconciliation logic as the operator against an in-memory client. Besides
+// the monitoring resources, objects may include Secrets, ConfigMaps and Services that are
+// referenced by the configuration, and Namespaces whose labels are matched by the namespaces
+// filter. The OperatorConfig is only considered if it is in the public namespace and has the
+// expected name.
+func Render(ctx context.Context, logger logr.Logger, opts Options, objs ...client.Object) (*RenderResult, error) {
+	if err := opts.defaultAndValidate(logger); err != nil {
+		return nil, fmt.Errorf("invalid options: %w", err)
+	}
+	sc, err := NewScheme()
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize Kubernetes scheme: %w", err)
+	}
+	ctx = logr.NewContext(ctx, logger)
+
+	// Like the mutating webhook would, default the OperatorConfig before it is stored.
+	config := &monitoringv1.OperatorConfig{}
+	config.Namespace = opts.PublicNamespace
+	config.Name = NameOperatorConfig
+	objs = slices.Clone(objs)
+	for i, obj := range objs {
+		oc, ok := obj.(*monitoringv1.OperatorConfig)
+		if !ok || oc.Namespace != config.Namespace || oc.Name != config.Name {
+			continue
+		}
+		config = oc.DeepCopy()
+		objs[i] = config
+	}
+	defaulter := &operatorConfigDefaulter{
+		projectID: opts.ProjectID,
+		location:  opts.Location,
+		cluster:   opts.Cluster,
+	}
+	defaulter.update(config)
+
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(sc).
+		WithObjects(objs...).
+		WithStatusSubresource(
+			&monitoringv1.PodMonitoring{},
+			&monitoringv1.ClusterPodMonitoring{},
+			&monitoringv1.ClusterNodeMonitoring{},
+			&monitoringv1.Rules{},
+			&monitoringv1.ClusterRules{},
+			&monitoringv1.GlobalRules{},
+		).
+		Build()
+
+	collection := newCollectionReconciler(kubeClient, opts)
+	if err := collection.ensureCollectorConfig(ctx, &config.Collection, config.Features.Config.Compression, config.Exports, &config.Namespaces, &config.Features.SelfMonitoring); err != nil {
+		return nil, fmt.Errorf("generate collector config: %w", err)
+	}
+	rules := newRulesReconciler(kubeClient, opts)
+	projectID, location, cluster := resolveLabels(opts.ProjectID, opts.Location, opts.Cluster, config.Rules.ExternalLabels)
+	if err := rules.ensureRuleConfigs(ctx, projectID, location, cluster, config.Features.Config.Compression, &config.Namespaces, config.Features.SelfMonitoring.Enabled, ruleShardCount(&config.Rules), config.Rules.AllowedQueryProjectIDs); err != nil {
+		return nil, fmt.Errorf("generate rule files: %w", err)
+	}
+	operatorConfig := newOperatorConfigReconciler(kubeClient, opts)
+	if _, err := operatorConfig.ensureRuleEvaluatorConfig(ctx, &config.Rules); err != nil {
+		return nil, fmt.Errorf("generate rule-evaluator config: %w", err)
+	}
+
+	result := &RenderResult{Files: map[string]string{}}
+	if err := result.addConfigMap(ctx, kubeClient, opts.OperatorNamespace, NameCollector, func(string) string {
+		return RenderCollectorConfigFile
+	}); err != nil {
+		return nil, err
+	}
+	if err := result.addConfigMap(ctx, kubeClient, opts.OperatorNamespace, NameRuleEvaluator, func(string) string {
+		return RenderRuleEvaluatorConfigFile
+	}); err != nil {
+		return nil, err
+	}
+	// The rule files of further shards are placed in a directory per shard.
+	for i := range int(ruleShardCount(&config.Rules)) {
+		dir := renderRulesDir
+		if i > 0 {
+			dir = path.Join(renderRulesDir, fmt.Sprintf("shard-%d", i))
+		}
+		if err := result.addConfigMap(ctx, kubeClient, opts.OperatorNamespace, ruleShardName(nameRulesGenerated, i), func(key string) string {
+			return path.Join(dir, key)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := result.addFailures(ctx, kubeClient, sc); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// addConfigMap adds all keys of the given generated ConfigMap to the result files.
+func (r *RenderResult) addConfigMap(ctx context.Context, c client.Reader, namespace, name string, filename func(string) string) error {
+	var cm corev1.ConfigMap
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &cm); apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("get generated ConfigMap %q: %w", name, err)
+	}
+	files := map[string][]byte{}
+	if manifest, ok := cm.Data[configshard.ManifestKey]; ok {
+		m, err := configshard.ParseManifest([]byte(manifest))
+		if err != nil {
+			return err
+		}
+		files, err = configshard.Assemble(ctx, m, func(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+			var shard corev1.ConfigMap
+			err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &shard)
+			return &shard, err
+		})
+		if err != nil {
+			return fmt.Errorf("assemble generated ConfigMap %q: %w", name, err)
+		}
+	} else {
+		for key, data := range cm.Data {
+			files[key] = []byte(data)
+		}
+		maps.Copy(files, cm.BinaryData)
+	}
+	for key, data := range files {
+		if bytes.HasPrefix(data, gzipMagic) {
+			b, err := gunzipData(data)
+			if err != nil {
+				return fmt.Errorf("decompress key %q of generated ConfigMap %q: %w", key, name, err)
+			}
+			data = b
+		}
+		r.Files[filename(key)] = string(data)
+	}
+	return nil
+}
+
+// gzipMagic is the header of gzip compressed data.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// addFailures collects resources whose status reports failed configuration generation.
+func (r *RenderResult) addFailures(ctx context.Context, c client.Reader, sc *runtime.Scheme) error {
+	var (
+		podMons         monitoringv1.PodMonitoringList
+		clusterPodMons  monitoringv1.ClusterPodMonitoringList
+		clusterNodeMons monitoringv1.ClusterNodeMonitoringList
+		rules           monitoringv1.RulesList
+		clusterRules    monitoringv1.ClusterRulesList
+		globalRules     monitoringv1.GlobalRulesList
+	)
+	var objs []monitoringv1.MonitoringCRD
+	for _, list := range []client.ObjectList{&podMons, &clusterPodMons, &clusterNodeMons, &rules, &clusterRules, &globalRules} {
+		if err := c.List(ctx, list); err != nil {
+			return fmt.Errorf("list %T: %w", list, err)
+		}
+	}
+	for i := range podMons.Items {
+		objs = append(objs, &podMons.Items[i])
+	}
+	for i := range clusterPodMons.Items {
+		objs = append(objs, &clusterPodMons.Items[i])
+	}
+	for i := range clusterNodeMons.Items {
+		objs = append(objs, &clusterNodeMons.Items[i])
+	}
+	for i := range rules.Items {
+		objs = append(objs, &rules.Items[i])
+	}
+	for i := range clusterRules.Items {
+		objs = append(objs, &clusterRules.Items[i])
+	}
+	for i := range globalRules.Items {
+		objs = append(objs, &globalRules.Items[i])
+	}
+
+	for _, obj := range objs {
+		for _, cond := range obj.GetMonitoringStatus().Conditions {
+			if cond.Type != monitoringv1.ConfigurationCreateSuccess || cond.Status != corev1.ConditionFalse {
+				continue
+			}
+			msg := cond.Message
+			if cond.Reason != "" {
+				msg = fmt.Sprintf("%s: %s", msg, cond.Reason)
+			}
+			gvk, err := apiutil.GVKForObject(obj, sc)
+			if err != nil {
+				return err
+			}
+			key := fmt.Sprintf("%s/%s", gvk.Kind, obj.GetName())
+			if obj.GetNamespace() != "" {
+				key = fmt.Sprintf("%s/%s/%s", gvk.Kind, obj.GetNamespace(), obj.GetName())
+			}
+			r.Failures = append(r.Failures, RenderFailure{
+				Key:     key,
+				Message: msg,
+			})
+		}
+	}
+	slices.SortFunc(r.Failures, func(a, b RenderFailure) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+	return nil
+}
+
+func gunzipData(data []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	return io.ReadAll(gz)
+}
@@ -0,0 +1,193 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+func TestRender(t *testing.T) {
+	opts := Options{
+		ProjectID: "test-proj",
+		Location:  "us-central1",
+		Cluster:   "test-cluster",
+	}
+	podMon := &monitoringv1.PodMonitoring{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "app"},
+		Spec: monitoringv1.PodMonitoringSpec{
+			Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "foo"}},
+			Endpoints: []monitoringv1.ScrapeEndpoint{{
+				Port:     intstr.FromString("metrics"),
+				Interval: "10s",
+			}},
+		},
+	}
+	rules := &monitoringv1.Rules{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "good"},
+		Spec: monitoringv1.RulesSpec{
+			Groups: []monitoringv1.RuleGroup{{
+				Name:     "group",
+				Interval: "30s",
+				Rules:    []monitoringv1.Rule{{Record: "job:up:sum", Expr: "sum by(job) (up)"}},
+			}},
+		},
+	}
+	badRules := &monitoringv1.Rules{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "bad"},
+		Spec: monitoringv1.RulesSpec{
+			Groups: []monitoringv1.RuleGroup{{
+				Name:     "group",
+				Interval: "30s",
+				Rules:    []monitoringv1.Rule{{Record: "foo", Expr: "sum by(("}},
+			}},
+		},
+	}
+
+	testCases := []struct {
+		desc         string
+		objs         func() []client.Object
+		wantFiles    []string
+		wantContains map[string][]string
+		wantFailures []string
+	}{
+		{
+			desc: "no objects",
+			objs: func() []client.Object { return nil },
+			wantFiles: []string{
+				RenderCollectorConfigFile,
+				RenderRuleEvaluatorConfigFile,
+				"rules/empty.yaml",
+			},
+		},
+		{
+			desc: "scrape and rules",
+			objs: func() []client.Object { return []client.Object{podMon, rules} },
+			wantFiles: []string{
+				RenderCollectorConfigFile,
+				RenderRuleEvaluatorConfigFile,
+				"rules/empty.yaml",
+				"rules/rules__ns1__good.yaml",
+			},
+			wantContains: map[string][]string{
+				RenderCollectorConfigFile:     {"job_name: PodMonitoring/ns1/app/metrics", "scrape_interval: 10s"},
+				"rules/rules__ns1__good.yaml": {"record: job:up:sum", "project_id: test-proj", "cluster: test-cluster"},
+			},
+		},
+		{
+			desc: "gzip compression",
+			objs: func() []client.Object {
+				return []client.Object{podMon, &monitoringv1.OperatorConfig{
+					ObjectMeta: metav1.ObjectMeta{Namespace: DefaultPublicNamespace, Name: NameOperatorConfig},
+					Features: monitoringv1.OperatorFeatures{
+						Config: monitoringv1.ConfigSpec{Compression: monitoringv1.CompressionGzip},
+					},
+				}}
+			},
+			wantFiles: []string{
+				RenderCollectorConfigFile,
+				RenderRuleEvaluatorConfigFile,
+				"rules/empty.yaml",
+			},
+			wantContains: map[string][]string{
+				RenderCollectorConfigFile: {"job_name: PodMonitoring/ns1/app/metrics"},
+			},
+		},
+		{
+			desc: "invalid rules",
+			objs: func() []client.Object { return []client.Object{rules, badRules} },
+			wantFiles: []string{
+				RenderCollectorConfigFile,
+				RenderRuleEvaluatorConfigFile,
+				"rules/empty.yaml",
+				"rules/rules__ns1__good.yaml",
+			},
+			wantFailures: []string{"Rules/ns2/bad"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			res, err := Render(t.Context(), logr.Discard(), opts, tc.objs()...)
+			if err != nil {
+				t.Fatalf("render: %s", err)
+			}
+			if diff := cmp.Diff(tc.wantFiles, slices.Sorted(maps.Keys(res.Files))); diff != "" {
+				t.Errorf("unexpected files (-want, +got): %s", diff)
+			}
+			for file, substrs := range tc.wantContains {
+				for _, s := range substrs {
+					if !strings.Contains(res.Files[file], s) {
+						t.Errorf("expected %q to contain %q, got:\n%s", file, s, res.Files[file])
+					}
+				}
+			}
+			var failures []string
+			for _, f := range res.Failures {
+				if f.Message == "" {
+					t.Errorf("expected failure message for %q", f.Key)
+				}
+				failures = append(failures, f.Key)
+			}
+			if diff := cmp.Diff(tc.wantFailures, failures); diff != "" {
+				t.Errorf("unexpected failures (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestRenderInvalidOptions(t *testing.T) {
+	if _, err := Render(t.Context(), logr.Discard(), Options{Cluster: "test-cluster"}); err == nil {
+		t.Fatal("expected error for missing project ID")
+	}
+}
+
+func TestRenderShardedConfig(t *testing.T) {
+	prevMax := maxConfigMapDataSize
+	maxConfigMapDataSize = 200
+	t.Cleanup(func() { maxConfigMapDataSize = prevMax })
+
+	opts := Options{
+		ProjectID: "test-proj",
+		Location:  "us-central1",
+		Cluster:   "test-cluster",
+	}
+	podMon := &monitoringv1.PodMonitoring{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "app"},
+		Spec: monitoringv1.PodMonitoringSpec{
+			Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "foo"}},
+			Endpoints: []monitoringv1.ScrapeEndpoint{{
+				Port:     intstr.FromString("metrics"),
+				Interval: "10s",
+			}},
+		},
+	}
+	res, err := Render(t.Context(), logr.Discard(), opts, podMon)
+	if err != nil {
+		t.Fatalf("render: %s", err)
+	}
+	if !strings.Contains(res.Files[RenderCollectorConfigFile], "job_name: PodMonitoring/ns1/app/metrics") {
+		t.Errorf("expected reassembled collector config, got:\n%s", res.Files[RenderCollectorConfigFile])
+	}
+}
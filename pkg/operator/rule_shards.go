@@ -0,0 +1,160 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+// labelRuleShard is set on the rule ConfigMaps and rule-evaluator Deployments of each shard
+// to the index of the shard.
+const labelRuleShard = "monitoring.googleapis.com/rule-shard"
+
+// ruleShardCount returns the number of shards rule groups are split across.
+func ruleShardCount(spec *monitoringv1.RuleEvaluatorSpec) int32 {
+	if spec.Shards < 1 {
+		return 1
+	}
+	return spec.Shards
+}
+
+// ruleShardName returns the name of the object of the given shard. The first shard uses the
+// name of the object deployed with the manifests, so that a single shard matches the
+// unsharded setup.
+func ruleShardName(name string, shard int) string {
+	if shard == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s-shard-%d", name, shard)
+}
+
+// ruleShardOf returns the shard index of an object labeled with labelRuleShard.
+func ruleShardOf(obj client.Object) (int, bool) {
+	v, ok := obj.GetLabels()[labelRuleShard]
+	if !ok {
+		return 0, false
+	}
+	shard, err := strconv.Atoi(v)
+	if err != nil || shard < 0 {
+		return 0, false
+	}
+	return shard, true
+}
+
+// ruleGroupShard returns the shard of a rule group, given the key of the object it belongs to.
+// The assignment only depends on the names, so it's stable across operator restarts and
+// changes to other rule groups.
+func ruleGroupShard(key, group string, shards int32) int {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	h.Write([]byte{'/'})
+	h.Write([]byte(group))
+	return int(h.Sum64() % uint64(shards))
+}
+
+// shardRuleFiles generates the rule file of each shard from the rule groups assigned to it.
+// Shards without any of the rule groups have an empty file.
+func shardRuleFiles(key string, groups []monitoringv1.RuleGroup, shards int32, generate func([]monitoringv1.RuleGroup) (string, error)) ([]string, error) {
+	if shards <= 1 {
+		result, err := generate(groups)
+		if err != nil {
+			return nil, err
+		}
+		return []string{result}, nil
+	}
+	byShard := make([][]monitoringv1.RuleGroup, shards)
+	for _, g := range groups {
+		i := ruleGroupShard(key, g.Name, shards)
+		byShard[i] = append(byShard[i], g)
+	}
+	files := make([]string, shards)
+	for i, groups := range byShard {
+		if len(groups) == 0 {
+			continue
+		}
+		var err error
+		if files[i], err = generate(groups); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// ruleEvaluatorShardDeployment returns the Deployment evaluating the given shard. It's a copy
+// of the rule-evaluator Deployment that loads the rules of the shard and elects its own leader.
+func ruleEvaluatorShardDeployment(base *appsv1.Deployment, shard int) *appsv1.Deployment {
+	name := ruleShardName(NameRuleEvaluator, shard)
+	value := strconv.Itoa(shard)
+
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: base.Namespace,
+			Name:      name,
+			Labels:    withLabel(base.Labels, labelRuleShard, value),
+			// Shards are garbage collected together with the rule-evaluator.
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: appsv1.SchemeGroupVersion.String(),
+				Kind:       "Deployment",
+				Name:       base.Name,
+				UID:        base.UID,
+			}},
+		},
+		Spec: *base.Spec.DeepCopy(),
+	}
+	// The shard label keeps the selector from matching the pods of the rule-evaluator.
+	// The selector of the rule-evaluator may still match the pods of the shards, but
+	// Deployments only adopt orphaned pods.
+	if deploy.Spec.Selector == nil {
+		deploy.Spec.Selector = &metav1.LabelSelector{}
+	}
+	deploy.Spec.Selector.MatchLabels = withLabel(deploy.Spec.Selector.MatchLabels, labelRuleShard, value)
+	deploy.Spec.Template.Labels = withLabel(deploy.Spec.Template.Labels, labelRuleShard, value)
+
+	podSpec := &deploy.Spec.Template.Spec
+	for i := range podSpec.Volumes {
+		if cm := podSpec.Volumes[i].ConfigMap; cm != nil && cm.Name == nameRulesGenerated {
+			cm.Name = ruleShardName(nameRulesGenerated, shard)
+		}
+	}
+	for i := range podSpec.Containers {
+		args := podSpec.Containers[i].Args
+		for j, arg := range args {
+			if strings.HasPrefix(arg, "--export.ha.kube.name=") {
+				args[j] = "--export.ha.kube.name=" + name
+			}
+		}
+	}
+	return deploy
+}
+
+// withLabel returns a copy of the labels with the given label set.
+func withLabel(labels map[string]string, name, value string) map[string]string {
+	res := maps.Clone(labels)
+	if res == nil {
+		res = map[string]string{}
+	}
+	res[name] = value
+	return res
+}
@@ -0,0 +1,306 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/prometheus/model/rulefmt"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+func TestShardRuleFiles(t *testing.T) {
+	var groups []monitoringv1.RuleGroup
+	for i := range 20 {
+		groups = append(groups, monitoringv1.RuleGroup{
+			Name:  fmt.Sprintf("group-%d", i),
+			Rules: []monitoringv1.Rule{{Record: "foo", Expr: "bar"}},
+		})
+	}
+	rules := &monitoringv1.Rules{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "rules"},
+		Spec:       monitoringv1.RulesSpec{Groups: groups},
+	}
+	generate := func(groups []monitoringv1.RuleGroup) (string, error) {
+		shard := *rules
+		shard.Spec.Groups = groups
+		return shard.RuleGroupsConfig("", "", "")
+	}
+
+	files, err := shardRuleFiles("ns/rules", groups, 3, generate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %d", len(files))
+	}
+	var got []string
+	for i, file := range files {
+		for _, name := range ruleGroupNames(t, file) {
+			if shard := ruleGroupShard("ns/rules", name, 3); shard != i {
+				t.Errorf("group %q in shard %d, expected shard %d", name, i, shard)
+			}
+			got = append(got, name)
+		}
+	}
+	slices.Sort(got)
+	want := make([]string, 0, len(groups))
+	for _, g := range groups {
+		want = append(want, g.Name)
+	}
+	slices.Sort(want)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected rule groups across shards (-want, +got): %s", diff)
+	}
+
+	// An invalid rule group fails generation regardless of its shard.
+	invalid := append(slices.Clone(groups), monitoringv1.RuleGroup{
+		Name:  "invalid",
+		Rules: []monitoringv1.Rule{{Record: "foo", Expr: "bar("}},
+	})
+	if _, err := shardRuleFiles("ns/rules", invalid, 3, generate); err == nil {
+		t.Error("expected error for invalid rule group")
+	}
+}
+
+func TestEnsureRuleConfigsShards(t *testing.T) {
+	var groups []monitoringv1.RuleGroup
+	for i := range 10 {
+		groups = append(groups, monitoringv1.RuleGroup{
+			Name:  fmt.Sprintf("group-%d", i),
+			Rules: []monitoringv1.Rule{{Record: "foo", Expr: "bar"}},
+		})
+	}
+	kubeClient := newFakeClientBuilder().
+		WithObjects(&monitoringv1.Rules{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "rules"},
+			Spec:       monitoringv1.RulesSpec{Groups: groups},
+		}).
+		Build()
+	r := rulesReconciler{
+		client: kubeClient,
+		opts:   Options{OperatorNamespace: "gmp-system"},
+	}
+
+	// shardGroups returns the rule groups in the ConfigMap of each shard.
+	shardGroups := func(shards int) [][]string {
+		res := make([][]string, shards)
+		for i := range shards {
+			var cm corev1.ConfigMap
+			if err := kubeClient.Get(t.Context(), client.ObjectKey{Namespace: "gmp-system", Name: ruleShardName(nameRulesGenerated, i)}, &cm); err != nil {
+				t.Fatal(err)
+			}
+			if file, ok := cm.Data["rules__ns__rules.yaml"]; ok {
+				res[i] = ruleGroupNames(t, file)
+			}
+		}
+		return res
+	}
+
+	if err := r.ensureRuleConfigs(t.Context(), "", "", "", monitoringv1.CompressionNone, nil, false, 3, nil); err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]int{}
+	for i, names := range shardGroups(3) {
+		if len(names) == 0 {
+			t.Errorf("expected rule groups in shard %d", i)
+		}
+		for _, name := range names {
+			seen[name]++
+		}
+	}
+	for _, g := range groups {
+		if seen[g.Name] != 1 {
+			t.Errorf("expected group %q in exactly one shard, found in %d", g.Name, seen[g.Name])
+		}
+	}
+
+	// Reducing the shard count moves all groups into the remaining shard and deletes the
+	// ConfigMaps of the others.
+	if err := r.ensureRuleConfigs(t.Context(), "", "", "", monitoringv1.CompressionNone, nil, false, 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := shardGroups(1)[0]; len(got) != len(groups) {
+		t.Errorf("expected %d rule groups, got %v", len(groups), got)
+	}
+	for i := 1; i < 3; i++ {
+		var cm corev1.ConfigMap
+		err := kubeClient.Get(t.Context(), client.ObjectKey{Namespace: "gmp-system", Name: ruleShardName(nameRulesGenerated, i)}, &cm)
+		if !apierrors.IsNotFound(err) {
+			t.Errorf("expected ConfigMap of shard %d to be deleted, got err: %v", i, err)
+		}
+	}
+}
+
+func TestEnsureRuleEvaluatorShards(t *testing.T) {
+	replicas := int32(2)
+	base := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "gmp-system",
+			Name:      NameRuleEvaluator,
+			UID:       "base-uid",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{LabelAppName: NameRuleEvaluator},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{LabelAppName: NameRuleEvaluator},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: RuleEvaluatorContainerName,
+						Args: []string{"--export.ha.backend=kube", "--export.ha.kube.name=rule-evaluator"},
+					}},
+					Volumes: []corev1.Volume{
+						{
+							Name: "config",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: NameRuleEvaluator}},
+							},
+						},
+						{
+							Name: "rules",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: nameRulesGenerated}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	kubeClient := newFakeClientBuilder().WithObjects(base).Build()
+	r := newOperatorConfigReconciler(kubeClient, Options{OperatorNamespace: "gmp-system"})
+
+	if err := r.ensureRuleEvaluatorShards(t.Context(), base, 3); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < 3; i++ {
+		var deploy appsv1.Deployment
+		if err := kubeClient.Get(t.Context(), client.ObjectKey{Namespace: "gmp-system", Name: fmt.Sprintf("rule-evaluator-shard-%d", i)}, &deploy); err != nil {
+			t.Fatal(err)
+		}
+		shard := fmt.Sprint(i)
+		if diff := cmp.Diff(map[string]string{LabelAppName: NameRuleEvaluator, labelRuleShard: shard}, deploy.Spec.Selector.MatchLabels); diff != "" {
+			t.Errorf("unexpected selector (-want, +got): %s", diff)
+		}
+		if diff := cmp.Diff(map[string]string{LabelAppName: NameRuleEvaluator, labelRuleShard: shard}, deploy.Spec.Template.Labels); diff != "" {
+			t.Errorf("unexpected pod labels (-want, +got): %s", diff)
+		}
+		if got := deploy.Spec.Template.Spec.Volumes[0].ConfigMap.Name; got != NameRuleEvaluator {
+			t.Errorf("expected config volume to be unchanged, got %q", got)
+		}
+		if got, want := deploy.Spec.Template.Spec.Volumes[1].ConfigMap.Name, "rules-generated-shard-"+shard; got != want {
+			t.Errorf("expected rules volume %q, got %q", want, got)
+		}
+		wantArgs := []string{"--export.ha.backend=kube", "--export.ha.kube.name=rule-evaluator-shard-" + shard}
+		if diff := cmp.Diff(wantArgs, deploy.Spec.Template.Spec.Containers[0].Args); diff != "" {
+			t.Errorf("unexpected args (-want, +got): %s", diff)
+		}
+		if *deploy.Spec.Replicas != replicas {
+			t.Errorf("expected %d replicas, got %d", replicas, *deploy.Spec.Replicas)
+		}
+		if len(deploy.OwnerReferences) != 1 || deploy.OwnerReferences[0].UID != base.UID {
+			t.Errorf("expected owner reference to the rule-evaluator, got %v", deploy.OwnerReferences)
+		}
+	}
+	if base.Spec.Template.Spec.Volumes[1].ConfigMap.Name != nameRulesGenerated {
+		t.Error("rule-evaluator Deployment was modified")
+	}
+
+	// Unchanged shards are not written, which keeps their scaled replicas and the metadata
+	// of the Deployment controller.
+	shardKey := client.ObjectKey{Namespace: "gmp-system", Name: "rule-evaluator-shard-1"}
+	var shard appsv1.Deployment
+	if err := kubeClient.Get(t.Context(), shardKey, &shard); err != nil {
+		t.Fatal(err)
+	}
+	shard.Annotations = map[string]string{"deployment.kubernetes.io/revision": "1"}
+	shard.Spec.Replicas = ptr.To(int32(0))
+	if err := kubeClient.Update(t.Context(), &shard); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.ensureRuleEvaluatorShards(t.Context(), base, 3); err != nil {
+		t.Fatal(err)
+	}
+	var gotShard appsv1.Deployment
+	if err := kubeClient.Get(t.Context(), shardKey, &gotShard); err != nil {
+		t.Fatal(err)
+	}
+	if gotShard.ResourceVersion != shard.ResourceVersion {
+		t.Errorf("expected unchanged shard not to be updated, got resource version %s, want %s", gotShard.ResourceVersion, shard.ResourceVersion)
+	}
+
+	// Changes of the rule-evaluator are copied to the shards.
+	base.Spec.Template.Spec.Containers[0].Image = "rule-evaluator:v2"
+	if err := r.ensureRuleEvaluatorShards(t.Context(), base, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := kubeClient.Get(t.Context(), shardKey, &gotShard); err != nil {
+		t.Fatal(err)
+	}
+	if image := gotShard.Spec.Template.Spec.Containers[0].Image; image != "rule-evaluator:v2" {
+		t.Errorf("expected updated image, got %q", image)
+	}
+	if *gotShard.Spec.Replicas != 0 {
+		t.Errorf("expected scaled replicas to be kept, got %d", *gotShard.Spec.Replicas)
+	}
+	if gotShard.Annotations["deployment.kubernetes.io/revision"] != "1" {
+		t.Errorf("expected revision annotation to be kept, got %v", gotShard.Annotations)
+	}
+
+	if err := r.ensureRuleEvaluatorShards(t.Context(), base, 2); err != nil {
+		t.Fatal(err)
+	}
+	var deploys appsv1.DeploymentList
+	if err := kubeClient.List(t.Context(), &deploys); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, deploy := range deploys.Items {
+		got = append(got, deploy.Name)
+	}
+	slices.Sort(got)
+	if diff := cmp.Diff([]string{"rule-evaluator", "rule-evaluator-shard-1"}, got); diff != "" {
+		t.Errorf("unexpected Deployments (-want, +got): %s", diff)
+	}
+}
+
+func ruleGroupNames(t *testing.T, file string) []string {
+	t.Helper()
+
+	rgs, errs := rulefmt.Parse([]byte(file))
+	if len(errs) > 0 {
+		t.Fatalf("parse rule file: %v", errs)
+	}
+	var names []string
+	for _, g := range rgs.Groups {
+		names = append(names, g.Name)
+	}
+	return names
+}
@@ -0,0 +1,399 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+var (
+	ruleStatusEvaluatorsFraction = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "prometheus_engine_rule_status_evaluators_fraction",
+		Help: "Fraction of rule-evaluators whose rules could be fetched in the last rule status poll.",
+	})
+
+	// Minimum duration between polls of the rule-evaluators. Rule groups are typically
+	// evaluated every minute, so polling more often rarely observes changes.
+	minRuleStatusPollDuration = time.Minute
+)
+
+// ruleGroupResult is a rule group as returned by the rules API of the rule-evaluator.
+type ruleGroupResult struct {
+	Name           string       `json:"name"`
+	File           string       `json:"file"`
+	Rules          []ruleResult `json:"rules"`
+	EvaluationTime float64      `json:"evaluationTime"`
+	LastEvaluation time.Time    `json:"lastEvaluation"`
+}
+
+// ruleResult is a recording or alerting rule as returned by the rules API of the rule-evaluator.
+type ruleResult struct {
+	Name           string    `json:"name"`
+	Health         string    `json:"health"`
+	LastError      string    `json:"lastError"`
+	EvaluationTime float64   `json:"evaluationTime"`
+	LastEvaluation time.Time `json:"lastEvaluation"`
+}
+
+// Responsible for fetching the rule groups given a rule-evaluator pod.
+type getRulesFn func(ctx context.Context, httpClient *http.Client, port int32, pod *corev1.Pod) ([]ruleGroupResult, error)
+
+// ruleStatusReconciler polls the rule-evaluators and populates the rule evaluation health in
+// the status of the rules objects.
+type ruleStatusReconciler struct {
+	ch         chan<- event.GenericEvent
+	opts       Options
+	getRules   getRulesFn
+	clock      clock.Clock
+	logger     logr.Logger
+	httpClient *http.Client
+	kubeClient client.Client
+}
+
+// setupRuleStatusPoller sets up a reconciler that polls and populates rule evaluation
+// statuses whenever it receives an event.
+func setupRuleStatusPoller(op *Operator, registry prometheus.Registerer, httpClient *http.Client) error {
+	ch := make(chan event.GenericEvent, 1)
+
+	reconciler := &ruleStatusReconciler{
+		ch:         ch,
+		opts:       op.opts,
+		getRules:   getRules,
+		logger:     op.logger,
+		httpClient: httpClient,
+		kubeClient: op.manager.GetClient(),
+		clock:      clock.RealClock{},
+	}
+
+	err := ctrl.NewControllerManagedBy(op.manager).
+		Named("rule-status").
+		// Like for the target status, the For clause is only required by controller-runtime.
+		// The reconcile loop is driven by the channel source.
+		For(
+			&appsv1.Deployment{},
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(_ client.Object) bool {
+				return false
+			})),
+		).
+		WatchesRawSource(
+			source.Channel(ch, &handler.EnqueueRequestForObject{}),
+		).
+		Complete(reconciler)
+	if err != nil {
+		return fmt.Errorf("create rule status controller: %w", err)
+	}
+
+	// Start the controller only once, on the leader.
+	if err := op.manager.Add(reconciler.start(registry)); err != nil {
+		return fmt.Errorf("unable to start rule status controller: %w", err)
+	}
+
+	return nil
+}
+
+// start returns a runnable that triggers the first poll. It also registers the metrics that
+// only the polling leader reports, as other replicas would report a zero fraction of polled
+// rule-evaluators.
+func (r *ruleStatusReconciler) start(registry prometheus.Registerer) manager.RunnableFunc {
+	return func(context.Context) error {
+		if err := registry.Register(ruleStatusEvaluatorsFraction); err != nil {
+			return err
+		}
+		r.ch <- event.GenericEvent{
+			Object: &appsv1.Deployment{},
+		}
+		return nil
+	}
+}
+
+// Reconcile polls the rule-evaluator pods and upserts the evaluation health of each rule group
+// into the status of the Rules, ClusterRules and GlobalRules it belongs to.
+func (r *ruleStatusReconciler) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	timer := r.clock.NewTimer(minRuleStatusPollDuration)
+
+	var config monitoringv1.OperatorConfig
+	if err := r.kubeClient.Get(ctx, types.NamespacedName{
+		Name:      NameOperatorConfig,
+		Namespace: r.opts.PublicNamespace,
+	}, &config); client.IgnoreNotFound(err) != nil {
+		r.logger.Error(err, "get operatorconfig")
+	} else if config.Features.RuleStatus.Enabled {
+		if err := r.pollAndUpdate(ctx); err != nil {
+			r.logger.Error(err, "poll and update rule status")
+		}
+	}
+
+	// Check if we beat the timer, otherwise wait.
+	select {
+	case <-ctx.Done():
+		break
+	case <-timer.C():
+		r.ch <- event.GenericEvent{
+			Object: &appsv1.Deployment{},
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// pollAndUpdate fetches the rule groups of all rule-evaluators and updates the status of the
+// rules objects.
+func (r *ruleStatusReconciler) pollAndUpdate(ctx context.Context) error {
+	groups, err := r.fetchRuleGroups(ctx)
+	if err != nil {
+		return err
+	}
+	return updateRuleStatus(ctx, r.logger, r.kubeClient, groups)
+}
+
+// fetchRuleGroups retrieves the rule groups of all rule-evaluator pods, including those of all
+// shards, keyed by the name of the rule file they were loaded from.
+func (r *ruleStatusReconciler) fetchRuleGroups(ctx context.Context) (map[string][]ruleGroupResult, error) {
+	var deploy appsv1.Deployment
+	if err := r.kubeClient.Get(ctx, client.ObjectKey{
+		Name:      NameRuleEvaluator,
+		Namespace: r.opts.OperatorNamespace,
+	}, &deploy); err != nil {
+		return nil, err
+	}
+	// The selector of the rule-evaluator also matches the pods of all further shards.
+	selector, err := metav1.LabelSelectorAsSelector(deploy.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	var port *int32
+	for _, container := range deploy.Spec.Template.Spec.Containers {
+		if container.Name != RuleEvaluatorContainerName {
+			continue
+		}
+		for _, containerPort := range container.Ports {
+			if containerPort.Name == RuleEvaluatorContainerPortName {
+				port = &containerPort.ContainerPort
+			}
+		}
+	}
+	if port == nil {
+		return nil, errors.New("unable to detect rule-evaluator port")
+	}
+
+	var pods corev1.PodList
+	if err := r.kubeClient.List(ctx, &pods, client.InNamespace(r.opts.OperatorNamespace), client.MatchingLabelsSelector{
+		Selector: selector,
+	}); err != nil {
+		return nil, err
+	}
+
+	groups := map[string][]ruleGroupResult{}
+	var polled, fetched int
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		polled++
+		result, err := r.getRules(ctx, r.httpClient, *port, pod)
+		if err != nil {
+			r.logger.Error(err, "failed to fetch rules", "pod", pod.GetName())
+			continue
+		}
+		fetched++
+		for _, g := range result {
+			file := path.Base(g.File)
+			groups[file] = append(groups[file], g)
+		}
+	}
+	if polled > 0 {
+		ruleStatusEvaluatorsFraction.Set(float64(fetched) / float64(polled))
+	}
+	return groups, nil
+}
+
+func getRules(ctx context.Context, httpClient *http.Client, port int32, pod *corev1.Pod) ([]ruleGroupResult, error) {
+	if pod.Status.PodIP == "" {
+		return nil, errors.New("pod does not have IP allocated")
+	}
+	podURL := fmt.Sprintf("http://%s/api/v1/rules?exclude_alerts=true", net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(port)))) //nolint:revive // Allow insecure http client
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, podURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch rules: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			Groups []ruleGroupResult `json:"groups"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("unable to decode rules: %w", err)
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("unable to fetch rules: %s", result.Error)
+	}
+	return result.Data.Groups, nil
+}
+
+// updateRuleStatus populates the status of all rules objects with the evaluation health of
+// their rule groups.
+func updateRuleStatus(ctx context.Context, logger logr.Logger, kubeClient client.Client, groups map[string][]ruleGroupResult) error {
+	var errs []error
+	update := func(obj client.Object, file string, spec *monitoringv1.RulesSpec, status *monitoringv1.RulesStatus) {
+		statuses := ruleGroupStatuses(spec.Groups, groups[file], status.RuleGroupStatuses)
+		if equality.Semantic.DeepEqual(statuses, status.RuleGroupStatuses) {
+			return
+		}
+		if err := patchRuleGroupStatuses(ctx, kubeClient, obj, statuses); err != nil {
+			// Continue patching all statuses, the error may be transient.
+			errs = append(errs, err)
+			logger.Error(err, "patching rule status", "namespace", obj.GetNamespace(), "name", obj.GetName())
+		}
+	}
+
+	var rulesList monitoringv1.RulesList
+	if err := kubeClient.List(ctx, &rulesList); err != nil {
+		return err
+	}
+	for i := range rulesList.Items {
+		rs := &rulesList.Items[i]
+		update(rs, rulesFilename(rs.Namespace, rs.Name), &rs.Spec, &rs.Status)
+	}
+	var clusterRulesList monitoringv1.ClusterRulesList
+	if err := kubeClient.List(ctx, &clusterRulesList); err != nil {
+		return err
+	}
+	for i := range clusterRulesList.Items {
+		rs := &clusterRulesList.Items[i]
+		update(rs, clusterRulesFilename(rs.Name), &rs.Spec, &rs.Status)
+	}
+	var globalRulesList monitoringv1.GlobalRulesList
+	if err := kubeClient.List(ctx, &globalRulesList); err != nil {
+		return err
+	}
+	for i := range globalRulesList.Items {
+		rs := &globalRulesList.Items[i]
+		update(rs, globalRulesFilename(rs.Name), &rs.Spec, &rs.Status)
+	}
+
+	return errors.Join(errs...)
+}
+
+// ruleGroupStatuses returns the status of each rule group in the order of the spec. Replicas
+// of a rule-evaluator report the same rule groups, of which the most recently evaluated one
+// is used. Rule groups that weren't reported, e.g. as their rule-evaluator couldn't be
+// reached, keep their previous status.
+func ruleGroupStatuses(groups []monitoringv1.RuleGroup, results []ruleGroupResult, previous []monitoringv1.RuleGroupStatus) []monitoringv1.RuleGroupStatus {
+	latest := map[string]*ruleGroupResult{}
+	for i := range results {
+		result := &results[i]
+		if prev, ok := latest[result.Name]; ok && !result.LastEvaluation.After(prev.LastEvaluation) {
+			continue
+		}
+		latest[result.Name] = result
+	}
+
+	var statuses []monitoringv1.RuleGroupStatus
+	for _, g := range groups {
+		if result, ok := latest[g.Name]; ok {
+			statuses = append(statuses, newRuleGroupStatus(result))
+			continue
+		}
+		i := slices.IndexFunc(previous, func(s monitoringv1.RuleGroupStatus) bool {
+			return s.Name == g.Name
+		})
+		if i >= 0 {
+			statuses = append(statuses, previous[i])
+		}
+	}
+	return statuses
+}
+
+func newRuleGroupStatus(result *ruleGroupResult) monitoringv1.RuleGroupStatus {
+	status := monitoringv1.RuleGroupStatus{
+		Name:                      result.Name,
+		LastEvaluation:            evaluationTime(result.LastEvaluation),
+		EvaluationDurationSeconds: strconv.FormatFloat(result.EvaluationTime, 'f', -1, 64),
+	}
+	for _, rule := range result.Rules {
+		status.RuleStatuses = append(status.RuleStatuses, monitoringv1.RuleStatus{
+			Name:                      rule.Name,
+			Health:                    rule.Health,
+			LastError:                 rule.LastError,
+			LastEvaluation:            evaluationTime(rule.LastEvaluation),
+			EvaluationDurationSeconds: strconv.FormatFloat(rule.EvaluationTime, 'f', -1, 64),
+		})
+	}
+	return status
+}
+
+// evaluationTime converts an evaluation time to the precision it's stored with in the status,
+// so that unchanged statuses can be detected.
+func evaluationTime(t time.Time) metav1.Time {
+	if t.IsZero() {
+		return metav1.Time{}
+	}
+	return metav1.NewTime(t.Truncate(time.Second))
+}
+
+func patchRuleGroupStatuses(ctx context.Context, kubeClient client.Client, obj client.Object, statuses []monitoringv1.RuleGroupStatus) error {
+	patchObject := map[string]any{
+		"status": map[string]any{
+			"ruleGroupStatuses": statuses,
+		},
+	}
+	patchBytes, err := json.Marshal(patchObject)
+	if err != nil {
+		return fmt.Errorf("unable to marshall status: %w", err)
+	}
+	patch := client.RawPatch(types.MergePatchType, patchBytes)
+	if err := kubeClient.Status().Patch(ctx, obj, patch); err != nil {
+		return fmt.Errorf("unable to patch status: %w", err)
+	}
+	return nil
+}
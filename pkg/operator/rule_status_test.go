@@ -0,0 +1,328 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+func TestRuleGroupStatuses(t *testing.T) {
+	older := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Minute + 500*time.Millisecond)
+
+	groups := []monitoringv1.RuleGroup{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	results := []ruleGroupResult{
+		// Reported by two replicas, the more recent evaluation wins.
+		{
+			Name:           "b",
+			LastEvaluation: older,
+			EvaluationTime: 0.5,
+			Rules: []ruleResult{{
+				Name:      "foo",
+				Health:    "err",
+				LastError: "query failed",
+			}},
+		},
+		{
+			Name:           "b",
+			LastEvaluation: newer,
+			EvaluationTime: 0.25,
+			Rules: []ruleResult{{
+				Name:           "foo",
+				Health:         "ok",
+				LastEvaluation: newer,
+				EvaluationTime: 0.125,
+			}},
+		},
+		// Not part of the spec anymore.
+		{Name: "removed", LastEvaluation: newer},
+	}
+	previous := []monitoringv1.RuleGroupStatus{
+		{Name: "a", LastEvaluation: metav1.NewTime(older)},
+		{Name: "b", LastEvaluation: metav1.NewTime(older)},
+		{Name: "removed", LastEvaluation: metav1.NewTime(older)},
+	}
+
+	want := []monitoringv1.RuleGroupStatus{
+		// Not reported, so the previous status is kept.
+		{Name: "a", LastEvaluation: metav1.NewTime(older)},
+		{
+			Name:                      "b",
+			LastEvaluation:            metav1.NewTime(newer.Truncate(time.Second)),
+			EvaluationDurationSeconds: "0.25",
+			RuleStatuses: []monitoringv1.RuleStatus{{
+				Name:                      "foo",
+				Health:                    "ok",
+				LastEvaluation:            metav1.NewTime(newer.Truncate(time.Second)),
+				EvaluationDurationSeconds: "0.125",
+			}},
+		},
+	}
+	if diff := cmp.Diff(want, ruleGroupStatuses(groups, results, previous)); diff != "" {
+		t.Errorf("unexpected rule group statuses (-want, +got): %s", diff)
+	}
+}
+
+func TestRuleStatusPollAndUpdate(t *testing.T) {
+	logger := testr.New(t)
+	opts := Options{
+		ProjectID:         "test-proj",
+		Location:          "test-loc",
+		Cluster:           "test-cluster",
+		OperatorNamespace: "gmp-system",
+	}
+	if err := opts.defaultAndValidate(logger); err != nil {
+		t.Fatal("Invalid options:", err)
+	}
+	evaluated := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newPod := func(name, shard string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: opts.OperatorNamespace,
+				Labels: map[string]string{
+					LabelAppName:   NameRuleEvaluator,
+					labelRuleShard: shard,
+				},
+			},
+			Status: corev1.PodStatus{Phase: phase, PodIP: "127.0.0.1"},
+		}
+	}
+	kubeClient := newFakeClientBuilder().WithObjects(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      NameRuleEvaluator,
+				Namespace: opts.OperatorNamespace,
+			},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{LabelAppName: NameRuleEvaluator},
+				},
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Name: RuleEvaluatorContainerName,
+							Ports: []corev1.ContainerPort{{
+								Name:          RuleEvaluatorContainerPortName,
+								ContainerPort: 19092,
+							}},
+						}},
+					},
+				},
+			},
+		},
+		newPod("shard-0", "0", corev1.PodRunning),
+		newPod("shard-1", "1", corev1.PodRunning),
+		newPod("pending", "1", corev1.PodPending),
+		&monitoringv1.Rules{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "rules"},
+			Spec: monitoringv1.RulesSpec{
+				Groups: []monitoringv1.RuleGroup{{Name: "group-1"}, {Name: "group-2"}},
+			},
+		},
+		&monitoringv1.GlobalRules{
+			ObjectMeta: metav1.ObjectMeta{Name: "global"},
+			Spec: monitoringv1.RulesSpec{
+				Groups: []monitoringv1.RuleGroup{{Name: "group-1"}},
+			},
+		},
+	).Build()
+
+	// The rule groups of the Rules object are split across the shards.
+	results := map[string][]ruleGroupResult{
+		"shard-0": {{
+			Name:           "group-1",
+			File:           "/prometheus/rules_out/rules__ns__rules.yaml",
+			LastEvaluation: evaluated,
+			Rules:          []ruleResult{{Name: "foo", Health: "ok", LastEvaluation: evaluated}},
+		}},
+		"shard-1": {{
+			Name:           "group-2",
+			File:           "/prometheus/rules_out/rules__ns__rules.yaml",
+			LastEvaluation: evaluated,
+			Rules:          []ruleResult{{Name: "bar", Health: "err", LastError: "query failed", LastEvaluation: evaluated}},
+		}},
+	}
+	r := &ruleStatusReconciler{
+		opts:       opts,
+		logger:     logger,
+		kubeClient: kubeClient,
+		getRules: func(_ context.Context, _ *http.Client, port int32, pod *corev1.Pod) ([]ruleGroupResult, error) {
+			if port != 19092 {
+				t.Errorf("unexpected port %d", port)
+			}
+			if pod.Status.Phase != corev1.PodRunning {
+				t.Errorf("unexpected poll of pod %s in phase %s", pod.Name, pod.Status.Phase)
+			}
+			result, ok := results[pod.Name]
+			if !ok {
+				return nil, errors.New("unreachable")
+			}
+			return result, nil
+		},
+	}
+	if err := r.pollAndUpdate(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []monitoringv1.RuleGroupStatus{
+		{
+			Name:                      "group-1",
+			LastEvaluation:            metav1.NewTime(evaluated),
+			EvaluationDurationSeconds: "0",
+			RuleStatuses: []monitoringv1.RuleStatus{{
+				Name: "foo", Health: "ok", LastEvaluation: metav1.NewTime(evaluated), EvaluationDurationSeconds: "0",
+			}},
+		},
+		{
+			Name:                      "group-2",
+			LastEvaluation:            metav1.NewTime(evaluated),
+			EvaluationDurationSeconds: "0",
+			RuleStatuses: []monitoringv1.RuleStatus{{
+				Name: "bar", Health: "err", LastError: "query failed", LastEvaluation: metav1.NewTime(evaluated), EvaluationDurationSeconds: "0",
+			}},
+		},
+	}
+	checkStatus := func() {
+		t.Helper()
+
+		var rules monitoringv1.Rules
+		if err := kubeClient.Get(t.Context(), client.ObjectKey{Namespace: "ns", Name: "rules"}, &rules); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, rules.Status.RuleGroupStatuses); diff != "" {
+			t.Errorf("unexpected rule group statuses (-want, +got): %s", diff)
+		}
+		var globalRules monitoringv1.GlobalRules
+		if err := kubeClient.Get(t.Context(), client.ObjectKey{Name: "global"}, &globalRules); err != nil {
+			t.Fatal(err)
+		}
+		if len(globalRules.Status.RuleGroupStatuses) != 0 {
+			t.Errorf("expected no rule group statuses for unevaluated GlobalRules, got %v", globalRules.Status.RuleGroupStatuses)
+		}
+	}
+	checkStatus()
+
+	// If a shard can't be reached, its rule groups keep their status.
+	delete(results, "shard-1")
+	if err := r.pollAndUpdate(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	checkStatus()
+}
+
+func TestGetRules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/v1/rules" {
+			http.NotFound(w, req)
+			return
+		}
+		if req.URL.Query().Get("exclude_alerts") != "true" {
+			t.Errorf("expected alerts to be excluded, got query %q", req.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"status":"success","data":{"groups":[{"name":"group-1","file":"/prometheus/rules_out/rules__ns__rules.yaml","interval":60,"limit":0,"evaluationTime":0.5,"lastEvaluation":"2026-01-01T00:00:00Z","rules":[{"state":"inactive","name":"Alert","query":"up == 0","duration":0,"health":"err","lastError":"query failed","evaluationTime":0.25,"lastEvaluation":"2026-01-01T00:00:00Z","type":"alerting"}]}]}}`)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, portStr, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := &corev1.Pod{Status: corev1.PodStatus{PodIP: host}}
+
+	got, err := getRules(t.Context(), server.Client(), int32(port), pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evaluated := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	want := []ruleGroupResult{{
+		Name:           "group-1",
+		File:           "/prometheus/rules_out/rules__ns__rules.yaml",
+		EvaluationTime: 0.5,
+		LastEvaluation: evaluated,
+		Rules: []ruleResult{{
+			Name:           "Alert",
+			Health:         "err",
+			LastError:      "query failed",
+			EvaluationTime: 0.25,
+			LastEvaluation: evaluated,
+		}},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected rule groups (-want, +got): %s", diff)
+	}
+
+	if _, err := getRules(t.Context(), server.Client(), int32(port), &corev1.Pod{}); err == nil {
+		t.Error("expected error for pod without IP")
+	}
+}
+
+func TestRuleStatusEvaluatorsFractionLeaderOnly(t *testing.T) {
+	// Two operator replicas, of which only the leader starts polling.
+	leaderRegistry, followerRegistry := prometheus.NewRegistry(), prometheus.NewRegistry()
+	leader := &ruleStatusReconciler{ch: make(chan event.GenericEvent, 1)}
+	if err := leader.start(leaderRegistry)(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	ruleStatusEvaluatorsFraction.Set(1)
+
+	exposesFraction := func(registry *prometheus.Registry) bool {
+		t.Helper()
+		families, err := registry.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return slices.ContainsFunc(families, func(mf *dto.MetricFamily) bool {
+			return mf.GetName() == "prometheus_engine_rule_status_evaluators_fraction"
+		})
+	}
+	if !exposesFraction(leaderRegistry) {
+		t.Error("expected leader to expose the evaluators fraction")
+	}
+	if exposesFraction(followerRegistry) {
+		t.Error("expected follower not to expose the evaluators fraction")
+	}
+}
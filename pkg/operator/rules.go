@@ -16,12 +16,18 @@ package operator
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -37,6 +43,9 @@ import (
 
 const (
 	nameRulesGenerated = "rules-generated"
+	// ruleQueryProjectsFilename is the file of the generated rule ConfigMaps that maps rule
+	// files to the project their rules are queried from, if it's not the default one.
+	ruleQueryProjectsFilename = "query-projects.json"
 )
 
 func setupRulesControllers(op *Operator) error {
@@ -52,11 +61,18 @@ func setupRulesControllers(op *Operator) error {
 		namespace: op.opts.PublicNamespace,
 		name:      NameOperatorConfig,
 	}
-	// Rule-evaluator rules ConfigMap filter.
-	objFilterRulesGenerated := namespacedNamePredicate{
-		namespace: op.opts.OperatorNamespace,
-		name:      nameRulesGenerated,
-	}
+	// Rule-evaluator rules ConfigMaps filter, including those of all shards.
+	objFilterRulesGenerated := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		if obj.GetNamespace() != op.opts.OperatorNamespace {
+			return false
+		}
+		// ConfigMaps split off due to their size are immutable.
+		if _, ok := obj.GetLabels()[labelConfigShardOf]; ok {
+			return false
+		}
+		_, ok := obj.GetLabels()[labelRuleShard]
+		return ok || obj.GetName() == nameRulesGenerated
+	})
 
 	// Reconcile the generated rules that are used by the rule-evaluator deployment.
 	err := ctrl.NewControllerManagedBy(op.manager).
@@ -69,18 +85,28 @@ func setupRulesControllers(op *Operator) error {
 			&monitoringv1.OperatorConfig{},
 			builder.WithPredicates(objFilterOperatorConfig),
 		).
-		// Any update to a Rules object requires re-generating the config.
+		// Any update to the spec of a Rules object requires re-generating the config.
+		// Status updates, such as the reported rule evaluation health, don't.
 		Watches(
 			&monitoringv1.GlobalRules{},
 			enqueueConst(objRequest),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
 		).
 		Watches(
 			&monitoringv1.ClusterRules{},
 			enqueueConst(objRequest),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
 		).
 		Watches(
 			&monitoringv1.Rules{},
 			enqueueConst(objRequest),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		// Namespace labels may be matched by the namespaces filter.
+		Watches(
+			&corev1.Namespace{},
+			enqueueConst(objRequest),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}),
 		).
 		// The configuration we generate for the rule-evaluator.
 		Watches(
@@ -98,12 +124,15 @@ func setupRulesControllers(op *Operator) error {
 type rulesReconciler struct {
 	client client.Client
 	opts   Options
+	// ruleFiles caches the rule files generated for each rules object, one per shard.
+	ruleFiles *configCache[[]string]
 }
 
 func newRulesReconciler(c client.Client, opts Options) *rulesReconciler {
 	return &rulesReconciler{
-		client: c,
-		opts:   opts,
+		client:    c,
+		opts:      opts,
+		ruleFiles: newConfigCache[[]string]("rules"),
 	}
 }
 
@@ -121,21 +150,27 @@ func (r *rulesReconciler) Reconcile(ctx context.Context, req reconcile.Request)
 
 	projectID, location, cluster := resolveLabels(r.opts.ProjectID, r.opts.Location, r.opts.Cluster, config.Rules.ExternalLabels)
 
-	if err := r.ensureRuleConfigs(ctx, projectID, location, cluster, config.Features.Config.Compression); err != nil {
+	start := time.Now()
+	selfMonitoring := config.Features.SelfMonitoring.Enabled
+	if err := r.ensureRuleConfigs(ctx, projectID, location, cluster, config.Features.Config.Compression, &config.Namespaces, selfMonitoring, ruleShardCount(&config.Rules), config.Rules.AllowedQueryProjectIDs); err != nil {
 		return reconcile.Result{}, fmt.Errorf("ensure rule configmaps: %w", err)
 	}
+	configGenerationDuration.WithLabelValues(nameRulesGenerated).Observe(time.Since(start).Seconds())
 
-	if err := r.scaleRuleConsumers(ctx); err != nil {
+	if err := r.scaleRuleConsumers(ctx, selfMonitoring); err != nil {
 		return reconcile.Result{}, fmt.Errorf("scale rule consumers: %w", err)
 	}
 
 	return reconcile.Result{}, nil
 }
 
-func (r *rulesReconciler) scaleRuleConsumers(ctx context.Context) error {
+// scaleRuleConsumers scales the rule-evaluator and Alertmanager down if there are no rules
+// to evaluate and back up otherwise. The self-monitoring rules count as rules. Deployments
+// targeted by an HPA are left to it.
+func (r *rulesReconciler) scaleRuleConsumers(ctx context.Context, selfMonitoring bool) error {
 	logger, _ := logr.FromContext(ctx)
 
-	var desiredReplicas int32
+	var desiredReplicas, ruleEvaluatorReplicas int32
 
 	var hasAnyRules bool
 	for _, check := range []ruleCheck{hasRules, hasClusterRules, hasGlobalRules} {
@@ -148,8 +183,9 @@ func (r *rulesReconciler) scaleRuleConsumers(ctx context.Context) error {
 			break
 		}
 	}
-	if hasAnyRules {
+	if hasAnyRules || selfMonitoring {
 		desiredReplicas = 1
+		ruleEvaluatorReplicas = r.opts.RuleEvaluatorReplicas
 	}
 
 	scaleClient := r.client.SubResource("scale")
@@ -173,27 +209,86 @@ func (r *rulesReconciler) scaleRuleConsumers(ctx context.Context) error {
 		}
 	}
 
-	ruleEvaluatorDeployment := appsv1.Deployment{
+	ruleEvaluatorDeployments := []appsv1.Deployment{{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: r.opts.OperatorNamespace,
 			Name:      "rule-evaluator",
 		},
+	}}
+	// The Deployments of further shards are scaled alike.
+	var shardDeployments appsv1.DeploymentList
+	if err := r.client.List(ctx, &shardDeployments, client.InNamespace(r.opts.OperatorNamespace), client.HasLabels{labelRuleShard}); err != nil {
+		return err
 	}
-	ruleEvaluatorScale := autoscalingv1.Scale{}
-	if err := scaleClient.Get(ctx, &ruleEvaluatorDeployment, &ruleEvaluatorScale); apierrors.IsNotFound(err) {
-		msg := fmt.Sprintf("Rule Evaluator Deployment not found, cannot scale to %d. In-cluster Rule Evaluator will not function.", desiredReplicas)
-		logger.Error(err, msg)
-	} else if err != nil {
+	ruleEvaluatorDeployments = append(ruleEvaluatorDeployments, shardDeployments.Items...)
+
+	// Deployments targeted by an HPA are scaled by it instead.
+	autoscaled, err := autoscaledDeployments(ctx, r.client, r.opts.OperatorNamespace)
+	if err != nil {
 		return err
-	} else if ruleEvaluatorScale.Spec.Replicas != desiredReplicas {
-		ruleEvaluatorScale.Spec.Replicas = desiredReplicas
-		if err := scaleClient.Update(ctx, &ruleEvaluatorDeployment, client.WithSubResourceBody(&ruleEvaluatorScale)); err != nil {
+	}
+
+	for i := range ruleEvaluatorDeployments {
+		ruleEvaluatorDeployment := &ruleEvaluatorDeployments[i]
+		if autoscaled[ruleEvaluatorDeployment.Name] {
+			continue
+		}
+		ruleEvaluatorScale := autoscalingv1.Scale{}
+		if err := scaleClient.Get(ctx, ruleEvaluatorDeployment, &ruleEvaluatorScale); apierrors.IsNotFound(err) {
+			msg := fmt.Sprintf("Rule Evaluator Deployment %s not found, cannot scale to %d. In-cluster Rule Evaluator will not function.", ruleEvaluatorDeployment.Name, ruleEvaluatorReplicas)
+			logger.Error(err, msg)
+		} else if err != nil {
 			return err
+		} else if ruleEvaluatorScale.Spec.Replicas != ruleEvaluatorReplicas {
+			ruleEvaluatorScale.Spec.Replicas = ruleEvaluatorReplicas
+			if err := scaleClient.Update(ctx, ruleEvaluatorDeployment, client.WithSubResourceBody(&ruleEvaluatorScale)); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
 }
 
+// autoscaledDeployments returns the names of the Deployments in the namespace that are the
+// scale target of a HorizontalPodAutoscaler.
+func autoscaledDeployments(ctx context.Context, c client.Reader, namespace string) (map[string]bool, error) {
+	var hpas autoscalingv2.HorizontalPodAutoscalerList
+	if err := c.List(ctx, &hpas, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	for _, hpa := range hpas.Items {
+		if ref := hpa.Spec.ScaleTargetRef; ref.Kind == "Deployment" {
+			names[ref.Name] = true
+		}
+	}
+	return names, nil
+}
+
+// rulesFilename returns the name of the rule file generated for a Rules object.
+func rulesFilename(namespace, name string) string {
+	return fmt.Sprintf("rules__%s__%s.yaml", namespace, name)
+}
+
+// clusterRulesFilename returns the name of the rule file generated for a ClusterRules object.
+func clusterRulesFilename(name string) string {
+	return fmt.Sprintf("clusterrules__%s.yaml", name)
+}
+
+// globalRulesFilename returns the name of the rule file generated for a GlobalRules object.
+func globalRulesFilename(name string) string {
+	return fmt.Sprintf("globalrules__%s.yaml", name)
+}
+
+// checkQueryProject returns an error if rules may not be evaluated against the given query
+// project. An empty project selects the default query project of the rule-evaluator.
+func checkQueryProject(allowed []string, projectID string) error {
+	if projectID == "" || slices.Contains(allowed, projectID) {
+		return nil
+	}
+	return fmt.Errorf("query project %q is not in the allowed query projects of the rule-evaluator", projectID)
+}
+
 type ruleCheck func(context.Context, client.Client) (bool, error)
 
 func hasRules(ctx context.Context, c client.Client) (bool, error) {
@@ -220,24 +315,57 @@ func hasGlobalRules(ctx context.Context, c client.Client) (bool, error) {
 	return len(rules.Items) > 0, nil
 }
 
-// ensureRuleConfigs updates the Prometheus Rules ConfigMap.
-func (r *rulesReconciler) ensureRuleConfigs(ctx context.Context, projectID, location, cluster string, configCompression monitoringv1.CompressionType) error {
+// ensureRuleConfigs updates the Prometheus Rules ConfigMaps of all shards.
+func (r *rulesReconciler) ensureRuleConfigs(ctx context.Context, projectID, location, cluster string, configCompression monitoringv1.CompressionType, namespaces *monitoringv1.NamespaceFilter, selfMonitoring bool, shards int32, allowedQueryProjects []string) error {
 	logger, _ := logr.FromContext(ctx)
 
-	// Re-generate the configmap that's loaded by the rule-evaluator.
-	cm := &corev1.ConfigMap{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: r.opts.OperatorNamespace,
-			Name:      nameRulesGenerated,
-			Labels: map[string]string{
-				LabelAppName: NameRuleEvaluator,
+	// Re-generate the configmaps that are loaded by the rule-evaluator of each shard.
+	cms := make([]*corev1.ConfigMap, shards)
+	for i := range cms {
+		cms[i] = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: r.opts.OperatorNamespace,
+				Name:      ruleShardName(nameRulesGenerated, i),
+				Labels: map[string]string{
+					LabelAppName:   NameRuleEvaluator,
+					labelRuleShard: strconv.Itoa(i),
+				},
 			},
-		},
-		// Ensure there's always at least an empty, uncompressed dummy file as the evaluator
-		// expects at least one match.
-		Data: map[string]string{
-			"empty.yaml": "",
-		},
+			// Ensure there's always at least an empty, uncompressed dummy file as the evaluator
+			// expects at least one match.
+			Data: map[string]string{
+				"empty.yaml": "",
+			},
+		}
+	}
+	// setShardData adds the rule file of each shard that has any rule groups.
+	setShardData := func(filename string, files []string) error {
+		for i, result := range files {
+			if result == "" {
+				continue
+			}
+			if err := setConfigMapData(cms[i], configCompression, filename, result); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	// queryProjects maps the rule files of each shard to the project their rules are queried
+	// from, if it's not the default one.
+	queryProjects := make([]map[string]string, shards)
+	setShardQueryProject := func(filename, queryProjectID string, files []string) {
+		if queryProjectID == "" {
+			return
+		}
+		for i, result := range files {
+			if result == "" {
+				continue
+			}
+			if queryProjects[i] == nil {
+				queryProjects[i] = map[string]string{}
+			}
+			queryProjects[i][filename] = queryProjectID
+		}
 	}
 
 	// Generate a final rule file for each Rules resource.
@@ -254,6 +382,8 @@ func (r *rulesReconciler) ensureRuleConfigs(ctx context.Context, projectID, loca
 		return fmt.Errorf("list rules: %w", err)
 	}
 
+	allowed := strings.Join(allowedQueryProjects, ",")
+	env := fmt.Sprintf("%s/%s/%s/%d/%s", projectID, location, cluster, shards, allowed)
 	now := metav1.Now()
 	conditionSuccess := &monitoringv1.MonitoringCondition{
 		Type:   monitoringv1.ConfigurationCreateSuccess,
@@ -261,9 +391,30 @@ func (r *rulesReconciler) ensureRuleConfigs(ctx context.Context, projectID, loca
 	}
 	var statusUpdates []monitoringv1.MonitoringCRD
 
+	nsFilter, err := newNamespaceFilter(r.client, namespaces)
+	if err != nil {
+		return err
+	}
 	for i := range rulesList.Items {
 		rs := &rulesList.Items[i]
-		result, err := rs.RuleGroupsConfig(projectID, location, cluster)
+		if ok, err := nsFilter.allowed(ctx, rs.Namespace); err != nil {
+			return err
+		} else if !ok {
+			if rs.Status.SetMonitoringCondition(rs.GetGeneration(), now, namespaceIgnoredCondition(rs.Namespace)) {
+				statusUpdates = append(statusUpdates, rs)
+			}
+			continue
+		}
+		result, err := r.ruleFiles.get(ctx, rs, env, func() ([]string, error) {
+			if err := checkQueryProject(allowedQueryProjects, rs.Spec.QueryProjectID); err != nil {
+				return nil, err
+			}
+			return shardRuleFiles(rs.Namespace+"/"+rs.Name, rs.Spec.Groups, shards, func(groups []monitoringv1.RuleGroup) (string, error) {
+				shard := *rs
+				shard.Spec.Groups = groups
+				return shard.RuleGroupsConfig(projectID, location, cluster)
+			})
+		})
 		if err != nil {
 			msg := "generating rule config failed"
 			if rs.Status.SetMonitoringCondition(rs.GetGeneration(), now, &monitoringv1.MonitoringCondition{
@@ -277,10 +428,11 @@ func (r *rulesReconciler) ensureRuleConfigs(ctx context.Context, projectID, loca
 			logger.Error(err, "convert rules", "err", err, "namespace", rs.Namespace, "name", rs.Name)
 			continue
 		}
-		filename := fmt.Sprintf("rules__%s__%s.yaml", rs.Namespace, rs.Name)
-		if err := setConfigMapData(cm, configCompression, filename, result); err != nil {
+		filename := rulesFilename(rs.Namespace, rs.Name)
+		if err := setShardData(filename, result); err != nil {
 			return err
 		}
+		setShardQueryProject(filename, rs.Spec.QueryProjectID, result)
 
 		if rs.Status.SetMonitoringCondition(rs.GetGeneration(), now, conditionSuccess) {
 			statusUpdates = append(statusUpdates, rs)
@@ -293,7 +445,16 @@ func (r *rulesReconciler) ensureRuleConfigs(ctx context.Context, projectID, loca
 	}
 	for i := range clusterRulesList.Items {
 		rs := &clusterRulesList.Items[i]
-		result, err := rs.RuleGroupsConfig(projectID, location, cluster)
+		result, err := r.ruleFiles.get(ctx, rs, env, func() ([]string, error) {
+			if err := checkQueryProject(allowedQueryProjects, rs.Spec.QueryProjectID); err != nil {
+				return nil, err
+			}
+			return shardRuleFiles(rs.Namespace+"/"+rs.Name, rs.Spec.Groups, shards, func(groups []monitoringv1.RuleGroup) (string, error) {
+				shard := *rs
+				shard.Spec.Groups = groups
+				return shard.RuleGroupsConfig(projectID, location, cluster)
+			})
+		})
 		if err != nil {
 			msg := "generating rule config failed"
 			if rs.Status.SetMonitoringCondition(rs.Generation, now, &monitoringv1.MonitoringCondition{
@@ -307,10 +468,11 @@ func (r *rulesReconciler) ensureRuleConfigs(ctx context.Context, projectID, loca
 			logger.Error(err, "convert rules", "err", err, "namespace", rs.Namespace, "name", rs.Name)
 			continue
 		}
-		filename := fmt.Sprintf("clusterrules__%s.yaml", rs.Name)
-		if err := setConfigMapData(cm, configCompression, filename, result); err != nil {
+		filename := clusterRulesFilename(rs.Name)
+		if err := setShardData(filename, result); err != nil {
 			return err
 		}
+		setShardQueryProject(filename, rs.Spec.QueryProjectID, result)
 
 		if rs.Status.SetMonitoringCondition(rs.GetGeneration(), now, conditionSuccess) {
 			statusUpdates = append(statusUpdates, rs)
@@ -323,7 +485,16 @@ func (r *rulesReconciler) ensureRuleConfigs(ctx context.Context, projectID, loca
 	}
 	for i := range globalRulesList.Items {
 		rs := &globalRulesList.Items[i]
-		result, err := rs.RuleGroupsConfig()
+		result, err := r.ruleFiles.get(ctx, rs, fmt.Sprintf("%d/%s", shards, allowed), func() ([]string, error) {
+			if err := checkQueryProject(allowedQueryProjects, rs.Spec.QueryProjectID); err != nil {
+				return nil, err
+			}
+			return shardRuleFiles("/"+rs.Name, rs.Spec.Groups, shards, func(groups []monitoringv1.RuleGroup) (string, error) {
+				shard := *rs
+				shard.Spec.Groups = groups
+				return shard.RuleGroupsConfig()
+			})
+		})
 		if err != nil {
 			msg := "generating rule config failed"
 			if rs.Status.SetMonitoringCondition(rs.Generation, now, &monitoringv1.MonitoringCondition{
@@ -337,23 +508,56 @@ func (r *rulesReconciler) ensureRuleConfigs(ctx context.Context, projectID, loca
 			logger.Error(err, "convert rules", "err", err, "namespace", rs.Namespace, "name", rs.Name)
 			continue
 		}
-		filename := fmt.Sprintf("globalrules__%s.yaml", rs.Name)
-		if err := setConfigMapData(cm, configCompression, filename, result); err != nil {
+		filename := globalRulesFilename(rs.Name)
+		if err := setShardData(filename, result); err != nil {
 			return err
 		}
+		setShardQueryProject(filename, rs.Spec.QueryProjectID, result)
 
 		if rs.Status.SetMonitoringCondition(rs.GetGeneration(), now, conditionSuccess) {
 			statusUpdates = append(statusUpdates, rs)
 		}
 	}
 
-	// Create or update generated rule ConfigMap.
-	if err := r.client.Update(ctx, cm); apierrors.IsNotFound(err) {
-		if err := r.client.Create(ctx, cm); err != nil {
-			return fmt.Errorf("create generated rules: %w", err)
+	if selfMonitoring {
+		rs := selfMonitoringRules(r.opts.OperatorNamespace)
+		result, err := shardRuleFiles("/"+rs.Name, rs.Spec.Groups, shards, func(groups []monitoringv1.RuleGroup) (string, error) {
+			shard := *rs
+			shard.Spec.Groups = groups
+			return shard.RuleGroupsConfig(projectID, location, cluster)
+		})
+		if err != nil {
+			return fmt.Errorf("generate self-monitoring rules: %w", err)
+		}
+		if err := setShardData(nameSelfMonitoringRules+".yaml", result); err != nil {
+			return err
 		}
-	} else if err != nil {
-		return fmt.Errorf("update generated rules: %w", err)
+	}
+
+	for i, projects := range queryProjects {
+		if projects == nil {
+			continue
+		}
+		data, err := json.Marshal(projects)
+		if err != nil {
+			return fmt.Errorf("marshal query projects: %w", err)
+		}
+		if err := setConfigMapData(cms[i], configCompression, ruleQueryProjectsFilename, string(data)); err != nil {
+			return err
+		}
+	}
+
+	// All current objects were requested, so remaining entries belong to deleted objects.
+	r.ruleFiles.prune()
+
+	// Create or update generated rule ConfigMaps.
+	for _, cm := range cms {
+		if err := writeConfigMap(ctx, r.client, cm); err != nil {
+			return fmt.Errorf("write generated rules: %w", err)
+		}
+	}
+	if err := r.deleteStaleRuleShards(ctx, int(shards)); err != nil {
+		return err
 	}
 
 	var errs []error
@@ -365,3 +569,35 @@ func (r *rulesReconciler) ensureRuleConfigs(ctx context.Context, projectID, loca
 
 	return errors.Join(errs...)
 }
+
+// deleteStaleRuleShards deletes the generated rule ConfigMaps of shards beyond the shard count,
+// along with the ConfigMaps they were split into.
+func (r *rulesReconciler) deleteStaleRuleShards(ctx context.Context, shards int) error {
+	var cms corev1.ConfigMapList
+	if err := r.client.List(ctx, &cms, client.InNamespace(r.opts.OperatorNamespace), client.HasLabels{labelRuleShard}); err != nil {
+		return fmt.Errorf("list generated rules: %w", err)
+	}
+	for i := range cms.Items {
+		cm := &cms.Items[i]
+		// ConfigMaps split off due to their size inherit the shard label.
+		if _, ok := cm.Labels[labelConfigShardOf]; ok {
+			continue
+		}
+		if shard, ok := ruleShardOf(cm); !ok || shard < shards {
+			continue
+		}
+		if err := r.client.Delete(ctx, cm); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("delete generated rules %q: %w", cm.Name, err)
+		}
+		for j := range cms.Items {
+			split := &cms.Items[j]
+			if split.Labels[labelConfigShardOf] != cm.Name {
+				continue
+			}
+			if err := r.client.Delete(ctx, split); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("delete ConfigMap shard %q: %w", split.Name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/template"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+// defaultRuleGroupInterval is the evaluation interval of rule groups that don't set one.
+const defaultRuleGroupInterval = time.Minute
+
+// highCardinalityLabels are labels that usually have a distinct value per target or
+// container, which makes them a poor choice to aggregate recording rules by.
+var highCardinalityLabels = map[string]bool{
+	"pod":          true,
+	"pod_name":     true,
+	"pod_ip":       true,
+	"instance":     true,
+	"container_id": true,
+	"uid":          true,
+	"ip":           true,
+}
+
+// rulesValidator rejects Rules, ClusterRules and GlobalRules whose rule files cannot be
+// generated or that query a project that isn't allowed. The lint checks enabled in the
+// OperatorConfig are reported as warnings.
+type rulesValidator struct {
+	client client.Reader
+	opts   *Options
+}
+
+func newRulesValidator(c client.Reader, opts *Options) *rulesValidator {
+	return &rulesValidator{client: c, opts: opts}
+}
+
+func (v *rulesValidator) ValidateCreate(ctx context.Context, o runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, o)
+}
+
+func (v *rulesValidator) ValidateUpdate(ctx context.Context, _, o runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, o)
+}
+
+func (v *rulesValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *rulesValidator) validate(ctx context.Context, o runtime.Object) (admission.Warnings, error) {
+	var (
+		spec *monitoringv1.RulesSpec
+		// Namespace the selectors of the rules are scoped to, if any.
+		namespace string
+	)
+	switch obj := o.(type) {
+	case *monitoringv1.Rules:
+		if _, err := obj.ValidateCreate(); err != nil {
+			return nil, err
+		}
+		spec, namespace = &obj.Spec, obj.Namespace
+	case *monitoringv1.ClusterRules:
+		if _, err := obj.ValidateCreate(); err != nil {
+			return nil, err
+		}
+		spec = &obj.Spec
+	case *monitoringv1.GlobalRules:
+		if _, err := obj.ValidateCreate(); err != nil {
+			return nil, err
+		}
+		spec = &obj.Spec
+	default:
+		return nil, fmt.Errorf("unexpected object type %T", o)
+	}
+	config, err := getOperatorConfig(ctx, v.client, v.opts.PublicNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkQueryProject(config.Rules.AllowedQueryProjectIDs, spec.QueryProjectID); err != nil {
+		return nil, err
+	}
+	return lintRuleGroups(&config.Features.RuleLint, spec.Groups, namespace), nil
+}
+
+// lintRuleGroups returns warnings for the rules that fail the enabled lint checks. The rules
+// must be valid.
+func lintRuleGroups(checks *monitoringv1.RuleLintSpec, groups []monitoringv1.RuleGroup, namespace string) admission.Warnings {
+	var warnings admission.Warnings
+	for _, g := range groups {
+		interval := defaultRuleGroupInterval
+		if d, err := prommodel.ParseDuration(g.Interval); err == nil {
+			interval = time.Duration(d)
+		}
+		for _, r := range g.Rules {
+			warn := func(format string, args ...any) {
+				warnings = append(warnings, fmt.Sprintf("group %q, rule %q: %s", g.Name, cmp.Or(r.Alert, r.Record), fmt.Sprintf(format, args...)))
+			}
+			if checks.MissingFor && r.Alert != "" {
+				if d, err := prommodel.ParseDuration(r.For); r.For == "" || (err == nil && d == 0) {
+					warn("alerting rule has no 'for' duration and fires on the result of a single evaluation")
+				}
+			}
+			if checks.InvalidTemplate && r.Alert != "" {
+				for _, name := range slices.Sorted(maps.Keys(r.Labels)) {
+					if err := parseAlertTemplate(r.Alert, r.Labels[name]); err != nil {
+						warn("template of label %q does not parse: %s", name, err)
+					}
+				}
+				for _, name := range slices.Sorted(maps.Keys(r.Annotations)) {
+					if err := parseAlertTemplate(r.Alert, r.Annotations[name]); err != nil {
+						warn("template of annotation %q does not parse: %s", name, err)
+					}
+				}
+			}
+
+			expr, err := parser.ParseExpr(r.Expr)
+			if err != nil {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				switch n := node.(type) {
+				case *parser.Call:
+					switch n.Func.Name {
+					case "rate", "irate", "increase":
+						if name := rangeMetricName(n.Args[0]); checks.CounterFunctionOnGauge && name != "" && !isCounterName(name) {
+							warn("%s() is applied to %q, which is not a counter by naming convention", n.Func.Name, name)
+						}
+					case "absent", "absent_over_time":
+						if checks.ScopedAbsent && namespace != "" {
+							warn("%s() only considers series with the label namespace=%q and always returns a result for metrics without a namespace label", n.Func.Name, namespace)
+						}
+					}
+				case *parser.MatrixSelector:
+					if checks.ShortRange && n.Range < 2*interval {
+						warn("range of %s is shorter than twice the group interval of %s", n, prommodel.Duration(interval))
+					}
+				case *parser.AggregateExpr:
+					if !checks.HighCardinalityBy || r.Record == "" || n.Without {
+						break
+					}
+					for _, l := range n.Grouping {
+						if highCardinalityLabels[l] {
+							warn("recording rule aggregates by the high cardinality label %q", l)
+						}
+					}
+				}
+				return nil
+			})
+		}
+	}
+	return warnings
+}
+
+// rangeMetricName returns the metric name of a range selector, or an empty string if it
+// isn't a range selector of a single metric.
+func rangeMetricName(node parser.Node) string {
+	ms, ok := node.(*parser.MatrixSelector)
+	if !ok {
+		return ""
+	}
+	vs, ok := ms.VectorSelector.(*parser.VectorSelector)
+	if !ok {
+		return ""
+	}
+	if vs.Name != "" {
+		return vs.Name
+	}
+	for _, m := range vs.LabelMatchers {
+		if m.Name == labels.MetricName && m.Type == labels.MatchEqual {
+			return m.Value
+		}
+	}
+	return ""
+}
+
+// isCounterName returns whether the metric name follows the naming conventions of counters,
+// including the counters that make up summaries and histograms.
+func isCounterName(name string) bool {
+	for _, suffix := range []string{"_total", "_count", "_sum", "_bucket"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAlertTemplate parses a label or annotation template of an alerting rule with the
+// same variables the rule-evaluator expands it with.
+func parseAlertTemplate(alert, text string) error {
+	defs := "{{$labels := .Labels}}{{$externalLabels := .ExternalLabels}}{{$externalURL := .ExternalURL}}{{$value := .Value}}"
+	expander := template.NewTemplateExpander(
+		context.Background(),
+		defs+text,
+		"__alert_"+alert,
+		template.AlertTemplateData(map[string]string{}, map[string]string{}, "", promql.Sample{}),
+		prommodel.Time(timestamp.FromTime(time.Now())),
+		nil,
+		nil,
+		nil,
+	)
+	return expander.ParseTest()
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+func TestRulesValidator(t *testing.T) {
+	opts := &Options{
+		ProjectID:       "test-proj",
+		Location:        "us-central1-c",
+		Cluster:         "test-cluster",
+		PublicNamespace: "gmp-public",
+	}
+	c := newFakeClientBuilder().WithObjects(&monitoringv1.OperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "gmp-public", Name: NameOperatorConfig},
+		Rules: monitoringv1.RuleEvaluatorSpec{
+			AllowedQueryProjectIDs: []string{"team-proj"},
+		},
+		Features: monitoringv1.OperatorFeatures{
+			RuleLint: monitoringv1.RuleLintSpec{MissingFor: true, ScopedAbsent: true},
+		},
+	}).Build()
+	v := newRulesValidator(c, opts)
+
+	spec := func(rules ...monitoringv1.Rule) monitoringv1.RulesSpec {
+		return monitoringv1.RulesSpec{Groups: []monitoringv1.RuleGroup{{Name: "group", Interval: "1m", Rules: rules}}}
+	}
+	absent := monitoringv1.Rule{Alert: "Absent", Expr: "absent(up)", For: "5m"}
+	noFor := monitoringv1.Rule{Alert: "Down", Expr: "up == 0"}
+	invalid := monitoringv1.Rule{Record: "invalid", Expr: "sum("}
+	withQueryProject := func(spec monitoringv1.RulesSpec, projectID string) monitoringv1.RulesSpec {
+		spec.QueryProjectID = projectID
+		return spec
+	}
+
+	for _, tc := range []struct {
+		desc         string
+		obj          runtime.Object
+		wantErr      bool
+		wantWarnings int
+	}{
+		{
+			desc:         "Rules",
+			obj:          &monitoringv1.Rules{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "test"}, Spec: spec(absent, noFor)},
+			wantWarnings: 2,
+		},
+		{
+			desc:    "invalid Rules",
+			obj:     &monitoringv1.Rules{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "test"}, Spec: spec(invalid)},
+			wantErr: true,
+		},
+		{
+			desc:         "ClusterRules are not scoped to a namespace",
+			obj:          &monitoringv1.ClusterRules{ObjectMeta: metav1.ObjectMeta{Name: "test"}, Spec: spec(absent, noFor)},
+			wantWarnings: 1,
+		},
+		{
+			desc:         "allowed query project",
+			obj:          &monitoringv1.ClusterRules{ObjectMeta: metav1.ObjectMeta{Name: "test"}, Spec: withQueryProject(spec(noFor), "team-proj")},
+			wantWarnings: 1,
+		},
+		{
+			desc:    "query project not allowed",
+			obj:     &monitoringv1.Rules{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "test"}, Spec: withQueryProject(spec(noFor), "other-proj")},
+			wantErr: true,
+		},
+		{
+			desc:    "invalid GlobalRules",
+			obj:     &monitoringv1.GlobalRules{ObjectMeta: metav1.ObjectMeta{Name: "test"}, Spec: spec(invalid)},
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			warnings, err := v.ValidateCreate(t.Context(), tc.obj)
+			if tc.wantErr && err == nil {
+				t.Fatal("expected error")
+			} else if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(warnings) != tc.wantWarnings {
+				t.Errorf("expected %d warnings, got %v", tc.wantWarnings, warnings)
+			}
+		})
+	}
+}
+
+func TestLintRuleGroups(t *testing.T) {
+	all := &monitoringv1.RuleLintSpec{
+		CounterFunctionOnGauge: true,
+		MissingFor:             true,
+		ShortRange:             true,
+		ScopedAbsent:           true,
+		HighCardinalityBy:      true,
+		InvalidTemplate:        true,
+	}
+	for _, tc := range []struct {
+		desc      string
+		checks    *monitoringv1.RuleLintSpec
+		namespace string
+		group     monitoringv1.RuleGroup
+		want      admission.Warnings
+	}{
+		{
+			desc:   "no findings",
+			checks: all,
+			group: monitoringv1.RuleGroup{
+				Name:     "group",
+				Interval: "30s",
+				Rules: []monitoringv1.Rule{
+					{Record: "job:requests:rate5m", Expr: `sum by (job) (rate(requests_total[5m])) / sum by (job) (rate({__name__="duration_seconds_count"}[5m]))`},
+					{Alert: "Down", Expr: "up == 0", For: "5m", Annotations: map[string]string{"summary": "{{ $labels.instance }} is down"}},
+					{Record: "pod:memory:sum", Expr: "sum without (container) (memory_bytes)"},
+				},
+			},
+		},
+		{
+			desc:   "checks disabled",
+			checks: &monitoringv1.RuleLintSpec{},
+			group: monitoringv1.RuleGroup{
+				Name: "group",
+				Rules: []monitoringv1.Rule{
+					{Alert: "Down", Expr: "rate(memory_bytes[1m]) > 0", Annotations: map[string]string{"summary": "{{ $labels"}},
+				},
+			},
+		},
+		{
+			desc:      "findings",
+			checks:    all,
+			namespace: "team-a",
+			group: monitoringv1.RuleGroup{
+				Name:     "group",
+				Interval: "1m",
+				Rules: []monitoringv1.Rule{
+					{Record: "pod:memory:rate", Expr: "sum by (pod, container) (rate(memory_bytes[5m]))"},
+					{Alert: "Missing", Expr: "absent_over_time(up[1m])", For: "0s", Labels: map[string]string{"severity": "{{ if }}"}, Annotations: map[string]string{"summary": "{{ $labels"}},
+				},
+			},
+			want: admission.Warnings{
+				`group "group", rule "pod:memory:rate": recording rule aggregates by the high cardinality label "pod"`,
+				`group "group", rule "pod:memory:rate": rate() is applied to "memory_bytes", which is not a counter by naming convention`,
+				`group "group", rule "Missing": alerting rule has no 'for' duration and fires on the result of a single evaluation`,
+				`group "group", rule "Missing": template of label "severity" does not parse: template: __alert_Missing:1: missing value for if`,
+				`group "group", rule "Missing": template of annotation "summary" does not parse: template: __alert_Missing:1: unclosed action`,
+				`group "group", rule "Missing": absent_over_time() only considers series with the label namespace="team-a" and always returns a result for metrics without a namespace label`,
+				`group "group", rule "Missing": range of up[1m] is shorter than twice the group interval of 1m`,
+			},
+		},
+		{
+			desc:   "default interval",
+			checks: &monitoringv1.RuleLintSpec{ShortRange: true},
+			group: monitoringv1.RuleGroup{
+				Name:  "group",
+				Rules: []monitoringv1.Rule{{Record: "job:up:rate", Expr: "sum by (job) (rate(up[90s]))"}},
+			},
+			want: admission.Warnings{
+				`group "group", rule "job:up:rate": range of up[1m30s] is shorter than twice the group interval of 1m`,
+			},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := lintRuleGroups(tc.checks, []monitoringv1.RuleGroup{tc.group}, tc.namespace)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected warnings (-want, +got): %s", diff)
+			}
+		})
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -365,7 +366,7 @@ func TestRulesStatus(t *testing.T) {
 		client: kubeClient,
 	}
 
-	if err := r.ensureRuleConfigs(t.Context(), "", "", "", monitoringv1.CompressionNone); err != nil {
+	if err := r.ensureRuleConfigs(t.Context(), "", "", "", monitoringv1.CompressionNone, nil, false, 1, nil); err != nil {
 		t.Fatal("ensure rules configs:", err)
 	}
 
@@ -398,6 +399,58 @@ func TestRulesStatus(t *testing.T) {
 	}
 }
 
+func TestEnsureRuleConfigsQueryProjects(t *testing.T) {
+	groups := []monitoringv1.RuleGroup{{
+		Name:  "group",
+		Rules: []monitoringv1.Rule{{Record: "foo", Expr: "bar"}},
+	}}
+	allowed := &monitoringv1.Rules{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "allowed"},
+		Spec:       monitoringv1.RulesSpec{QueryProjectID: "team-proj", Groups: groups},
+	}
+	notAllowed := &monitoringv1.ClusterRules{
+		ObjectMeta: metav1.ObjectMeta{Name: "not-allowed"},
+		Spec:       monitoringv1.RulesSpec{QueryProjectID: "other-proj", Groups: groups},
+	}
+	defaultProject := &monitoringv1.GlobalRules{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec:       monitoringv1.RulesSpec{Groups: groups},
+	}
+	kubeClient := newFakeClientBuilder().
+		WithObjects(allowed, notAllowed, defaultProject).
+		Build()
+	r := rulesReconciler{
+		client: kubeClient,
+		opts:   Options{OperatorNamespace: "gmp-system"},
+	}
+
+	if err := r.ensureRuleConfigs(t.Context(), "", "", "", monitoringv1.CompressionNone, nil, false, 1, []string{"team-proj"}); err != nil {
+		t.Fatal(err)
+	}
+	var cm corev1.ConfigMap
+	if err := kubeClient.Get(t.Context(), client.ObjectKey{Namespace: "gmp-system", Name: ruleShardName(nameRulesGenerated, 0)}, &cm); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"rules__ns__allowed.yaml", "globalrules__default.yaml"} {
+		if _, ok := cm.Data[file]; !ok {
+			t.Errorf("expected rule file %q", file)
+		}
+	}
+	if _, ok := cm.Data["clusterrules__not-allowed.yaml"]; ok {
+		t.Error("unexpected rule file of rules with a query project that isn't allowed")
+	}
+	if diff := cmp.Diff(`{"rules__ns__allowed.yaml":"team-proj"}`, cm.Data[ruleQueryProjectsFilename]); diff != "" {
+		t.Errorf("unexpected query projects (-want, +got): %s", diff)
+	}
+
+	if err := kubeClient.Get(t.Context(), client.ObjectKeyFromObject(notAllowed), notAllowed); err != nil {
+		t.Fatal(err)
+	}
+	if conds := notAllowed.Status.Conditions; len(conds) != 1 || conds[0].Status != corev1.ConditionFalse {
+		t.Errorf("expected failed condition, got %v", conds)
+	}
+}
+
 func TestScaleRuleConsumers(t *testing.T) {
 	var alertmanagerReplicas int32
 	alertManager := appsv1.StatefulSet{
@@ -427,29 +480,44 @@ func TestScaleRuleConsumers(t *testing.T) {
 	ruleEvaluatorDeleted := newFakeClientBuilder().WithObjects(&alertManager).Build()
 	alertmanagerDeletedWithRules := newFakeClientBuilder().WithObjects(&ruleEvaluator, &monitoringv1.Rules{}).Build()
 	ruleEvaluatorDeletedWithRules := newFakeClientBuilder().WithObjects(&alertManager, &monitoringv1.Rules{}).Build()
+	autoscaledWithRules := newFakeClientBuilder().WithObjects(&alertManager, &ruleEvaluator, &monitoringv1.Rules{}, &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "rule-evaluator",
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "rule-evaluator",
+			},
+		},
+	}).Build()
 
 	type test struct {
-		client  client.Client
-		want    int32
-		wantErr bool
+		client            client.Client
+		want              int32
+		wantRuleEvaluator int32
+		wantErr           bool
 	}
 
 	tests := map[string]test{
-		"no rules":                          {client: emptyRules, want: 0},
-		"has rule":                          {client: hasRule, want: 1},
+		"no rules":                          {client: emptyRules, want: 0, wantRuleEvaluator: 0},
+		"has rule":                          {client: hasRule, want: 1, wantRuleEvaluator: 2},
 		"error":                             {client: errGettingRules, want: 0, wantErr: true},
 		"alertmanager deleted":              {client: alertmanagerDeleted, want: 0, wantErr: false},
 		"rule-evaluator deleted":            {client: ruleEvaluatorDeleted, want: 0, wantErr: false},
-		"alertmanager deleted with rules":   {client: alertmanagerDeletedWithRules, want: 1, wantErr: false},
-		"rule-evaluator deleted with rules": {client: ruleEvaluatorDeletedWithRules, want: 1, wantErr: false},
+		"alertmanager deleted with rules":   {client: alertmanagerDeletedWithRules, want: 1, wantRuleEvaluator: 2, wantErr: false},
+		"rule-evaluator deleted with rules": {client: ruleEvaluatorDeletedWithRules, want: 1, wantRuleEvaluator: 2, wantErr: false},
+		"autoscaled with rules":             {client: autoscaledWithRules, want: 1, wantRuleEvaluator: 0, wantErr: false},
 	}
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
 			r := rulesReconciler{
 				client: &fakeClientWithScale{tc.client},
+				opts:   Options{RuleEvaluatorReplicas: 2},
 			}
-			err := r.scaleRuleConsumers(t.Context())
+			err := r.scaleRuleConsumers(t.Context(), false)
 			if err != nil {
 				if !tc.wantErr {
 					t.Errorf("Unexpected error: %s", err)
@@ -469,8 +537,8 @@ func TestScaleRuleConsumers(t *testing.T) {
 			if err := r.client.Get(t.Context(), client.ObjectKey{Name: "rule-evaluator"}, &ruleEvaluator); client.IgnoreNotFound(err) != nil {
 				t.Error(err)
 			}
-			if ruleEvaluator.Spec.Replicas != nil && *ruleEvaluator.Spec.Replicas != tc.want {
-				t.Errorf("want: %d, got: %d", tc.want, ruleEvaluator.Spec.Replicas)
+			if ruleEvaluator.Spec.Replicas != nil && *ruleEvaluator.Spec.Replicas != tc.wantRuleEvaluator {
+				t.Errorf("want: %d, got: %d", tc.wantRuleEvaluator, *ruleEvaluator.Spec.Replicas)
 			}
 		})
 	}
@@ -86,170 +86,139 @@ func (r *scalingReconciler) Reconcile(ctx context.Context, req reconcile.Request
 		return reconcile.Result{}, fmt.Errorf("get operatorconfig: %w", err)
 	}
 
-	switch {
-	case config.Scaling.VPA.Enabled:
-		// Apply VPA
-		if err := applyVPA(ctx, r.client, r.opts.OperatorNamespace); err != nil {
-			return reconcile.Result{}, err
-		}
-		return reconcile.Result{}, nil
-	default:
-		return reconcile.Result{}, deleteVPA(ctx, r.client, r.opts.OperatorNamespace)
+	if config.Scaling.VPA.Enabled {
+		return reconcile.Result{}, applyVPA(ctx, r.client, r.opts.OperatorNamespace, &config.Scaling.VPA)
 	}
+	return reconcile.Result{}, deleteVPA(ctx, r.client, r.opts.OperatorNamespace)
 }
 
-func applyVPA(ctx context.Context, c client.Client, namespace string) error {
-	alertmanagerVPA := autoscalingv1.VerticalPodAutoscaler{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: namespace,
-			Name:      alertmanagerVPAName,
-		},
+// vpaTarget holds the operator defaults of the VPA of a workload.
+type vpaTarget struct {
+	name        string
+	kind        string
+	container   string
+	minReplicas *int32
+	minAllowed  corev1.ResourceList
+	// configReloader is whether the pods have a config-reloader sidecar, which is not scaled.
+	configReloader bool
+}
+
+// spec returns the VPA spec of the target with the policy applied to the main container.
+func (t *vpaTarget) spec(policy *monitoringv1.VPAPolicy) autoscalingv1.VerticalPodAutoscalerSpec {
+	container := autoscalingv1.ContainerResourcePolicy{
+		ContainerName: t.container,
+		Mode:          ptr.To(autoscalingv1.ContainerScalingModeAuto),
+		MinAllowed:    t.minAllowed,
 	}
-	if _, err := controllerutil.CreateOrUpdate(ctx, c, &alertmanagerVPA, func() error {
-		alertmanagerVPA.Spec = autoscalingv1.VerticalPodAutoscalerSpec{
-			TargetRef: &autoscaling.CrossVersionObjectReference{
-				APIVersion: "apps/v1",
-				Kind:       "StatefulSet",
-				Name:       alertmanagerVPAName,
-			},
-			UpdatePolicy: &autoscalingv1.PodUpdatePolicy{
-				MinReplicas: ptr.To(int32(1)),
-				UpdateMode:  ptr.To(autoscalingv1.UpdateModeAuto),
-			},
-			ResourcePolicy: &autoscalingv1.PodResourcePolicy{
-				ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
-					{
-						ContainerName: "alertmanager",
-						Mode:          ptr.To(autoscalingv1.ContainerScalingModeAuto),
-						MinAllowed: corev1.ResourceList{
-							corev1.ResourceCPU:    resource.MustParse("1m"),
-							corev1.ResourceMemory: resource.MustParse("16Mi"),
-						},
-					},
-					{
-						ContainerName: "config-reloader",
-						Mode:          ptr.To(autoscalingv1.ContainerScalingModeOff),
-					},
-				},
-			},
+	updateMode := autoscalingv1.UpdateModeAuto
+	if policy != nil {
+		if policy.MinAllowed != nil {
+			container.MinAllowed = policy.MinAllowed
+		}
+		container.MaxAllowed = policy.MaxAllowed
+		if len(policy.ControlledResources) > 0 {
+			container.ControlledResources = ptr.To(policy.ControlledResources)
+		}
+		if policy.UpdateMode != "" {
+			updateMode = autoscalingv1.UpdateMode(policy.UpdateMode)
 		}
-		return nil
-	}); err != nil {
-		return err
 	}
-
-	collectorVPA := autoscalingv1.VerticalPodAutoscaler{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: namespace,
-			Name:      collectorVPAName,
+	containers := []autoscalingv1.ContainerResourcePolicy{container}
+	if t.configReloader {
+		containers = append(containers, autoscalingv1.ContainerResourcePolicy{
+			ContainerName: "config-reloader",
+			Mode:          ptr.To(autoscalingv1.ContainerScalingModeOff),
+		})
+	}
+	return autoscalingv1.VerticalPodAutoscalerSpec{
+		TargetRef: &autoscaling.CrossVersionObjectReference{
+			APIVersion: "apps/v1",
+			Kind:       t.kind,
+			Name:       t.name,
+		},
+		UpdatePolicy: &autoscalingv1.PodUpdatePolicy{
+			MinReplicas: t.minReplicas,
+			UpdateMode:  ptr.To(updateMode),
+		},
+		ResourcePolicy: &autoscalingv1.PodResourcePolicy{
+			ContainerPolicies: containers,
 		},
 	}
-	if _, err := controllerutil.CreateOrUpdate(ctx, c, &collectorVPA, func() error {
-		collectorVPA.Spec = autoscalingv1.VerticalPodAutoscalerSpec{
-			TargetRef: &autoscaling.CrossVersionObjectReference{
-				APIVersion: "apps/v1",
-				Kind:       "DaemonSet",
-				Name:       collectorVPAName,
-			},
-			UpdatePolicy: &autoscalingv1.PodUpdatePolicy{
-				UpdateMode: ptr.To(autoscalingv1.UpdateModeAuto),
-			},
-			ResourcePolicy: &autoscalingv1.PodResourcePolicy{
-				ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
-					{
-						ContainerName: "prometheus",
-						Mode:          ptr.To(autoscalingv1.ContainerScalingModeAuto),
-						MinAllowed: corev1.ResourceList{
-							corev1.ResourceCPU:    resource.MustParse("4m"),
-							corev1.ResourceMemory: resource.MustParse("32Mi"),
-						},
-					},
-					{
-						ContainerName: "config-reloader",
-						Mode:          ptr.To(autoscalingv1.ContainerScalingModeOff),
-					},
+}
+
+func applyVPA(ctx context.Context, c client.Client, namespace string, spec *monitoringv1.VPASpec) error {
+	targets := []struct {
+		vpaTarget
+		policy *monitoringv1.VPAPolicy
+	}{
+		{
+			vpaTarget: vpaTarget{
+				name:        alertmanagerVPAName,
+				kind:        "StatefulSet",
+				container:   AlertmanagerContainerName,
+				minReplicas: ptr.To(int32(1)),
+				minAllowed: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1m"),
+					corev1.ResourceMemory: resource.MustParse("16Mi"),
 				},
+				configReloader: true,
 			},
-		}
-		return nil
-	}); err != nil {
-		return err
-	}
-
-	operatorVPA := autoscalingv1.VerticalPodAutoscaler{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: namespace,
-			Name:      operatorVPAName,
+			policy: spec.Alertmanager,
 		},
-	}
-	if _, err := controllerutil.CreateOrUpdate(ctx, c, &operatorVPA, func() error {
-		collectorVPA.Spec = autoscalingv1.VerticalPodAutoscalerSpec{
-			TargetRef: &autoscaling.CrossVersionObjectReference{
-				APIVersion: "apps/v1",
-				Kind:       "Deployment",
-				Name:       operatorVPAName,
+		{
+			vpaTarget: vpaTarget{
+				name:      collectorVPAName,
+				kind:      "DaemonSet",
+				container: CollectorPrometheusContainerName,
+				minAllowed: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("4m"),
+					corev1.ResourceMemory: resource.MustParse("32Mi"),
+				},
+				configReloader: true,
 			},
-			UpdatePolicy: &autoscalingv1.PodUpdatePolicy{
-				MinReplicas: ptr.To(int32(1)),
-				UpdateMode:  ptr.To(autoscalingv1.UpdateModeAuto),
+			policy: spec.Collector,
+		},
+		{
+			vpaTarget: vpaTarget{
+				name:        operatorVPAName,
+				kind:        "Deployment",
+				container:   "operator",
+				minReplicas: ptr.To(int32(1)),
+				minAllowed: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1m"),
+					corev1.ResourceMemory: resource.MustParse("16Mi"),
+				},
 			},
-			ResourcePolicy: &autoscalingv1.PodResourcePolicy{
-				ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
-					{
-						ContainerName: "operator",
-						Mode:          ptr.To(autoscalingv1.ContainerScalingModeAuto),
-						MinAllowed: corev1.ResourceList{
-							corev1.ResourceCPU:    resource.MustParse("1m"),
-							corev1.ResourceMemory: resource.MustParse("16Mi"),
-						},
-					},
+		},
+		{
+			vpaTarget: vpaTarget{
+				name:        ruleEvaluatorVPAName,
+				kind:        "Deployment",
+				container:   RuleEvaluatorContainerName,
+				minReplicas: ptr.To(int32(1)),
+				minAllowed: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1m"),
+					corev1.ResourceMemory: resource.MustParse("16Mi"),
 				},
+				configReloader: true,
 			},
-		}
-		return nil
-	}); err != nil {
-		return err
-	}
-
-	ruleEvaluatorVPA := autoscalingv1.VerticalPodAutoscaler{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: namespace,
-			Name:      ruleEvaluatorVPAName,
+			policy: spec.RuleEvaluator,
 		},
 	}
-	if _, err := controllerutil.CreateOrUpdate(ctx, c, &ruleEvaluatorVPA, func() error {
-		collectorVPA.Spec = autoscalingv1.VerticalPodAutoscalerSpec{
-			TargetRef: &autoscaling.CrossVersionObjectReference{
-				APIVersion: "apps/v1",
-				Kind:       "Deployment",
-				Name:       ruleEvaluatorVPAName,
-			},
-			UpdatePolicy: &autoscalingv1.PodUpdatePolicy{
-				MinReplicas: ptr.To(int32(1)),
-				UpdateMode:  ptr.To(autoscalingv1.UpdateModeAuto),
-			},
-			ResourcePolicy: &autoscalingv1.PodResourcePolicy{
-				ContainerPolicies: []autoscalingv1.ContainerResourcePolicy{
-					{
-						ContainerName: "evaluator",
-						Mode:          ptr.To(autoscalingv1.ContainerScalingModeAuto),
-						MinAllowed: corev1.ResourceList{
-							corev1.ResourceCPU:    resource.MustParse("1m"),
-							corev1.ResourceMemory: resource.MustParse("16Mi"),
-						},
-					},
-					{
-						ContainerName: "config-reloader",
-						Mode:          ptr.To(autoscalingv1.ContainerScalingModeOff),
-					},
-				},
+	for _, t := range targets {
+		vpa := autoscalingv1.VerticalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      t.name,
 			},
 		}
-		return nil
-	}); err != nil {
-		return err
+		if _, err := controllerutil.CreateOrUpdate(ctx, c, &vpa, func() error {
+			vpa.Spec = t.spec(t.policy)
+			return nil
+		}); err != nil {
+			return err
+		}
 	}
-
 	return nil
 }
 
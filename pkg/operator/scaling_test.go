@@ -19,12 +19,18 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	autoscalingv1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
 )
 
 func TestApplyVPA(t *testing.T) {
@@ -80,7 +86,7 @@ func TestApplyVPA(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			err := applyVPA(t.Context(), tc.c, "")
+			err := applyVPA(t.Context(), tc.c, "", &monitoringv1.VPASpec{Enabled: true})
 			switch {
 			case err != nil && !tc.wantErr:
 				t.Errorf("unexpected error: %v", err)
@@ -193,3 +199,60 @@ func TestDeleteVPA(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyVPAPolicy(t *testing.T) {
+	scheme, err := NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	maxAllowed := corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")}
+	spec := &monitoringv1.VPASpec{
+		Enabled: true,
+		RuleEvaluator: &monitoringv1.VPAPolicy{
+			MinAllowed:          corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+			MaxAllowed:          maxAllowed,
+			ControlledResources: []corev1.ResourceName{corev1.ResourceMemory},
+			UpdateMode:          "Initial",
+		},
+	}
+	if err := applyVPA(t.Context(), c, "", spec); err != nil {
+		t.Fatal(err)
+	}
+
+	var vpa autoscalingv1.VerticalPodAutoscaler
+	if err := c.Get(t.Context(), client.ObjectKey{Name: ruleEvaluatorVPAName}, &vpa); err != nil {
+		t.Fatal(err)
+	}
+	if got := *vpa.Spec.UpdatePolicy.UpdateMode; got != autoscalingv1.UpdateModeInitial {
+		t.Errorf("expected update mode %q, got %q", autoscalingv1.UpdateModeInitial, got)
+	}
+	want := []autoscalingv1.ContainerResourcePolicy{
+		{
+			ContainerName:       RuleEvaluatorContainerName,
+			Mode:                ptr.To(autoscalingv1.ContainerScalingModeAuto),
+			MinAllowed:          spec.RuleEvaluator.MinAllowed,
+			MaxAllowed:          maxAllowed,
+			ControlledResources: &[]corev1.ResourceName{corev1.ResourceMemory},
+		},
+		{
+			ContainerName: "config-reloader",
+			Mode:          ptr.To(autoscalingv1.ContainerScalingModeOff),
+		},
+	}
+	if diff := cmp.Diff(want, vpa.Spec.ResourcePolicy.ContainerPolicies); diff != "" {
+		t.Errorf("unexpected container policies (-want, +got): %s", diff)
+	}
+
+	// Components without a policy keep the defaults.
+	if err := c.Get(t.Context(), client.ObjectKey{Name: collectorVPAName}, &vpa); err != nil {
+		t.Fatal(err)
+	}
+	if got := *vpa.Spec.UpdatePolicy.UpdateMode; got != autoscalingv1.UpdateModeAuto {
+		t.Errorf("expected update mode %q, got %q", autoscalingv1.UpdateModeAuto, got)
+	}
+	if got := vpa.Spec.ResourcePolicy.ContainerPolicies[0].MinAllowed.Memory().String(); got != "32Mi" {
+		t.Errorf("expected default min allowed memory, got %s", got)
+	}
+}
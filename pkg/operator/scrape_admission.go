@@ -0,0 +1,149 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+// minScrapeInterval is the scrape interval below which admission warns about the ingestion
+// volume of an endpoint.
+const minScrapeInterval = 5 * time.Second
+
+// scrapeValidator rejects PodMonitorings, ClusterPodMonitorings and ClusterNodeMonitorings
+// whose scrape configs cannot be generated, running the same generation as the collection
+// controller. Settings that are valid but likely unintended are reported as warnings.
+type scrapeValidator struct {
+	client client.Reader
+	opts   *Options
+	// quota additionally validates PodMonitorings against the MonitoringQuota of their
+	// namespace.
+	quota *podMonitoringQuotaValidator
+}
+
+func newScrapeValidator(c client.Reader, opts *Options) *scrapeValidator {
+	return &scrapeValidator{
+		client: c,
+		opts:   opts,
+		quota:  &podMonitoringQuotaValidator{client: c},
+	}
+}
+
+func (v *scrapeValidator) ValidateCreate(ctx context.Context, o runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, o)
+}
+
+func (v *scrapeValidator) ValidateUpdate(ctx context.Context, _, o runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, o)
+}
+
+func (v *scrapeValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *scrapeValidator) validate(ctx context.Context, o runtime.Object) (admission.Warnings, error) {
+	config, err := getOperatorConfig(ctx, v.client, v.opts.PublicNamespace)
+	if err != nil {
+		return nil, err
+	}
+	nsFilter, err := newNamespaceFilter(v.client, &config.Namespaces)
+	if err != nil {
+		return nil, err
+	}
+	projectID, location, cluster := resolveLabels(v.opts.ProjectID, v.opts.Location, v.opts.Cluster, config.Collection.ExternalLabels)
+
+	switch obj := o.(type) {
+	case *monitoringv1.PodMonitoring:
+		pool := monitoringv1.PrometheusSecretConfigs{}
+		if _, err := obj.ScrapeConfigs(projectID, location, cluster, pool); err != nil {
+			return nil, err
+		}
+		warnings := scrapeEndpointWarnings(obj.Spec.Endpoints)
+		if ok, err := nsFilter.allowed(ctx, obj.Namespace); err != nil {
+			return nil, err
+		} else if !ok {
+			warnings = append(warnings, fmt.Sprintf("namespace %q is excluded by the namespaces filter of the OperatorConfig, endpoints will not be scraped", obj.Namespace))
+		}
+		quotaWarnings, err := v.quota.validate(ctx, obj)
+		return append(warnings, quotaWarnings...), err
+
+	case *monitoringv1.ClusterPodMonitoring:
+		pool := monitoringv1.PrometheusSecretConfigs{}
+		if _, err := obj.ScrapeConfigs(projectID, location, cluster, pool); err != nil {
+			return nil, err
+		}
+		if ns, err := nsFilter.deniedSecretNamespace(ctx, pool); err != nil {
+			return nil, err
+		} else if ns != "" {
+			return nil, fmt.Errorf("referenced secrets in namespace %q are excluded by the namespaces filter of the OperatorConfig", ns)
+		}
+		return scrapeEndpointWarnings(obj.Spec.Endpoints), nil
+
+	case *monitoringv1.ClusterNodeMonitoring:
+		if _, err := obj.ScrapeConfigs(projectID, location, cluster); err != nil {
+			return nil, err
+		}
+		var warnings admission.Warnings
+		if config.Collection.KubeletScraping != nil && (obj.Name == reservedKubeletJobName || obj.Name == reservedCAdvisorJobName) {
+			warnings = append(warnings, fmt.Sprintf("ClusterNodeMonitoring %q is not applied because kubelet scraping is enabled in the OperatorConfig", obj.Name))
+		}
+		for i, ep := range obj.Spec.Endpoints {
+			warnings = append(warnings, endpointWarnings(i, ep.Interval, ep.TLS != nil && ep.TLS.InsecureSkipVerify)...)
+		}
+		return warnings, nil
+	}
+	return nil, fmt.Errorf("unexpected object type %T", o)
+}
+
+// getOperatorConfig returns the OperatorConfig, or an empty one if it does not exist.
+func getOperatorConfig(ctx context.Context, c client.Reader, publicNamespace string) (*monitoringv1.OperatorConfig, error) {
+	var config monitoringv1.OperatorConfig
+	key := client.ObjectKey{Namespace: publicNamespace, Name: NameOperatorConfig}
+	if err := c.Get(ctx, key, &config); err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("get OperatorConfig: %w", err)
+	}
+	return &config, nil
+}
+
+// scrapeEndpointWarnings returns warnings for settings of the endpoints that are valid but
+// likely unintended.
+func scrapeEndpointWarnings(endpoints []monitoringv1.ScrapeEndpoint) admission.Warnings {
+	var warnings admission.Warnings
+	for i, ep := range endpoints {
+		warnings = append(warnings, endpointWarnings(i, ep.Interval, ep.TLS != nil && ep.TLS.InsecureSkipVerify)...)
+	}
+	return warnings
+}
+
+func endpointWarnings(index int, interval string, insecureSkipVerify bool) admission.Warnings {
+	var warnings admission.Warnings
+	if d, err := prommodel.ParseDuration(interval); err == nil && time.Duration(d) < minScrapeInterval {
+		warnings = append(warnings, fmt.Sprintf("endpoint with index %d has a scrape interval of %s, below %s, which may significantly increase ingestion volume", index, interval, minScrapeInterval))
+	}
+	if insecureSkipVerify {
+		warnings = append(warnings, fmt.Sprintf("endpoint with index %d disables TLS certificate verification", index))
+	}
+	return warnings
+}
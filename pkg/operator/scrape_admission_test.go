@@ -0,0 +1,138 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+func TestScrapeValidator(t *testing.T) {
+	opts := &Options{
+		ProjectID:       "test-proj",
+		Location:        "us-central1-c",
+		Cluster:         "test-cluster",
+		PublicNamespace: "gmp-public",
+	}
+	c := newFakeClientBuilder().WithObjects(&monitoringv1.OperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "gmp-public", Name: NameOperatorConfig},
+		Collection: monitoringv1.CollectionSpec{
+			KubeletScraping: &monitoringv1.KubeletScraping{Interval: "30s"},
+		},
+		Namespaces: monitoringv1.NamespaceFilter{Deny: []string{"ignored"}},
+	}).Build()
+	v := newScrapeValidator(c, opts)
+
+	podMonitoring := func(namespace string, endpoints ...monitoringv1.ScrapeEndpoint) *monitoringv1.PodMonitoring {
+		return &monitoringv1.PodMonitoring{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "test"},
+			Spec:       monitoringv1.PodMonitoringSpec{Endpoints: endpoints},
+		}
+	}
+	clusterPodMonitoring := func(endpoints ...monitoringv1.ScrapeEndpoint) *monitoringv1.ClusterPodMonitoring {
+		return &monitoringv1.ClusterPodMonitoring{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec:       monitoringv1.ClusterPodMonitoringSpec{Endpoints: endpoints},
+		}
+	}
+	endpoint := monitoringv1.ScrapeEndpoint{Port: intstr.FromString("metrics"), Interval: "10s"}
+	bearer := func(namespace string) monitoringv1.ScrapeEndpoint {
+		ep := endpoint
+		ep.Authorization = &monitoringv1.Auth{
+			Credentials: &monitoringv1.SecretSelector{
+				Secret: &monitoringv1.SecretKeySelector{Namespace: namespace, Name: "token", Key: "token"},
+			},
+		}
+		return ep
+	}
+	badRegex := endpoint
+	badRegex.MetricRelabeling = []monitoringv1.RelabelingRule{{Action: "drop", SourceLabels: []string{"foo"}, Regex: "("}}
+	risky := endpoint
+	risky.Interval = "1s"
+	risky.TLS = &monitoringv1.TLS{InsecureSkipVerify: true}
+
+	for _, tc := range []struct {
+		desc         string
+		obj          runtime.Object
+		wantErr      bool
+		wantWarnings int
+	}{
+		{
+			desc: "valid PodMonitoring",
+			obj:  podMonitoring("team-a", endpoint),
+		},
+		{
+			desc:    "invalid relabeling regex",
+			obj:     podMonitoring("team-a", badRegex),
+			wantErr: true,
+		},
+		{
+			desc:    "duplicate job names",
+			obj:     podMonitoring("team-a", endpoint, endpoint),
+			wantErr: true,
+		},
+		{
+			desc:    "secret in other namespace",
+			obj:     podMonitoring("team-a", bearer("team-b")),
+			wantErr: true,
+		},
+		{
+			desc:         "risky endpoint settings",
+			obj:          podMonitoring("team-a", risky),
+			wantWarnings: 2,
+		},
+		{
+			desc:         "ignored namespace",
+			obj:          podMonitoring("ignored", endpoint),
+			wantWarnings: 1,
+		},
+		{
+			desc: "valid ClusterPodMonitoring",
+			obj:  clusterPodMonitoring(bearer("team-b")),
+		},
+		{
+			desc:    "ClusterPodMonitoring secret in ignored namespace",
+			obj:     clusterPodMonitoring(bearer("ignored")),
+			wantErr: true,
+		},
+		{
+			desc: "ClusterNodeMonitoring replaced by kubelet scraping",
+			obj: &monitoringv1.ClusterNodeMonitoring{
+				ObjectMeta: metav1.ObjectMeta{Name: reservedKubeletJobName},
+				Spec: monitoringv1.ClusterNodeMonitoringSpec{
+					Endpoints: []monitoringv1.ScrapeNodeEndpoint{{Path: "/metrics", Interval: "30s"}},
+				},
+			},
+			wantWarnings: 1,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			warnings, err := v.ValidateCreate(t.Context(), tc.obj)
+			if tc.wantErr && err == nil {
+				t.Fatal("expected error")
+			} else if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(warnings) != tc.wantWarnings {
+				t.Errorf("expected %d warnings, got %v", tc.wantWarnings, warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+
+	promconfig "github.com/prometheus/prometheus/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+const (
+	// selfMonitoringJobPrefix is the scrape pool prefix of self-monitoring jobs. Like the
+	// kubelet jobs they are not backed by a resource, so no target status is reported.
+	selfMonitoringJobPrefix = "self-monitoring"
+	// nameSelfMonitoringRules is the name of the generated rule file and group with the
+	// self-monitoring alerts.
+	nameSelfMonitoringRules       = "gmp-self-monitoring"
+	defaultSelfMonitoringInterval = "30s"
+)
+
+// selfMonitoringEndpoints are the metric ports of each component, keyed by the value of its
+// app.kubernetes.io/name label.
+var selfMonitoringEndpoints = map[string][]string{
+	NameOperator:      {"metrics"},
+	NameCollector:     {CollectorPrometheusContainerPortName, CollectorConfigReloaderContainerPortName},
+	NameRuleEvaluator: {RuleEvaluatorContainerPortName, CollectorConfigReloaderContainerPortName},
+	NameAlertmanager:  {NameAlertmanager, CollectorConfigReloaderContainerPortName},
+}
+
+// selfMonitoringScrapeConfigs returns the scrape configs for all managed collection components
+// in the operator namespace.
+func selfMonitoringScrapeConfigs(namespace, projectID, location, cluster string, spec *monitoringv1.SelfMonitoringSpec) ([]*promconfig.ScrapeConfig, error) {
+	interval := spec.Interval
+	if interval == "" {
+		interval = defaultSelfMonitoringInterval
+	}
+	var res []*promconfig.ScrapeConfig
+	for _, name := range []string{NameOperator, NameCollector, NameRuleEvaluator, NameAlertmanager} {
+		pm := &monitoringv1.PodMonitoring{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: monitoringv1.PodMonitoringSpec{
+				Selector: metav1.LabelSelector{MatchLabels: map[string]string{LabelAppName: name}},
+			},
+		}
+		for _, port := range selfMonitoringEndpoints[name] {
+			pm.Spec.Endpoints = append(pm.Spec.Endpoints, monitoringv1.ScrapeEndpoint{
+				Port:     intstr.FromString(port),
+				Interval: interval,
+			})
+		}
+		cfgs, err := pm.ScrapeConfigs(projectID, location, cluster, monitoringv1.PrometheusSecretConfigs{})
+		if err != nil {
+			return nil, fmt.Errorf("self-monitoring scrape config for %s: %w", name, err)
+		}
+		for i, cfg := range cfgs {
+			cfg.JobName = fmt.Sprintf("%s/%s/%s", selfMonitoringJobPrefix, name, selfMonitoringEndpoints[name][i])
+		}
+		res = append(res, cfgs...)
+	}
+	return res, nil
+}
+
+// selfMonitoringRules returns the alerting rules for the managed collection components. They
+// are evaluated like ClusterRules, so they only consider data of this cluster.
+func selfMonitoringRules(namespace string) *monitoringv1.ClusterRules {
+	sel := fmt.Sprintf(`namespace=%q`, namespace)
+	return &monitoringv1.ClusterRules{
+		ObjectMeta: metav1.ObjectMeta{Name: nameSelfMonitoringRules},
+		Spec: monitoringv1.RulesSpec{
+			Groups: []monitoringv1.RuleGroup{{
+				Name:     nameSelfMonitoringRules,
+				Interval: "1m",
+				Rules: []monitoringv1.Rule{
+					{
+						Alert: "GMPConfigReloadFailed",
+						Expr:  fmt.Sprintf(`max by (job, instance) (reloader_last_reload_successful{%s}) == 0`, sel),
+						For:   "10m",
+						Labels: map[string]string{
+							"severity": "warning",
+						},
+						Annotations: map[string]string{
+							"summary":     "Managed collection component failed to reload its configuration",
+							"description": "The config-reloader of {{ $labels.instance }} ({{ $labels.job }}) failed to reload the configuration, the component runs with an outdated configuration.",
+						},
+					},
+					{
+						Alert: "GMPExportErrors",
+						Expr:  fmt.Sprintf(`sum by (job, instance) (rate(gcm_export_samples_send_errors_total{%s}[5m])) > 0`, sel),
+						For:   "15m",
+						Labels: map[string]string{
+							"severity": "warning",
+						},
+						Annotations: map[string]string{
+							"summary":     "Managed collection component fails to export samples",
+							"description": "{{ $labels.instance }} ({{ $labels.job }}) fails to send {{ $value }} samples per second to Cloud Monitoring.",
+						},
+					},
+					{
+						Alert: "GMPTargetStatusIncomplete",
+						Expr:  fmt.Sprintf(`min(prometheus_engine_target_status_collectors_fraction{%s}) < 1`, sel),
+						For:   "15m",
+						Labels: map[string]string{
+							"severity": "warning",
+						},
+						Annotations: map[string]string{
+							"summary":     "Target status is missing collectors",
+							"description": "Only a fraction of {{ $value }} of the collectors could be polled for target status.",
+						},
+					},
+				},
+			}},
+		},
+	}
+}
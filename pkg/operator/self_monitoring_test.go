@@ -0,0 +1,94 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+func TestSelfMonitoring(t *testing.T) {
+	opts := Options{
+		ProjectID:         "test-proj",
+		Location:          "us-central1-c",
+		Cluster:           "test-cluster",
+		OperatorNamespace: "gmp-system",
+		PublicNamespace:   "gmp-public",
+	}
+	c := newFakeClientBuilder().Build()
+
+	t.Run("scrape configs", func(t *testing.T) {
+		r := newCollectionReconciler(c, opts)
+
+		cfg, _, err := r.makeCollectorConfig(t.Context(), &monitoringv1.CollectionSpec{}, nil, nil, &monitoringv1.SelfMonitoringSpec{Enabled: true, Interval: "1m"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var jobs []string
+		for _, sc := range cfg.ScrapeConfigs {
+			if !strings.HasPrefix(sc.JobName, selfMonitoringJobPrefix+"/") {
+				continue
+			}
+			jobs = append(jobs, sc.JobName)
+			if got := sc.ScrapeInterval.String(); got != "1m" {
+				t.Errorf("job %q: expected interval 1m, got %s", sc.JobName, got)
+			}
+			// Target status skips the jobs instead of failing on them.
+			pool, err := parseScrapePool(sc.JobName)
+			if err != nil {
+				t.Fatalf("parse scrape pool: %s", err)
+			}
+			if obj, err := getObjectByScrapeJobKey(pool.key); err != nil || obj != nil {
+				t.Errorf("job %q: expected no object, got %v, %v", sc.JobName, obj, err)
+			}
+		}
+		if len(jobs) != 7 {
+			t.Errorf("expected 7 self-monitoring jobs, got %v", jobs)
+		}
+
+		cfg, _, err = r.makeCollectorConfig(t.Context(), &monitoringv1.CollectionSpec{}, nil, nil, &monitoringv1.SelfMonitoringSpec{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cfg.ScrapeConfigs) != 0 {
+			t.Errorf("expected no scrape configs when disabled, got %d", len(cfg.ScrapeConfigs))
+		}
+	})
+
+	t.Run("rules", func(t *testing.T) {
+		r := newRulesReconciler(c, opts)
+		if err := r.ensureRuleConfigs(t.Context(), opts.ProjectID, opts.Location, opts.Cluster, monitoringv1.CompressionNone, nil, true, 1, nil); err != nil {
+			t.Fatal(err)
+		}
+		var cm corev1.ConfigMap
+		if err := c.Get(t.Context(), client.ObjectKey{Namespace: opts.OperatorNamespace, Name: nameRulesGenerated}, &cm); err != nil {
+			t.Fatal(err)
+		}
+		rules, ok := cm.Data[nameSelfMonitoringRules+".yaml"]
+		if !ok {
+			t.Fatalf("self-monitoring rules missing in %v", cm.Data)
+		}
+		for _, want := range []string{"GMPConfigReloadFailed", "GMPExportErrors", "GMPTargetStatusIncomplete", `cluster="test-cluster"`} {
+			if !strings.Contains(rules, want) {
+				t.Errorf("expected %q in rules:\n%s", want, rules)
+			}
+		}
+	})
+}
@@ -0,0 +1,430 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	amlabels "github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+const (
+	// silenceCreatedByPrefix prefixes the creator of silences managed through Silence
+	// resources, followed by the namespace and name of the resource.
+	silenceCreatedByPrefix = NameOperator + "/"
+	// silencePollInterval is how often silences are compared with the Alertmanager state,
+	// which may be lost at any time.
+	silencePollInterval = time.Minute
+	// silenceDefaultDeleteAfter is how long ended Silences are kept if they do not set a
+	// retention, matching the default of the CRD.
+	silenceDefaultDeleteAfter = 24 * time.Hour
+
+	reasonSilenceCreated          = "SilenceCreated"
+	reasonSilenceActive           = "SilenceActive"
+	reasonSilenceEnded            = "SilenceEnded"
+	reasonSilenceInvalid          = "SilenceInvalid"
+	reasonAlertmanagerUnavailable = "AlertmanagerUnavailable"
+)
+
+func setupSilenceController(op *Operator) error {
+	// The managed Alertmanager is the request object we reconcile against.
+	objRequest := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: op.opts.OperatorNamespace,
+			Name:      NameAlertmanager,
+		},
+	}
+	objFilterAlertmanager := namespacedNamePredicate{
+		namespace: op.opts.OperatorNamespace,
+		name:      NameAlertmanager,
+	}
+
+	err := ctrl.NewControllerManagedBy(op.manager).
+		Named("silences").
+		// Restore silences right away when the Alertmanager is replaced.
+		For(
+			&appsv1.StatefulSet{},
+			builder.WithPredicates(objFilterAlertmanager),
+		).
+		Watches(
+			&monitoringv1.Silence{},
+			enqueueConst(objRequest),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Complete(newSilenceReconciler(op.manager.GetClient(), op.opts, op.opts.CollectorHTTPClient))
+	if err != nil {
+		return fmt.Errorf("create silences controller: %w", err)
+	}
+	return nil
+}
+
+// silenceReconciler keeps the silences of the managed Alertmanager in sync with the Silence
+// resources.
+type silenceReconciler struct {
+	client     client.Client
+	opts       Options
+	httpClient *http.Client
+	// alertmanagerURL returns the base URL of the Alertmanager API or an empty string if no
+	// Alertmanager is running.
+	alertmanagerURL func(ctx context.Context) (string, error)
+	now             func() time.Time
+}
+
+func newSilenceReconciler(c client.Client, opts Options, httpClient *http.Client) *silenceReconciler {
+	r := &silenceReconciler{
+		client:     c,
+		opts:       opts,
+		httpClient: httpClient,
+		now:        time.Now,
+	}
+	r.alertmanagerURL = r.alertmanagerPodURL
+	return r
+}
+
+func (r *silenceReconciler) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	logger, _ := logr.FromContext(ctx)
+	logger.Info("reconciling silences")
+
+	var silences monitoringv1.SilenceList
+	if err := r.client.List(ctx, &silences); err != nil {
+		return reconcile.Result{}, fmt.Errorf("list silences: %w", err)
+	}
+	baseURL, err := r.alertmanagerURL(ctx)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	var existing []alertmanagerSilence
+	if baseURL != "" {
+		if existing, err = r.listSilences(ctx, baseURL); err != nil {
+			logger.Error(err, "list Alertmanager silences")
+			baseURL = ""
+		}
+	}
+
+	now := r.now()
+	requeueAfter := silencePollInterval
+	managed := map[string]bool{}
+	var errs []error
+	for i := range silences.Items {
+		s := &silences.Items[i]
+		status := s.Status.DeepCopy()
+		if s.Spec.EndsAt.Time.After(now) {
+			requeueAfter = min(requeueAfter, s.Spec.EndsAt.Sub(now))
+			status.SetMonitoringCondition(s.Generation, metav1.NewTime(now), r.ensureSilenceCondition(ctx, s, status, baseURL, existing, managed, now))
+			status.SetMonitoringCondition(s.Generation, metav1.NewTime(now), &monitoringv1.MonitoringCondition{
+				Type:   monitoringv1.SilenceExpired,
+				Status: corev1.ConditionFalse,
+				Reason: reasonSilenceActive,
+			})
+		} else {
+			// The silence ended and the Alertmanager expires it by itself. The resource is
+			// kept for its retention so that it can still be extended.
+			cond := &monitoringv1.MonitoringCondition{
+				Type:    monitoringv1.SilenceExpired,
+				Status:  corev1.ConditionTrue,
+				Reason:  reasonSilenceEnded,
+				Message: fmt.Sprintf("silence ended at %s", s.Spec.EndsAt.Format(time.RFC3339)),
+			}
+			deleteAfter, err := silenceDeleteAfter(s)
+			if err != nil {
+				cond.Message = fmt.Sprintf("%s, not deleted: %s", cond.Message, err)
+			} else if deleteAt := s.Spec.EndsAt.Add(deleteAfter); !deleteAt.After(now) {
+				if err := r.client.Delete(ctx, s); err != nil && !apierrors.IsNotFound(err) {
+					errs = append(errs, fmt.Errorf("delete ended silence %s: %w", client.ObjectKeyFromObject(s), err))
+				}
+				continue
+			} else {
+				requeueAfter = min(requeueAfter, deleteAt.Sub(now))
+			}
+			status.SetMonitoringCondition(s.Generation, metav1.NewTime(now), cond)
+		}
+		if equality.Semantic.DeepEqual(status, &s.Status) {
+			continue
+		}
+		patch := client.MergeFrom(s.DeepCopy())
+		s.Status = *status
+		if err := r.client.Status().Patch(ctx, s, patch); err != nil {
+			errs = append(errs, fmt.Errorf("patch silence status %s: %w", client.ObjectKeyFromObject(s), err))
+		}
+	}
+
+	// Expire silences whose resources were deleted or which were replaced.
+	if baseURL != "" {
+		for _, sil := range existing {
+			if !strings.HasPrefix(sil.CreatedBy, silenceCreatedByPrefix) || sil.expired() || managed[sil.ID] {
+				continue
+			}
+			if err := r.expireSilence(ctx, baseURL, sil.ID); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// silenceDeleteAfter returns how long the Silence is kept after its end.
+func silenceDeleteAfter(s *monitoringv1.Silence) (time.Duration, error) {
+	if s.Spec.DeleteAfter == "" {
+		return silenceDefaultDeleteAfter, nil
+	}
+	d, err := model.ParseDuration(s.Spec.DeleteAfter)
+	if err != nil {
+		return 0, fmt.Errorf("invalid deleteAfter %q: %w", s.Spec.DeleteAfter, err)
+	}
+	return time.Duration(d), nil
+}
+
+// ensureSilenceCondition creates or updates the Alertmanager silence of an active Silence and
+// returns the resulting ConfigurationCreateSuccess condition.
+func (r *silenceReconciler) ensureSilenceCondition(ctx context.Context, s *monitoringv1.Silence, status *monitoringv1.SilenceStatus, baseURL string, existing []alertmanagerSilence, managed map[string]bool, now time.Time) *monitoringv1.MonitoringCondition {
+	logger, _ := logr.FromContext(ctx)
+
+	cond := &monitoringv1.MonitoringCondition{
+		Type:   monitoringv1.ConfigurationCreateSuccess,
+		Status: corev1.ConditionTrue,
+		Reason: reasonSilenceCreated,
+	}
+	desired, err := makeAlertmanagerSilence(s, now)
+	switch {
+	case err != nil:
+		cond.Status = corev1.ConditionFalse
+		cond.Reason = reasonSilenceInvalid
+		cond.Message = err.Error()
+	case baseURL == "":
+		cond.Status = corev1.ConditionFalse
+		cond.Reason = reasonAlertmanagerUnavailable
+		cond.Message = "no managed Alertmanager is running"
+	default:
+		id, err := r.ensureSilence(ctx, baseURL, desired, existing, managed, now)
+		if err != nil {
+			cond.Status = corev1.ConditionFalse
+			cond.Reason = reasonAlertmanagerUnavailable
+			cond.Message = err.Error()
+			logger.Error(err, "create Alertmanager silence", "namespace", s.Namespace, "name", s.Name)
+		} else {
+			status.SilenceID = id
+			managed[id] = true
+		}
+	}
+	return cond
+}
+
+// ensureSilence creates or updates the silence in the Alertmanager and returns its ID.
+// The existing silence is marked as managed even if the update fails so that it stays in
+// place until the next attempt.
+func (r *silenceReconciler) ensureSilence(ctx context.Context, baseURL string, desired *alertmanagerSilence, existing []alertmanagerSilence, managed map[string]bool, now time.Time) (string, error) {
+	for _, sil := range existing {
+		if sil.CreatedBy != desired.CreatedBy || sil.expired() {
+			continue
+		}
+		managed[sil.ID] = true
+		if sil.equal(desired, now) {
+			return sil.ID, nil
+		}
+		// Keep the start of active silences so the Alertmanager updates them in place
+		// instead of replacing them.
+		if !desired.StartsAt.After(now) && !sil.StartsAt.After(now) {
+			desired.StartsAt = sil.StartsAt
+		}
+		desired.ID = sil.ID
+		break
+	}
+	return r.postSilence(ctx, baseURL, desired)
+}
+
+// alertmanagerPodURL returns the URL of the first running Alertmanager pod.
+func (r *silenceReconciler) alertmanagerPodURL(ctx context.Context) (string, error) {
+	var pods corev1.PodList
+	if err := r.client.List(ctx, &pods,
+		client.InNamespace(r.opts.OperatorNamespace),
+		client.MatchingLabelsSelector{Selector: labels.SelectorFromSet(alertmanagerLabels())},
+	); err != nil {
+		return "", fmt.Errorf("list Alertmanager pods: %w", err)
+	}
+	slices.SortFunc(pods.Items, func(a, b corev1.Pod) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil || pod.Status.PodIP == "" {
+			continue
+		}
+		if port, ok := containerPort(pod, NameAlertmanager); ok {
+			return fmt.Sprintf("http://%s:%d", pod.Status.PodIP, port), nil //nolint:revive // Allow insecure http client
+		}
+	}
+	return "", nil
+}
+
+// alertmanagerSilence is a silence in the Alertmanager v2 API.
+type alertmanagerSilence struct {
+	ID        string                `json:"id,omitempty"`
+	Matchers  []alertmanagerMatcher `json:"matchers"`
+	StartsAt  time.Time             `json:"startsAt"`
+	EndsAt    time.Time             `json:"endsAt"`
+	CreatedBy string                `json:"createdBy"`
+	Comment   string                `json:"comment"`
+	Status    *struct {
+		State string `json:"state"`
+	} `json:"status,omitempty"`
+}
+
+type alertmanagerMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+}
+
+func (s *alertmanagerSilence) expired() bool {
+	return s.Status != nil && s.Status.State == "expired"
+}
+
+// equal returns whether the silence matches the desired one. Start times in the past are
+// not compared as the Alertmanager sets them to the current time on creation.
+func (s *alertmanagerSilence) equal(desired *alertmanagerSilence, now time.Time) bool {
+	if desired.StartsAt.After(now) && !s.StartsAt.Equal(desired.StartsAt) {
+		return false
+	}
+	return s.EndsAt.Truncate(time.Second).Equal(desired.EndsAt) &&
+		s.Comment == desired.Comment &&
+		slices.Equal(s.Matchers, desired.Matchers)
+}
+
+// makeAlertmanagerSilence returns the Alertmanager silence of the Silence resource.
+func makeAlertmanagerSilence(s *monitoringv1.Silence, now time.Time) (*alertmanagerSilence, error) {
+	res := &alertmanagerSilence{
+		Matchers: []alertmanagerMatcher{
+			{Name: "namespace", Value: s.Namespace, IsEqual: true},
+		},
+		StartsAt:  s.CreationTimestamp.Time,
+		EndsAt:    s.Spec.EndsAt.Time,
+		CreatedBy: fmt.Sprintf("%s%s/%s", silenceCreatedByPrefix, s.Namespace, s.Name),
+		Comment:   s.Spec.Comment,
+	}
+	if s.Spec.StartsAt != nil {
+		res.StartsAt = s.Spec.StartsAt.Time
+	}
+	if res.StartsAt.Before(now) {
+		res.StartsAt = now
+	}
+	if res.Comment == "" {
+		res.Comment = fmt.Sprintf("Managed by Silence %s/%s", s.Namespace, s.Name)
+	}
+	if !res.EndsAt.After(res.StartsAt) {
+		return nil, errors.New("end of the silence must be after its start")
+	}
+	if len(s.Spec.Matchers) == 0 {
+		return nil, errors.New("at least one matcher is required")
+	}
+	for _, m := range s.Spec.Matchers {
+		matcher, err := amlabels.ParseMatcher(m)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher %q: %w", m, err)
+		}
+		res.Matchers = append(res.Matchers, alertmanagerMatcher{
+			Name:    matcher.Name,
+			Value:   matcher.Value,
+			IsRegex: matcher.Type == amlabels.MatchRegexp || matcher.Type == amlabels.MatchNotRegexp,
+			IsEqual: matcher.Type == amlabels.MatchEqual || matcher.Type == amlabels.MatchRegexp,
+		})
+	}
+	return res, nil
+}
+
+func (r *silenceReconciler) listSilences(ctx context.Context, baseURL string) ([]alertmanagerSilence, error) {
+	var res []alertmanagerSilence
+	if err := r.do(ctx, http.MethodGet, baseURL+"/api/v2/silences", nil, &res); err != nil {
+		return nil, fmt.Errorf("list silences: %w", err)
+	}
+	return res, nil
+}
+
+func (r *silenceReconciler) postSilence(ctx context.Context, baseURL string, s *alertmanagerSilence) (string, error) {
+	var res struct {
+		SilenceID string `json:"silenceID"`
+	}
+	if err := r.do(ctx, http.MethodPost, baseURL+"/api/v2/silences", s, &res); err != nil {
+		return "", fmt.Errorf("post silence: %w", err)
+	}
+	return res.SilenceID, nil
+}
+
+func (r *silenceReconciler) expireSilence(ctx context.Context, baseURL, id string) error {
+	if err := r.do(ctx, http.MethodDelete, baseURL+"/api/v2/silence/"+id, nil, nil); err != nil {
+		return fmt.Errorf("expire silence %s: %w", id, err)
+	}
+	return nil
+}
+
+// do sends a request to the Alertmanager API and decodes the response into res, if set.
+func (r *silenceReconciler) do(ctx context.Context, method, url string, body, res any) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if res == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(res)
+}
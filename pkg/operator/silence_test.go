@@ -0,0 +1,304 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+// fakeAlertmanager is a minimal stand-in for the silences of the Alertmanager v2 API.
+type fakeAlertmanager struct {
+	mtx      sync.Mutex
+	nextID   int
+	silences map[string]*alertmanagerSilence
+	// failPost makes creating and updating silences fail.
+	failPost bool
+}
+
+func (am *fakeAlertmanager) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	am.mtx.Lock()
+	defer am.mtx.Unlock()
+
+	switch {
+	case req.Method == http.MethodGet && req.URL.Path == "/api/v2/silences":
+		res := []*alertmanagerSilence{}
+		for _, s := range am.silences {
+			res = append(res, s)
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	case req.Method == http.MethodPost && req.URL.Path == "/api/v2/silences":
+		if am.failPost {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		var s alertmanagerSilence
+		if err := json.NewDecoder(req.Body).Decode(&s); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if s.ID != "" {
+			if _, ok := am.silences[s.ID]; !ok {
+				http.Error(w, "silence not found", http.StatusNotFound)
+				return
+			}
+		} else {
+			am.nextID++
+			s.ID = fmt.Sprintf("silence-%d", am.nextID)
+		}
+		s.Status = &struct {
+			State string `json:"state"`
+		}{State: "active"}
+		am.silences[s.ID] = &s
+		_ = json.NewEncoder(w).Encode(map[string]string{"silenceID": s.ID})
+	case req.Method == http.MethodDelete && strings.HasPrefix(req.URL.Path, "/api/v2/silence/"):
+		s, ok := am.silences[strings.TrimPrefix(req.URL.Path, "/api/v2/silence/")]
+		if !ok {
+			http.Error(w, "silence not found", http.StatusNotFound)
+			return
+		}
+		s.Status.State = "expired"
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func TestSilenceReconcile(t *testing.T) {
+	opts := Options{OperatorNamespace: "gmp-system"}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	am := &fakeAlertmanager{silences: map[string]*alertmanagerSilence{}}
+	srv := httptest.NewServer(am)
+	defer srv.Close()
+
+	silence := &monitoringv1.Silence{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "ns1",
+			Name:              "maintenance",
+			Generation:        1,
+			CreationTimestamp: metav1.NewTime(now.Add(-time.Minute)),
+		},
+		Spec: monitoringv1.SilenceSpec{
+			Matchers: []string{`alertname="HighLatency"`, `instance=~"web-.*"`},
+			EndsAt:   metav1.NewTime(now.Add(time.Hour)),
+			Comment:  "planned maintenance",
+		},
+	}
+	invalid := &monitoringv1.Silence{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "invalid", Generation: 1},
+		Spec: monitoringv1.SilenceSpec{
+			Matchers: []string{`alertname=~"("`},
+			EndsAt:   metav1.NewTime(now.Add(time.Hour)),
+		},
+	}
+	expired := &monitoringv1.Silence{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "expired", Generation: 1},
+		Spec: monitoringv1.SilenceSpec{
+			Matchers: []string{`alertname="Foo"`},
+			EndsAt:   metav1.NewTime(now.Add(-time.Minute)),
+		},
+	}
+	retentionPassed := &monitoringv1.Silence{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "retention-passed", Generation: 1},
+		Spec: monitoringv1.SilenceSpec{
+			Matchers:    []string{`alertname="Foo"`},
+			EndsAt:      metav1.NewTime(now.Add(-2 * time.Minute)),
+			DeleteAfter: "1m",
+		},
+	}
+	c := newFakeClientBuilder().WithObjects(silence, invalid, expired, retentionPassed).Build()
+
+	r := newSilenceReconciler(c, opts, srv.Client())
+	r.now = func() time.Time { return now }
+	available := true
+	r.alertmanagerURL = func(context.Context) (string, error) {
+		if !available {
+			return "", nil
+		}
+		return srv.URL, nil
+	}
+
+	reconcileSilences := func() reconcile.Result {
+		t.Helper()
+		res, err := r.Reconcile(t.Context(), reconcile.Request{})
+		if err != nil {
+			t.Fatalf("reconcile: %s", err)
+		}
+		return res
+	}
+	getSilence := func(obj *monitoringv1.Silence) *monitoringv1.Silence {
+		t.Helper()
+		var got monitoringv1.Silence
+		if err := c.Get(t.Context(), client.ObjectKeyFromObject(obj), &got); err != nil {
+			t.Fatal(err)
+		}
+		return &got
+	}
+	expectTypedCondition := func(obj *monitoringv1.Silence, typ monitoringv1.MonitoringConditionType, status corev1.ConditionStatus, reason string) {
+		t.Helper()
+		for _, cond := range obj.Status.Conditions {
+			if cond.Type != typ {
+				continue
+			}
+			if cond.Status != status || cond.Reason != reason {
+				t.Errorf("silence %s: expected condition %s %s/%s, got %s/%s", client.ObjectKeyFromObject(obj), typ, status, reason, cond.Status, cond.Reason)
+			}
+			return
+		}
+		t.Errorf("silence %s: condition %s not found in %v", client.ObjectKeyFromObject(obj), typ, obj.Status.Conditions)
+	}
+	expectCondition := func(obj *monitoringv1.Silence, status corev1.ConditionStatus, reason string) {
+		t.Helper()
+		expectTypedCondition(obj, monitoringv1.ConfigurationCreateSuccess, status, reason)
+		expectTypedCondition(obj, monitoringv1.SilenceExpired, corev1.ConditionFalse, reasonSilenceActive)
+	}
+
+	if res := reconcileSilences(); res.RequeueAfter != silencePollInterval {
+		t.Errorf("expected requeue after %s, got %s", silencePollInterval, res.RequeueAfter)
+	}
+	// Ended silences are kept for their retention and reported as expired.
+	expectTypedCondition(getSilence(expired), monitoringv1.SilenceExpired, corev1.ConditionTrue, reasonSilenceEnded)
+	if err := c.Get(t.Context(), client.ObjectKeyFromObject(retentionPassed), &monitoringv1.Silence{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected silence past its retention to be deleted, got %v", err)
+	}
+	expectCondition(getSilence(invalid), corev1.ConditionFalse, reasonSilenceInvalid)
+
+	got := getSilence(silence)
+	expectCondition(got, corev1.ConditionTrue, reasonSilenceCreated)
+	if len(am.silences) != 1 {
+		t.Fatalf("expected 1 Alertmanager silence, got %d", len(am.silences))
+	}
+	id := got.Status.SilenceID
+	amSilence, ok := am.silences[id]
+	if !ok {
+		t.Fatalf("silence ID %q not found in Alertmanager", id)
+	}
+	wantMatchers := []alertmanagerMatcher{
+		{Name: "namespace", Value: "ns1", IsEqual: true},
+		{Name: "alertname", Value: "HighLatency", IsEqual: true},
+		{Name: "instance", Value: "web-.*", IsRegex: true, IsEqual: true},
+	}
+	if fmt.Sprint(amSilence.Matchers) != fmt.Sprint(wantMatchers) {
+		t.Errorf("expected matchers %v, got %v", wantMatchers, amSilence.Matchers)
+	}
+	if amSilence.CreatedBy != "gmp-operator/ns1/maintenance" || amSilence.Comment != "planned maintenance" {
+		t.Errorf("unexpected silence metadata: %+v", amSilence)
+	}
+
+	// Reconciling again keeps the silence as is.
+	reconcileSilences()
+	if len(am.silences) != 1 || getSilence(silence).Status.SilenceID != id {
+		t.Errorf("expected silence %s to be kept, got %v", id, am.silences)
+	}
+
+	// Extending the silence updates it in place.
+	got.Spec.EndsAt = metav1.NewTime(now.Add(2 * time.Hour))
+	if err := c.Update(t.Context(), got); err != nil {
+		t.Fatal(err)
+	}
+	reconcileSilences()
+	if len(am.silences) != 1 || !am.silences[id].EndsAt.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("expected silence %s to be updated, got %+v", id, am.silences[id])
+	}
+
+	// A failed update keeps the previous silence in place.
+	got = getSilence(silence)
+	got.Spec.EndsAt = metav1.NewTime(now.Add(3 * time.Hour))
+	got.Generation++
+	if err := c.Update(t.Context(), got); err != nil {
+		t.Fatal(err)
+	}
+	am.failPost = true
+	reconcileSilences()
+	am.failPost = false
+	if am.silences[id].expired() || !am.silences[id].EndsAt.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("expected silence %s to be kept, got %+v", id, am.silences[id])
+	}
+	expectCondition(getSilence(silence), corev1.ConditionFalse, reasonAlertmanagerUnavailable)
+	if got := getSilence(silence).Status.SilenceID; got != id {
+		t.Errorf("expected silence ID %s to be kept, got %s", id, got)
+	}
+	reconcileSilences()
+	expectCondition(getSilence(silence), corev1.ConditionTrue, reasonSilenceCreated)
+	if !am.silences[id].EndsAt.Equal(now.Add(3 * time.Hour)) {
+		t.Errorf("expected silence %s to be updated on retry, got %+v", id, am.silences[id])
+	}
+
+	// Extending an ended silence creates it again.
+	gotExpired := getSilence(expired)
+	gotExpired.Spec.EndsAt = metav1.NewTime(now.Add(time.Hour))
+	gotExpired.Generation++
+	if err := c.Update(t.Context(), gotExpired); err != nil {
+		t.Fatal(err)
+	}
+	reconcileSilences()
+	expectCondition(getSilence(expired), corev1.ConditionTrue, reasonSilenceCreated)
+	if len(am.silences) != 2 {
+		t.Errorf("expected 2 Alertmanager silences, got %d", len(am.silences))
+	}
+	if err := c.Delete(t.Context(), expired); err != nil {
+		t.Fatal(err)
+	}
+
+	// The silence is restored if the Alertmanager loses its state.
+	am.silences = map[string]*alertmanagerSilence{}
+	reconcileSilences()
+	if len(am.silences) != 1 {
+		t.Errorf("expected silence to be restored, got %v", am.silences)
+	}
+	id = getSilence(silence).Status.SilenceID
+
+	// Silences of deleted resources are expired.
+	if err := c.Delete(t.Context(), silence); err != nil {
+		t.Fatal(err)
+	}
+	reconcileSilences()
+	if !am.silences[id].expired() {
+		t.Errorf("expected silence %s to be expired", id)
+	}
+
+	// Without a running Alertmanager the status reports the silence as not created.
+	available = false
+	reconcileSilences()
+	expectCondition(getSilence(invalid), corev1.ConditionFalse, reasonSilenceInvalid)
+	if err := c.Create(t.Context(), &monitoringv1.Silence{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns3", Name: "pending"},
+		Spec: monitoringv1.SilenceSpec{
+			Matchers: []string{`alertname="Foo"`},
+			EndsAt:   metav1.NewTime(now.Add(time.Minute / 2)),
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if res := reconcileSilences(); res.RequeueAfter != time.Minute/2 {
+		t.Errorf("expected requeue at the end of the silence, got %s", res.RequeueAfter)
+	}
+	expectCondition(getSilence(&monitoringv1.Silence{ObjectMeta: metav1.ObjectMeta{Namespace: "ns3", Name: "pending"}}), corev1.ConditionFalse, reasonAlertmanagerUnavailable)
+}
@@ -52,6 +52,10 @@ var (
 		Name: "prometheus_engine_target_status_duration",
 		Help: "A metric indicating how long it took to fetch the complete target status.",
 	}, []string{})
+	targetStatusCollectorsFraction = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "prometheus_engine_target_status_collectors_fraction",
+		Help: "Fraction of collectors whose targets could be fetched in the last target status poll.",
+	})
 
 	// Minimum duration between polls.
 	minPollDuration = 10 * time.Second
@@ -115,19 +119,30 @@ func setupTargetStatusPoller(op *Operator, registry prometheus.Registerer, httpC
 		return fmt.Errorf("create target status controller: %w", err)
 	}
 
-	// Start the controller only once.
-	if err := op.manager.Add(manager.RunnableFunc(func(context.Context) error {
-		reconciler.ch <- event.GenericEvent{
-			Object: &appsv1.DaemonSet{},
-		}
-		return nil
-	})); err != nil {
+	// Start the controller only once. Like the controller, the runnable requires leader
+	// election, so only the leader polls targets when multiple replicas are running.
+	if err := op.manager.Add(reconciler.start(registry)); err != nil {
 		return fmt.Errorf("unable to start target status controller: %w", err)
 	}
 
 	return nil
 }
 
+// start returns a runnable that triggers the first poll. It also registers the metrics that
+// only the polling leader reports, as other replicas would report a zero fraction of polled
+// collectors.
+func (r *targetStatusReconciler) start(registry prometheus.Registerer) manager.RunnableFunc {
+	return func(context.Context) error {
+		if err := registry.Register(targetStatusCollectorsFraction); err != nil {
+			return err
+		}
+		r.ch <- event.GenericEvent{
+			Object: &appsv1.DaemonSet{},
+		}
+		return nil
+	}
+}
+
 // fetchAllPodMonitorings fetches all ClusterPodMonitoring and PodMonitoring CRs deployed in the cluster. This excludes ClusterNodeMonitoring CRs.
 func fetchAllPodMonitorings(ctx context.Context, kubeClient client.Client) ([]monitoringv1.PodMonitoringCRD, error) {
 	var combinedList []monitoringv1.PodMonitoringCRD
@@ -215,6 +230,15 @@ func pollAndUpdate(ctx context.Context, logger logr.Logger, opts Options, httpCl
 	if err != nil {
 		return err
 	}
+	if len(targets) > 0 {
+		var fetched int
+		for _, t := range targets {
+			if t != nil {
+				fetched++
+			}
+		}
+		targetStatusCollectorsFraction.Set(float64(fetched) / float64(len(targets)))
+	}
 
 	return updateTargetStatus(ctx, logger, kubeClient, targets, allPodMonitorings)
 }
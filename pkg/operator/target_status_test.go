@@ -30,6 +30,8 @@ import (
 	"github.com/go-logr/logr/testr"
 	"github.com/google/go-cmp/cmp"
 	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/model"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -1888,3 +1890,35 @@ func TestFetchTargets(t *testing.T) {
 		})
 	}
 }
+
+func TestTargetStatusCollectorsFractionLeaderOnly(t *testing.T) {
+	// Two operator replicas, of which only the leader starts polling.
+	leaderRegistry, followerRegistry := prometheus.NewRegistry(), prometheus.NewRegistry()
+	for _, registry := range []*prometheus.Registry{leaderRegistry, followerRegistry} {
+		if err := registry.Register(targetStatusDuration); err != nil {
+			t.Fatal(err)
+		}
+	}
+	leader := &targetStatusReconciler{ch: make(chan event.GenericEvent, 1)}
+	if err := leader.start(leaderRegistry)(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	targetStatusCollectorsFraction.Set(1)
+
+	exposesFraction := func(registry *prometheus.Registry) bool {
+		t.Helper()
+		families, err := registry.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return slices.ContainsFunc(families, func(mf *dto.MetricFamily) bool {
+			return mf.GetName() == "prometheus_engine_target_status_collectors_fraction"
+		})
+	}
+	if !exposesFraction(leaderRegistry) {
+		t.Error("expected leader to expose the collectors fraction")
+	}
+	if exposesFraction(followerRegistry) {
+		t.Error("expected follower not to expose the collectors fraction")
+	}
+}
@@ -34,23 +34,23 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 )
 
 // setupAdmissionWebhooks configures validating webhooks for the operator-managed
 // custom resources and registers handlers with the webhook server.
 func setupAdmissionWebhooks(ctx context.Context, logger logr.Logger, kubeClient client.Client, webhookServer *webhook.DefaultServer, opts *Options, vpaAvailable bool) error {
-	// Write provided cert files.
-	caBundle, err := ensureCerts(opts.OperatorNamespace, webhookServer.Options.CertDir, opts.TLSCert, opts.TLSKey, opts.CACert)
+	caBundle, caBundleUpdated, err := setupWebhookCerts(ctx, logger, kubeClient, webhookServer.Options.CertDir, opts)
 	if err != nil {
 		return err
 	}
 
 	name := webhookName(opts.OperatorNamespace)
 
-	if len(caBundle) > 0 {
+	if len(caBundle()) > 0 {
 		// Keep setting the caBundle, if "ensureCerts" gives us those, in the expected webhook configurations.
 		// In case of not enough permissions we will keep trying with error message.
-		go continuouslySetCABundle(ctx, logger, kubeClient, name, caBundle)
+		go continuouslySetCABundle(ctx, logger, kubeClient, name, opts.OperatorNamespace, caBundle, caBundleUpdated)
 	}
 	scheme := kubeClient.Scheme()
 
@@ -63,17 +63,31 @@ func setupAdmissionWebhooks(ctx context.Context, logger logr.Logger, kubeClient
 			VPAAvailable: vpaAvailable,
 		}),
 	)
+	scrapeValidator := newScrapeValidator(kubeClient, opts)
+	webhookServer.Register(
+		validatePath(monitoringv1.PodMonitoringResource()),
+		admission.WithCustomValidator(scheme, &monitoringv1.PodMonitoring{}, scrapeValidator),
+	)
+	webhookServer.Register(
+		validatePath(monitoringv1.ClusterPodMonitoringResource()),
+		admission.WithCustomValidator(scheme, &monitoringv1.ClusterPodMonitoring{}, scrapeValidator),
+	)
+	webhookServer.Register(
+		validatePath(monitoringv1.ClusterNodeMonitoringResource()),
+		admission.WithCustomValidator(scheme, &monitoringv1.ClusterNodeMonitoring{}, scrapeValidator),
+	)
+	rulesValidator := newRulesValidator(kubeClient, opts)
 	webhookServer.Register(
 		validatePath(monitoringv1.RulesResource()),
-		admission.WithCustomValidator(scheme, &monitoringv1.Rules{}, monitoringv1.NewRulesValidator()),
+		admission.WithCustomValidator(scheme, &monitoringv1.Rules{}, rulesValidator),
 	)
 	webhookServer.Register(
 		validatePath(monitoringv1.ClusterRulesResource()),
-		admission.WithCustomValidator(scheme, &monitoringv1.ClusterRules{}, monitoringv1.NewClusterRulesValidator()),
+		admission.WithCustomValidator(scheme, &monitoringv1.ClusterRules{}, rulesValidator),
 	)
 	webhookServer.Register(
 		validatePath(monitoringv1.GlobalRulesResource()),
-		admission.WithCustomValidator(scheme, &monitoringv1.GlobalRules{}, monitoringv1.NewGlobalRulesValidator()),
+		admission.WithCustomValidator(scheme, &monitoringv1.GlobalRules{}, rulesValidator),
 	)
 	// Defaulting webhooks.
 	webhookServer.Register(
@@ -84,6 +98,8 @@ func setupAdmissionWebhooks(ctx context.Context, logger logr.Logger, kubeClient
 			cluster:   opts.Cluster,
 		}),
 	)
+	// Conversion webhook between v1alpha1 and v1.
+	webhookServer.Register(convertPath, conversion.NewWebhookHandler(scheme))
 	return nil
 }
 
@@ -91,6 +107,28 @@ func webhookName(namespace string) string {
 	return fmt.Sprintf("%s.%s.monitoring.googleapis.com", NameOperator, namespace)
 }
 
+// setupWebhookCerts writes the cert/key files of the webhook server to the directory and
+// returns a function returning the current CA bundle along with a channel that is notified
+// when it changes.
+//
+// If no certificates are provided, they are generated and rotated by a webhookCertManager. All
+// operator replicas must serve the same certificate, so an inaccessible Secret fails the setup.
+func setupWebhookCerts(ctx context.Context, logger logr.Logger, kubeClient client.Client, dir string, opts *Options) (func() []byte, <-chan struct{}, error) {
+	if opts.TLSCert == "" && opts.TLSKey == "" && opts.CACert == "" {
+		m := newWebhookCertManager(logger, kubeClient, opts.OperatorNamespace, dir)
+		if err := m.Sync(ctx); err != nil {
+			return nil, nil, fmt.Errorf("sync webhook certificates: %w", err)
+		}
+		go m.Run(ctx)
+		return m.CABundle, m.updated, nil
+	}
+	caBundle, err := ensureCerts(opts.OperatorNamespace, dir, opts.TLSCert, opts.TLSKey, opts.CACert)
+	if err != nil {
+		return nil, nil, err
+	}
+	return func() []byte { return caBundle }, nil, nil
+}
+
 // ensureCerts writes the cert/key files to the specified directory.
 // If cert/key are not available, generate them.
 func ensureCerts(operatorNamespace, dir, certEncoded, keyEncoded, caCertEncoded string) ([]byte, error) {
@@ -116,8 +154,6 @@ func ensureCerts(operatorNamespace, dir, certEncoded, keyEncoded, caCertEncoded
 	} else if keyEncoded == "" && certEncoded == "" && caCertEncoded == "" {
 		// Generate a self-signed pair if none was explicitly provided. It will be valid
 		// for 1 year.
-		// TODO(freinartz): re-generate at runtime and update the ValidatingWebhookConfiguration
-		// at runtime whenever the files change.
 		fqdn := fmt.Sprintf("%s.%s.svc", NameOperator, operatorNamespace)
 
 		crt, key, err = cert.GenerateSelfSignedCertKey(fqdn, nil, nil)
@@ -188,21 +224,28 @@ func setMutatingWebhookCABundle(ctx context.Context, kubeClient client.Client, n
 	return kubeClient.Update(ctx, &mwc)
 }
 
-func continuouslySetCABundle(ctx context.Context, logger logr.Logger, kubeClient client.Client, name string, caBundle []byte) {
+// continuouslySetCABundle keeps setting the CA bundle in the webhook configurations and the
+// conversion webhooks of the CRDs. It is set right away when notified through the updated
+// channel, which may be nil.
+func continuouslySetCABundle(ctx context.Context, logger logr.Logger, kubeClient client.Client, name, namespace string, caBundle func() []byte, updated <-chan struct{}) {
 	// Initial sleep for the client to initialize before our first calls.
 	// Ideally we could explicitly wait for it.
 	time.Sleep(5 * time.Second)
 
 	for {
-		if err := setValidatingWebhookCABundle(ctx, kubeClient, name, caBundle); err != nil {
+		if err := setValidatingWebhookCABundle(ctx, kubeClient, name, caBundle()); err != nil {
 			logger.Error(err, "Setting CA bundle for ValidatingWebhookConfiguration failed; retrying in 1m...")
 		}
-		if err := setMutatingWebhookCABundle(ctx, kubeClient, name, caBundle); err != nil {
+		if err := setMutatingWebhookCABundle(ctx, kubeClient, name, caBundle()); err != nil {
 			logger.Error(err, "Setting CA bundle for MutatingWebhookConfiguration failed; retrying in 1m...")
 		}
+		if err := setConversionWebhookCABundle(ctx, kubeClient, namespace, caBundle()); err != nil {
+			logger.Error(err, "Setting CA bundle for CRD conversion webhooks failed; retrying in 1m...")
+		}
 		select {
 		case <-ctx.Done():
 			return
+		case <-updated:
 		case <-time.After(time.Minute):
 		}
 	}
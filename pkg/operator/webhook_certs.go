@@ -0,0 +1,334 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// nameWebhookCertsSecret is the Secret in the operator namespace holding the generated
+	// webhook certificates.
+	nameWebhookCertsSecret = "webhook-tls"
+
+	webhookCAValidity = 10 * 365 * 24 * time.Hour
+	// The CA is renewed once less than this remains, so that serving certificates never
+	// outlive it.
+	webhookCARenewBefore   = 2 * 365 * 24 * time.Hour
+	webhookCertValidity    = 365 * 24 * time.Hour
+	webhookCertRenewBefore = 90 * 24 * time.Hour
+	// webhookCAPropagationDelay is the minimum age of a renewed CA before serving certificates
+	// are issued by it, so that the API server trusts it by the time they are served.
+	webhookCAPropagationDelay = 5 * time.Minute
+	webhookCertsSyncInterval  = 10 * time.Minute
+	// Certificates are valid from slightly before their creation to tolerate clock skew.
+	webhookCertBackdate = time.Hour
+
+	keyWebhookCACert         = "ca.crt"
+	keyWebhookCAKey          = "ca.key"
+	keyWebhookPreviousCACert = "ca-previous.crt"
+	keyWebhookCert           = "tls.crt"
+	keyWebhookKey            = "tls.key"
+)
+
+var webhookCertExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "prometheus_engine_webhook_certificate_expiry_timestamp_seconds",
+	Help: "Expiry time of the certificates generated for the webhook server, by certificate.",
+}, []string{"certificate"})
+
+func registerWebhookMetrics(registry prometheus.Registerer) error {
+	return registry.Register(webhookCertExpiry)
+}
+
+// webhookCertManager maintains a CA and a serving certificate issued by it for the webhook
+// server. Both are stored in a Secret, so that they are shared across operator replicas and
+// restarts, and are renewed well before they expire.
+//
+// The serving certificate is written to the certificate directory of the webhook server,
+// which reloads it on change.
+type webhookCertManager struct {
+	logger    logr.Logger
+	client    client.Client
+	namespace string
+	dir       string
+	now       func() time.Time
+
+	mtx      sync.Mutex
+	caBundle []byte
+	// updated is notified when the CA bundle changes.
+	updated chan struct{}
+}
+
+func newWebhookCertManager(logger logr.Logger, c client.Client, namespace, dir string) *webhookCertManager {
+	return &webhookCertManager{
+		logger:    logger,
+		client:    c,
+		namespace: namespace,
+		dir:       dir,
+		now:       time.Now,
+		updated:   make(chan struct{}, 1),
+	}
+}
+
+// CABundle returns the CA certificates the API server must trust to call the webhooks.
+func (m *webhookCertManager) CABundle() []byte {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.caBundle
+}
+
+// Run periodically renews the certificates until the context is canceled.
+func (m *webhookCertManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(webhookCertsSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Sync(ctx); err != nil {
+				m.logger.Error(err, "syncing webhook certificates failed; retrying...")
+			}
+		}
+	}
+}
+
+// Sync renews the certificates in the Secret as needed and writes the serving certificate.
+// Conflicting updates by other operator replicas are retried.
+func (m *webhookCertManager) Sync(ctx context.Context) error {
+	var err error
+	for range 3 {
+		if err = m.sync(ctx); !apierrors.IsConflict(err) && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func (m *webhookCertManager) sync(ctx context.Context) error {
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: m.namespace,
+			Name:      nameWebhookCertsSecret,
+		},
+	}
+	exists := true
+	if err := m.client.Get(ctx, client.ObjectKeyFromObject(&secret), &secret); apierrors.IsNotFound(err) {
+		exists = false
+	} else if err != nil {
+		return fmt.Errorf("get webhook certificates secret: %w", err)
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	now := m.now()
+
+	changed, err := m.renew(secret.Data, now)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if err := m.client.Create(ctx, &secret); err != nil {
+			return fmt.Errorf("create webhook certificates secret: %w", err)
+		}
+	} else if changed {
+		if err := m.client.Update(ctx, &secret); err != nil {
+			return fmt.Errorf("update webhook certificates secret: %w", err)
+		}
+	}
+
+	if err := writeFileAtomic(filepath.Join(m.dir, "tls.crt"), secret.Data[keyWebhookCert], 0644); err != nil {
+		return fmt.Errorf("write cert file: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(m.dir, "tls.key"), secret.Data[keyWebhookKey], 0600); err != nil {
+		return fmt.Errorf("write key file: %w", err)
+	}
+
+	caBundle := secret.Data[keyWebhookCACert]
+	if prev, err := parseCertPEM(secret.Data[keyWebhookPreviousCACert]); err == nil && now.Before(prev.NotAfter) {
+		caBundle = append(bytes.Clone(caBundle), secret.Data[keyWebhookPreviousCACert]...)
+	}
+	m.mtx.Lock()
+	if !bytes.Equal(m.caBundle, caBundle) {
+		m.caBundle = caBundle
+		select {
+		case m.updated <- struct{}{}:
+		default:
+		}
+	}
+	m.mtx.Unlock()
+	return nil
+}
+
+// renew replaces certificates in the data that are missing, invalid or about to expire and
+// returns whether the data changed.
+//
+// A renewed CA is added to the CA bundle before serving certificates are issued by it. The
+// previous CA stays in the bundle, so that the serving certificate issued by it stays trusted.
+func (m *webhookCertManager) renew(data map[string][]byte, now time.Time) (bool, error) {
+	changed := false
+
+	ca, caErr := parseCertPEM(data[keyWebhookCACert])
+	caKey, caKeyErr := parseKeyPEM(data[keyWebhookCAKey])
+	if caErr != nil || caKeyErr != nil || now.After(ca.NotAfter.Add(-webhookCARenewBefore)) {
+		if caErr == nil && now.Before(ca.NotAfter) {
+			data[keyWebhookPreviousCACert] = data[keyWebhookCACert]
+		} else {
+			delete(data, keyWebhookPreviousCACert)
+		}
+		certPEM, keyPEM, err := generateWebhookCA(now)
+		if err != nil {
+			return false, fmt.Errorf("generate webhook CA: %w", err)
+		}
+		data[keyWebhookCACert], data[keyWebhookCAKey] = certPEM, keyPEM
+		if ca, err = parseCertPEM(certPEM); err != nil {
+			return false, err
+		}
+		if caKey, err = parseKeyPEM(keyPEM); err != nil {
+			return false, err
+		}
+		m.logger.Info("generated webhook CA", "expiry", ca.NotAfter)
+		changed = true
+	}
+	webhookCertExpiry.WithLabelValues("ca").Set(float64(ca.NotAfter.Unix()))
+
+	cert, certErr := parseCertPEM(data[keyWebhookCert])
+	_, keyErr := parseKeyPEM(data[keyWebhookKey])
+	caPropagated := now.Sub(ca.NotBefore.Add(webhookCertBackdate)) >= webhookCAPropagationDelay
+	switch {
+	case certErr != nil || keyErr != nil || now.After(cert.NotAfter):
+		// There is no serving certificate to fall back to.
+	case cert.CheckSignatureFrom(ca) != nil:
+		if !caPropagated {
+			webhookCertExpiry.WithLabelValues("serving").Set(float64(cert.NotAfter.Unix()))
+			return changed, nil
+		}
+	case now.After(cert.NotAfter.Add(-webhookCertRenewBefore)):
+	default:
+		webhookCertExpiry.WithLabelValues("serving").Set(float64(cert.NotAfter.Unix()))
+		return changed, nil
+	}
+	certPEM, keyPEM, err := generateWebhookCert(now, m.namespace, ca, caKey)
+	if err != nil {
+		return false, fmt.Errorf("generate webhook certificate: %w", err)
+	}
+	data[keyWebhookCert], data[keyWebhookKey] = certPEM, keyPEM
+	if cert, err = parseCertPEM(certPEM); err != nil {
+		return false, err
+	}
+	m.logger.Info("generated webhook serving certificate", "expiry", cert.NotAfter)
+	webhookCertExpiry.WithLabelValues("serving").Set(float64(cert.NotAfter.Unix()))
+	return true, nil
+}
+
+func generateWebhookCA(now time.Time) ([]byte, []byte, error) {
+	tmpl := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("%s-ca@%d", NameOperator, now.Unix())},
+		NotBefore:             now.Add(-webhookCertBackdate),
+		NotAfter:              now.Add(webhookCAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return generateCert(tmpl, nil, nil)
+}
+
+func generateWebhookCert(now time.Time, namespace string, ca *x509.Certificate, caKey *ecdsa.PrivateKey) ([]byte, []byte, error) {
+	fqdn := fmt.Sprintf("%s.%s.svc", NameOperator, namespace)
+	tmpl := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: fqdn},
+		DNSNames:    []string{fqdn},
+		NotBefore:   now.Add(-webhookCertBackdate),
+		NotAfter:    now.Add(webhookCertValidity),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	return generateCert(tmpl, ca, caKey)
+}
+
+// generateCert generates a key and a certificate for it from the template. The certificate is
+// self-signed if no parent is given.
+func generateCert(tmpl, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl.SerialNumber, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+func parseCertPEM(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func parseKeyPEM(data []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "EC PRIVATE KEY" {
+		return nil, errors.New("no PEM encoded EC private key found")
+	}
+	return x509.ParseECPrivateKey(block.Bytes)
+}
+
+// writeFileAtomic writes the file through a rename, so that the webhook server never reads
+// a partially written file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp := path + ".tmp"
+	if err := writeFile(tmp, data, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/testr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestWebhookCertManager(t *testing.T) {
+	c := newFakeClientBuilder().Build()
+	dir := t.TempDir()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := newWebhookCertManager(testr.New(t), c, "gmp-system", dir)
+	m.now = func() time.Time { return now }
+
+	sync := func() (secret corev1.Secret, serving *x509.Certificate) {
+		t.Helper()
+		if err := m.Sync(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Get(t.Context(), client.ObjectKey{Namespace: "gmp-system", Name: nameWebhookCertsSecret}, &secret); err != nil {
+			t.Fatal(err)
+		}
+		// The written files must form a valid key pair matching the Secret.
+		certPEM, err := os.ReadFile(filepath.Join(dir, "tls.crt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyPEM, err := os.ReadFile(filepath.Join(dir, "tls.key"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+			t.Fatalf("invalid key pair: %s", err)
+		}
+		if !bytes.Equal(certPEM, secret.Data[keyWebhookCert]) {
+			t.Error("written certificate does not match Secret")
+		}
+		serving, err = parseCertPEM(certPEM)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return secret, serving
+	}
+	// verify checks that the serving certificate is trusted by the current CA bundle.
+	verify := func(serving *x509.Certificate) {
+		t.Helper()
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(m.CABundle()) {
+			t.Fatal("invalid CA bundle")
+		}
+		_, err := serving.Verify(x509.VerifyOptions{
+			DNSName:     "gmp-operator.gmp-system.svc",
+			Roots:       pool,
+			CurrentTime: now,
+		})
+		if err != nil {
+			t.Errorf("serving certificate not trusted: %s", err)
+		}
+	}
+	notified := func() bool {
+		select {
+		case <-m.updated:
+			return true
+		default:
+			return false
+		}
+	}
+
+	secret, serving := sync()
+	verify(serving)
+	if !notified() {
+		t.Error("expected CA bundle update notification")
+	}
+	initialCA := secret.Data[keyWebhookCACert]
+
+	// Nothing is renewed before it is due.
+	now = now.Add(24 * time.Hour)
+	secret, renewed := sync()
+	if !renewed.Equal(serving) {
+		t.Error("unexpected renewal of serving certificate")
+	}
+	if notified() {
+		t.Error("unexpected CA bundle update notification")
+	}
+
+	// The serving certificate is renewed well before it expires.
+	now = serving.NotAfter.Add(-webhookCertRenewBefore + time.Hour)
+	secret, renewed = sync()
+	if renewed.Equal(serving) {
+		t.Error("expected renewal of serving certificate")
+	}
+	if !bytes.Equal(initialCA, secret.Data[keyWebhookCACert]) {
+		t.Error("unexpected renewal of CA")
+	}
+	verify(renewed)
+	serving = renewed
+
+	// When the CA is renewed, the previous CA stays trusted and keeps issuing the serving
+	// certificate until the new CA is propagated.
+	ca, err := parseCertPEM(initialCA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now = ca.NotAfter.Add(-webhookCARenewBefore - time.Hour)
+	_, serving = sync()
+	now = now.Add(2 * time.Hour)
+	secret, renewed = sync()
+	if bytes.Equal(initialCA, secret.Data[keyWebhookCACert]) {
+		t.Error("expected renewal of CA")
+	}
+	if !bytes.Equal(initialCA, secret.Data[keyWebhookPreviousCACert]) {
+		t.Error("expected previous CA to be kept")
+	}
+	if !notified() {
+		t.Error("expected CA bundle update notification")
+	}
+	if !renewed.Equal(serving) {
+		t.Error("unexpected renewal of serving certificate before CA propagation")
+	}
+	verify(renewed)
+
+	now = now.Add(webhookCAPropagationDelay)
+	_, renewed = sync()
+	if renewed.Equal(serving) {
+		t.Error("expected serving certificate issued by the renewed CA")
+	}
+	verify(renewed)
+}
+
+func TestWebhookCertManagerSharedSecret(t *testing.T) {
+	c := newFakeClientBuilder().Build()
+	m1 := newWebhookCertManager(testr.New(t), c, "gmp-system", t.TempDir())
+	m2 := newWebhookCertManager(testr.New(t), c, "gmp-system", t.TempDir())
+
+	if err := m1.Sync(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if err := m2.Sync(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	// Replicas share the certificates of the Secret.
+	if !bytes.Equal(m1.CABundle(), m2.CABundle()) {
+		t.Error("expected replicas to use the same CA bundle")
+	}
+}
+
+func TestSetupWebhookCertsSecretInaccessible(t *testing.T) {
+	c := newFakeClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(context.Context, client.WithWatch, client.ObjectKey, client.Object, ...client.GetOption) error {
+			return apierrors.NewForbidden(corev1.Resource("secrets"), nameWebhookCertsSecret, errors.New("forbidden"))
+		},
+	}).Build()
+	dir := t.TempDir()
+
+	if _, _, err := setupWebhookCerts(t.Context(), testr.New(t), c, dir, &Options{OperatorNamespace: "gmp-system"}); err == nil {
+		t.Fatal("expected error")
+	}
+	// No certificate must be served that other replicas don't share.
+	if _, err := os.Stat(filepath.Join(dir, "tls.crt")); !os.IsNotExist(err) {
+		t.Errorf("expected no certificate file, got %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+// fieldManagerWorkloads is the field manager of the workload overrides applied by the operator.
+const fieldManagerWorkloads = "gmp-operator"
+
+// applyWorkloadOverrides applies the overrides to the pod template of the given workload with
+// server-side apply. The workload must be as currently deployed, including its managed fields.
+// A nil spec applies an empty configuration, which releases the fields the operator applied
+// before so that they revert. Nothing is applied if the operator doesn't own any fields.
+func applyWorkloadOverrides(ctx context.Context, c client.Client, workload client.Object, template *corev1.PodTemplateSpec, container string, spec *monitoringv1.WorkloadSpec) error {
+	owned, err := ownedFields(workload, fieldManagerWorkloads)
+	if err != nil {
+		return err
+	}
+	if spec == nil && owned.Empty() {
+		return nil
+	}
+	gvk, err := apiutil.GVKForObject(workload, c.Scheme())
+	if err != nil {
+		return err
+	}
+	config := map[string]any{
+		"apiVersion": gvk.GroupVersion().String(),
+		"kind":       gvk.Kind,
+		"metadata": map[string]any{
+			"namespace": workload.GetNamespace(),
+			"name":      workload.GetName(),
+		},
+	}
+	if spec != nil {
+		podSpec, err := podSpecOverrides(&template.Spec, container, spec, owned)
+		if err != nil {
+			return err
+		}
+		config["spec"] = map[string]any{
+			"template": map[string]any{
+				"spec": podSpec,
+			},
+		}
+	}
+	patch, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err := c.Patch(ctx, workload, client.RawPatch(types.ApplyPatchType, patch), client.FieldOwner(fieldManagerWorkloads), client.ForceOwnership); err != nil {
+		return fmt.Errorf("apply overrides to %s %s: %w", gvk.Kind, workload.GetName(), err)
+	}
+	return nil
+}
+
+// podSpecOverrides returns the pod spec fields to apply for the overrides.
+//
+// Fields the operator applied before but that are no longer overridden are applied with their
+// current value. Omitting them would remove them from the workload, as the operator forcibly
+// took over their ownership from the manifests.
+func podSpecOverrides(current *corev1.PodSpec, container string, spec *monitoringv1.WorkloadSpec, owned *fieldpath.Set) (map[string]any, error) {
+	i := slices.IndexFunc(current.Containers, func(c corev1.Container) bool {
+		return c.Name == container
+	})
+	if i < 0 {
+		return nil, fmt.Errorf("container %q not found", container)
+	}
+	currentContainer := &current.Containers[i]
+	podPath := []any{"spec", "template", "spec"}
+	containerPath := append(slices.Clone(podPath), "containers", fieldpath.KeyByFields("name", container))
+
+	podSpec := map[string]any{}
+	switch {
+	case spec.Tolerations != nil:
+		podSpec["tolerations"] = spec.Tolerations
+	case ownsPath(owned, podPath, "tolerations"):
+		podSpec["tolerations"] = current.Tolerations
+	}
+	switch {
+	case spec.Affinity != nil:
+		podSpec["affinity"] = spec.Affinity
+	case ownsPath(owned, podPath, "affinity"):
+		podSpec["affinity"] = current.Affinity
+	}
+	switch {
+	case spec.PriorityClassName != "":
+		podSpec["priorityClassName"] = spec.PriorityClassName
+	case ownsPath(owned, podPath, "priorityClassName"):
+		podSpec["priorityClassName"] = current.PriorityClassName
+	}
+
+	containerSpec := map[string]any{"name": container}
+	switch {
+	case spec.Resources != nil:
+		containerSpec["resources"] = spec.Resources
+	case ownsPath(owned, containerPath, "resources"):
+		containerSpec["resources"] = currentContainer.Resources
+	}
+	switch {
+	case len(spec.ExtraArgs) > 0:
+		containerSpec["args"] = mergeArgs(currentContainer.Args, spec.ExtraArgs)
+	case ownsPath(owned, containerPath, "args"):
+		containerSpec["args"] = currentContainer.Args
+	}
+	// Environment variables are merged by name, so ones that are removed from the overrides
+	// are removed from the container.
+	if len(spec.Env) > 0 {
+		containerSpec["env"] = spec.Env
+	}
+	podSpec["containers"] = []any{containerSpec}
+
+	return podSpec, nil
+}
+
+// mergeArgs appends the extra arguments to the arguments. Flags replace all arguments of the
+// same flag, so that merging is idempotent.
+func mergeArgs(args, extra []string) []string {
+	flagName := func(arg string) string {
+		if !strings.HasPrefix(arg, "-") {
+			return ""
+		}
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		return name
+	}
+	res := slices.DeleteFunc(slices.Clone(args), func(arg string) bool {
+		return slices.ContainsFunc(extra, func(e string) bool {
+			name := flagName(e)
+			return arg == e || (name != "" && flagName(arg) == name)
+		})
+	})
+	return append(res, extra...)
+}
+
+// ownedFields returns the fields of the object that are managed by the field manager with
+// server-side apply.
+func ownedFields(obj client.Object, manager string) (*fieldpath.Set, error) {
+	set := &fieldpath.Set{}
+	for _, mf := range obj.GetManagedFields() {
+		if mf.Manager != manager || mf.FieldsV1 == nil {
+			continue
+		}
+		var s fieldpath.Set
+		if err := s.FromJSON(bytes.NewReader(mf.FieldsV1.Raw)); err != nil {
+			return nil, fmt.Errorf("parse managed fields: %w", err)
+		}
+		set = set.Union(&s)
+	}
+	return set, nil
+}
+
+// ownsPath returns whether the set contains the field at the path or any of its children.
+func ownsPath(set *fieldpath.Set, parent []any, field string) bool {
+	prefix, err := fieldpath.MakePath(append(slices.Clone(parent), field)...)
+	if err != nil {
+		return false
+	}
+	var found bool
+	set.Iterate(func(p fieldpath.Path) {
+		if len(p) >= len(prefix) && p[:len(prefix)].Equals(prefix) {
+			found = true
+		}
+	})
+	return found
+}
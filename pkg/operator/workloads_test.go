@@ -0,0 +1,244 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	monitoringv1 "github.com/GoogleCloudPlatform/prometheus-engine/pkg/operator/apis/monitoring/v1"
+)
+
+func TestMergeArgs(t *testing.T) {
+	args := []string{"--config.file=/prometheus/config.yaml", "--log.level=info", "--web.enable-lifecycle"}
+	extra := []string{"--log.level=debug", "--storage.tsdb.retention.time=1h"}
+
+	want := []string{
+		"--config.file=/prometheus/config.yaml",
+		"--web.enable-lifecycle",
+		"--log.level=debug",
+		"--storage.tsdb.retention.time=1h",
+	}
+	got := mergeArgs(args, extra)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected args (-want, +got): %s", diff)
+	}
+	// Merging again must not duplicate the extra arguments.
+	if diff := cmp.Diff(want, mergeArgs(got, extra)); diff != "" {
+		t.Errorf("merging is not idempotent (-want, +got): %s", diff)
+	}
+}
+
+func TestPodSpecOverrides(t *testing.T) {
+	current := &corev1.PodSpec{
+		PriorityClassName: "gmp-critical",
+		Tolerations:       []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+		Containers: []corev1.Container{{
+			Name: "evaluator",
+			Args: []string{"--log.level=debug"},
+		}},
+	}
+	// The operator previously applied the priority class and args.
+	owned, err := ownedFields(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{
+					Manager:    fieldManagerWorkloads,
+					Operation:  metav1.ManagedFieldsOperationApply,
+					FieldsType: "FieldsV1",
+					FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:template":{"f:spec":{
+						"f:priorityClassName":{},
+						"f:containers":{"k:{\"name\":\"evaluator\"}":{".":{},"f:name":{},"f:args":{}}}}}}}`)},
+				},
+				{
+					Manager:    "kubectl",
+					Operation:  metav1.ManagedFieldsOperationApply,
+					FieldsType: "FieldsV1",
+					FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:template":{"f:spec":{"f:tolerations":{}}}}}`)},
+				},
+			},
+		},
+	}, fieldManagerWorkloads)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources := &corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+
+	got, err := podSpecOverrides(current, "evaluator", &monitoringv1.WorkloadSpec{Resources: resources}, owned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"priorityClassName": "gmp-critical",
+		"containers": []any{map[string]any{
+			"name":      "evaluator",
+			"resources": resources,
+			"args":      []string{"--log.level=debug"},
+		}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected pod spec (-want, +got): %s", diff)
+	}
+
+	if _, err := podSpecOverrides(current, "missing", &monitoringv1.WorkloadSpec{}, owned); err == nil {
+		t.Error("expected error for missing container")
+	}
+}
+
+func TestApplyWorkloadOverrides(t *testing.T) {
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "gmp-system", Name: NameRuleEvaluator},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: RuleEvaluatorContainerName}},
+				},
+			},
+		},
+	}
+	var patches []client.Patch
+	c := newFakeClientBuilder().
+		WithObjects(deploy).
+		WithInterceptorFuncs(interceptor.Funcs{
+			// The fake client does not support server-side apply.
+			Patch: func(_ context.Context, _ client.WithWatch, _ client.Object, patch client.Patch, _ ...client.PatchOption) error {
+				patches = append(patches, patch)
+				return nil
+			},
+		}).
+		Build()
+
+	if err := applyWorkloadOverrides(t.Context(), c, deploy, &deploy.Spec.Template, RuleEvaluatorContainerName, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(patches) != 0 {
+		t.Fatalf("expected no patch without overrides and owned fields, got %d", len(patches))
+	}
+
+	spec := &monitoringv1.WorkloadSpec{
+		PriorityClassName: "gmp-critical",
+		Env:               []corev1.EnvVar{{Name: "GOMAXPROCS", Value: "2"}},
+	}
+	if err := applyWorkloadOverrides(t.Context(), c, deploy, &deploy.Spec.Template, RuleEvaluatorContainerName, spec); err != nil {
+		t.Fatal(err)
+	}
+	if len(patches) != 1 {
+		t.Fatalf("expected one patch, got %d", len(patches))
+	}
+	if patches[0].Type() != types.ApplyPatchType {
+		t.Errorf("expected apply patch, got %q", patches[0].Type())
+	}
+	data, err := patches[0].Data(deploy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]any{
+			"namespace": "gmp-system",
+			"name":      NameRuleEvaluator,
+		},
+		"spec": map[string]any{
+			"template": map[string]any{
+				"spec": map[string]any{
+					"priorityClassName": "gmp-critical",
+					"containers": []any{map[string]any{
+						"name": RuleEvaluatorContainerName,
+						"env":  []any{map[string]any{"name": "GOMAXPROCS", "value": "2"}},
+					}},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected patch (-want, +got): %s", diff)
+	}
+
+	// Removing the overrides applies an empty configuration to release the owned fields.
+	deploy.ManagedFields = []metav1.ManagedFieldsEntry{{
+		Manager:    fieldManagerWorkloads,
+		Operation:  metav1.ManagedFieldsOperationApply,
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:template":{"f:spec":{"f:priorityClassName":{}}}}}`)},
+	}}
+	if err := applyWorkloadOverrides(t.Context(), c, deploy, &deploy.Spec.Template, RuleEvaluatorContainerName, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(patches) != 2 {
+		t.Fatalf("expected a second patch, got %d", len(patches))
+	}
+	data, err = patches[1].Data(deploy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	delete(want, "spec")
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected patch (-want, +got): %s", diff)
+	}
+}
+
+func TestEnsureAlertmanagerStatefulSetOverrides(t *testing.T) {
+	sset := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "gmp-system", Name: NameAlertmanager},
+		Spec: appsv1.StatefulSetSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: AlertmanagerContainerName}},
+				},
+			},
+		},
+	}
+	var patches int
+	c := newFakeClientBuilder().
+		WithObjects(sset).
+		WithInterceptorFuncs(interceptor.Funcs{
+			// The fake client does not support server-side apply.
+			Patch: func(context.Context, client.WithWatch, client.Object, client.Patch, ...client.PatchOption) error {
+				patches++
+				return nil
+			},
+		}).
+		Build()
+	r := newOperatorConfigReconciler(c, Options{OperatorNamespace: "gmp-system"})
+
+	// Overrides apply even if the OperatorConfig doesn't configure the managed Alertmanager.
+	if err := r.ensureAlertmanagerStatefulSet(t.Context(), &monitoringv1.WorkloadSpec{PriorityClassName: "gmp-critical"}); err != nil {
+		t.Fatal(err)
+	}
+	if patches != 1 {
+		t.Errorf("expected overrides to be applied, got %d patches", patches)
+	}
+}